@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-
-	// "os"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/AbramovArseniy/GophKeeper/internal/client"
 	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/config"
@@ -40,7 +42,25 @@ func main() {
 		log.Fatal("Failed connect to server")
 	}
 
+	if flag.Arg(0) == "generate" {
+		client.RunGenerator()
+		return
+	}
 	client := client.NewCLI(action)
+	if cfg.BreachedPasswords != "" {
+		if err = client.SetBreachedPasswords(cfg.BreachedPasswords); err != nil {
+			log.Println("error while opening breached passwords:", err)
+		}
+	}
+	if flag.Arg(0) == "ssh-agent" {
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		err = client.RunSSHAgent(ctx, flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	err = client.StartCLI(ctx)
 	if err != nil {
 		log.Fatal(err)
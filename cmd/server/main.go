@@ -33,11 +33,15 @@ func StartServer() {
 		Handler: handler,
 	}
 	log.Printf("HTTP server started at %s", s.Addr)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.UpgradeData(ctx)
 	idleConnsClosed := make(chan struct{})
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 	go func() {
 		<-sigs
+		cancel()
 		if err := srv.Shutdown(context.Background()); err != nil {
 			log.Printf("HTTP server Shutdown: %v", err)
 		}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/manifoldco/promptui"
+)
+
+const activityPageSize = 20
+
+// showActivity pages through the user's activity log checking the hash chain of every page shown so far
+func showActivity(ctx context.Context, client clienttypes.ClientAction) {
+	var (
+		shown    []audit.Entry
+		beforeID int64
+	)
+	for {
+		entries, err := client.GetAudit(ctx, beforeID, activityPageSize)
+		if err != nil {
+			fmt.Println("Cant get your activity log!")
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("No more activity")
+			return
+		}
+		for _, e := range entries {
+			printAuditEntry(e)
+		}
+		shown = append(shown, entries...)
+		if err = audit.VerifyChain(shown); err != nil {
+			fmt.Println("WARNING: activity log was tampered with:", err)
+		} else {
+			fmt.Println("Activity log integrity verified")
+		}
+		last := entries[len(entries)-1]
+		if last.PrevHash == "" {
+			return
+		}
+		prompt := promptui.Select{
+			Label: "Activity log",
+			Items: []string{"Older entries", "Back"},
+		}
+		idx, _, err := prompt.Run()
+		if err != nil || idx == 1 {
+			return
+		}
+		beforeID = last.ID
+	}
+}
+
+func printAuditEntry(e audit.Entry) {
+	status := "ok"
+	if !e.Success {
+		status = "FAILED"
+	}
+	secret := ""
+	if e.SecretName != "" {
+		secret = fmt.Sprintf(" %s %q", e.SecretType, e.SecretName)
+	}
+	if e.Target != "" {
+		secret += " with " + e.Target
+	}
+	actor := ""
+	if e.Actor != e.Login {
+		actor = " by " + e.Actor
+	}
+	fmt.Printf("%s %-15s %-6s%s%s from %s (%s)\n",
+		e.CreatedAt.Local().Format(time.DateTime), e.Action, status, secret, actor, e.IP, e.UserAgent)
+}
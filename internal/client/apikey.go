@@ -0,0 +1,42 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/export"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+func init() {
+	infoViews[storage.APIKey] = exportAPIKey
+}
+
+// exportAPIKey prints the key in the format chosen by the user, nothing is written to disk
+func exportAPIKey(info storage.Info) {
+	key, ok := info.(*storage.InfoAPIKey)
+	if !ok {
+		return
+	}
+	prompt := promptui.Select{
+		Label: "Export the key",
+		Items: []string{"Don't export", "AWS credentials file section", ".netrc entry", "Environment variables"},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	switch idx {
+	case 1:
+		profilePrompt := promptui.Prompt{Label: "Enter AWS profile", Default: "default"}
+		profile, err := profilePrompt.Run()
+		if err != nil {
+			return
+		}
+		fmt.Print(export.AWSCredentials(profile, key))
+	case 2:
+		fmt.Print(export.Netrc(key))
+	case 3:
+		fmt.Print(export.EnvVars(key))
+	}
+}
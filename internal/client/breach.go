@@ -0,0 +1,20 @@
+package client
+
+import "fmt"
+
+// passwordBreached checks the password against the user's ranges, it is not breached if the ranges are not set or can't be read
+func (cli *CommandLine) passwordBreached(password string) bool {
+	if cli.breaches == nil {
+		return false
+	}
+	count, err := cli.breaches.Count(password)
+	if err != nil {
+		fmt.Println("Cant check the password for breaches:", err)
+		return false
+	}
+	if count > 0 {
+		fmt.Printf("The password was seen %d times in data breaches, choose another one!\n", count)
+		return true
+	}
+	return false
+}
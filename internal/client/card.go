@@ -0,0 +1,30 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+func init() {
+	infoViews[storage.Card] = revealCard
+}
+
+// revealCard prints full number and CVC of the card only if the user asks for them
+func revealCard(info storage.Info) {
+	card, ok := info.(*storage.InfoCard)
+	if !ok {
+		return
+	}
+	prompt := promptui.Select{
+		Label: "Card number and CVC are hidden",
+		Items: []string{"Keep hidden", "Reveal"},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil || idx == 0 {
+		return
+	}
+	fmt.Printf("Card number: %s\n", storage.FormatCardNumber(card.CardNumber))
+	fmt.Printf("CVC code: %s\n", card.CVCcode)
+}
@@ -2,11 +2,16 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/AbramovArseniy/GophKeeper/internal/client/httpclient"
 	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/vault"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/breach"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
 	"github.com/manifoldco/promptui"
 	"google.golang.org/grpc/metadata"
@@ -14,6 +19,13 @@ import (
 
 type CommandLine struct {
 	action *MDAct
+	// vaultKey is unwrapped with the user's password after login and never leaves the client
+	vaultKey []byte
+	// publicKey and privateKey are used to open secrets other users sealed for the user
+	publicKey  []byte
+	privateKey []byte
+	// breaches are checked for passwords offline if the user has the ranges
+	breaches *breach.Ranges
 }
 
 type MDAct struct {
@@ -31,6 +43,16 @@ func NewCLI(action *MDAct) *CommandLine {
 	return &CommandLine{action: action}
 }
 
+// SetBreachedPasswords makes the CLI check passwords against HIBP range files in the directory
+func (cli *CommandLine) SetBreachedPasswords(dir string) error {
+	ranges, err := breach.Open(dir)
+	if err != nil {
+		return err
+	}
+	cli.breaches = ranges
+	return nil
+}
+
 func (cli *CommandLine) StartCLI(ctx context.Context) (err error) {
 	err = cli.Authentication(ctx)
 	if err != nil {
@@ -40,6 +62,7 @@ func (cli *CommandLine) StartCLI(ctx context.Context) (err error) {
 		return fmt.Errorf("Authentication error: %w", err)
 	}
 	fmt.Println("Authenticated successfully!")
+	showDueBanner(ctx, cli.action.act)
 
 	if err := cli.Action(ctx); err != nil {
 		if err == clienttypes.ErrExitCLI {
@@ -54,7 +77,7 @@ func (cli *CommandLine) StartCLI(ctx context.Context) (err error) {
 func (cli *CommandLine) Authentication(ctx context.Context) error {
 	prompt := promptui.Select{
 		Label: "Welcome to GophKeeper! What would you like to do?",
-		Items: []string{"Register", "Log in", "Exit"},
+		Items: []string{"Register", "Log in", "Forgot password", "Open one-time link", "Exit"},
 	}
 	idx, _, err := prompt.Run()
 	if err != nil {
@@ -75,6 +98,17 @@ func (cli *CommandLine) Authentication(ctx context.Context) error {
 		return nil
 	}
 	if idx == 2 {
+		err := resetPassword(ctx, cli)
+		if err != nil {
+			return fmt.Errorf("error: can't authorize: %w", err)
+		}
+		return nil
+	}
+	if idx == 3 {
+		openLink(ctx, cli.action.act)
+		return exitCLI(ctx)
+	}
+	if idx == 4 {
 		return exitCLI(ctx)
 	}
 	cli.Action(ctx)
@@ -90,16 +124,35 @@ func register(ctx context.Context, cli *CommandLine) error {
 	if err != nil {
 		return fmt.Errorf("error: can't get password: %w", err)
 	}
+	if cli.passwordBreached(password) {
+		return errors.New("error: password was found in data breaches")
+	}
+	vaultKey, err := vault.NewKey()
+	if err != nil {
+		return fmt.Errorf("error: can't create vault key: %w", err)
+	}
+	wrappedKey, err := vault.Wrap(vaultKey, password)
+	if err != nil {
+		return fmt.Errorf("error: can't wrap vault key: %w", err)
+	}
+	recoveryKey, recovery, err := newRecoveryKey(vaultKey)
+	if err != nil {
+		return err
+	}
 	authReq := clienttypes.AuthRequest{
 		Login:    login,
 		Password: password,
+		VaultKey: wrappedKey,
+		Recovery: &recovery,
 	}
 	err = cli.action.act.Register(ctx, authReq)
 	if err != nil {
 		return fmt.Errorf("error: can't register: %w", err)
 	}
+	cli.vaultKey = vaultKey
+	showRecoveryKey(recoveryKey)
 
-	return nil
+	return loadKeyPair(ctx, cli)
 }
 
 func authorize(ctx context.Context, cli *CommandLine) error {
@@ -115,14 +168,97 @@ func authorize(ctx context.Context, cli *CommandLine) error {
 		Login:    login,
 		Password: password,
 	}
-	err = cli.action.act.Login(ctx, authReq)
+	challenge, err := cli.action.act.Login(ctx, authReq)
 	if err != nil {
 		return fmt.Errorf("error: can't login: %w", err)
 	}
+	if challenge != "" {
+		code, err := getTOTPCode()
+		if err != nil {
+			return fmt.Errorf("error: can't get authentication code: %w", err)
+		}
+		err = cli.action.act.LoginTOTP(ctx, clienttypes.TOTPLoginRequest{
+			ChallengeToken: challenge,
+			Code:           code,
+		})
+		if err != nil {
+			return fmt.Errorf("error: can't confirm login: %w", err)
+		}
+	}
+
+	if err = loadVaultKey(ctx, cli, password); err != nil {
+		return err
+	}
+	return loadKeyPair(ctx, cli)
+}
+
+// loadVaultKey unwraps user's vault key, accounts registered without one get a new key
+func loadVaultKey(ctx context.Context, cli *CommandLine, password string) error {
+	wrappedKey, err := cli.action.act.GetVaultKey(ctx)
+	if err != nil {
+		return fmt.Errorf("error: can't get vault key: %w", err)
+	}
+	if wrappedKey != nil {
+		cli.vaultKey, err = vault.Unwrap(wrappedKey, password)
+		if err != nil {
+			return fmt.Errorf("error: can't unwrap vault key: %w", err)
+		}
+		return nil
+	}
+	vaultKey, err := vault.NewKey()
+	if err != nil {
+		return fmt.Errorf("error: can't create vault key: %w", err)
+	}
+	wrappedKey, err = vault.Wrap(vaultKey, password)
+	if err != nil {
+		return fmt.Errorf("error: can't wrap vault key: %w", err)
+	}
+	if err = cli.action.act.SetVaultKey(ctx, wrappedKey); err != nil {
+		return fmt.Errorf("error: can't save vault key: %w", err)
+	}
+	cli.vaultKey = vaultKey
+
+	return nil
+}
+
+// loadKeyPair decrypts user's private key with the vault key, users without key pair get a new one
+func loadKeyPair(ctx context.Context, cli *CommandLine) error {
+	keyPair, err := cli.action.act.GetKeyPair(ctx)
+	if err != nil {
+		return fmt.Errorf("error: can't get key pair: %w", err)
+	}
+	if len(keyPair.PublicKey) != 0 {
+		cli.privateKey, err = vault.Open(keyPair.PrivateKey, cli.vaultKey)
+		if err != nil {
+			return fmt.Errorf("error: can't decrypt private key: %w", err)
+		}
+		cli.publicKey = keyPair.PublicKey
+		return nil
+	}
+	publicKey, privateKey, err := vault.NewKeyPair()
+	if err != nil {
+		return fmt.Errorf("error: can't create key pair: %w", err)
+	}
+	encryptedKey, err := vault.Seal(privateKey, cli.vaultKey)
+	if err != nil {
+		return fmt.Errorf("error: can't encrypt private key: %w", err)
+	}
+	err = cli.action.act.SetKeyPair(ctx, clienttypes.KeyPairData{PublicKey: publicKey, PrivateKey: encryptedKey})
+	if err != nil {
+		return fmt.Errorf("error: can't publish key pair: %w", err)
+	}
+	cli.publicKey, cli.privateKey = publicKey, privateKey
 
 	return nil
 }
 
+func getTOTPCode() (string, error) {
+	prompt := promptui.Prompt{
+		Label: "Enter authentication code or backup code: ",
+	}
+	return prompt.Run()
+}
+
 func getLogin() (string, error) {
 	prompt := promptui.Prompt{
 		Label: "Enter your login: ",
@@ -138,117 +274,419 @@ func getPassword() (string, error) {
 	return prompt.Run()
 }
 
+const (
+	actionAddInfo      = "Add secret info"
+	actionGetInfo      = "Get secret info"
+	actionFindLogins   = "Find logins for URL"
+	actionUpdateInfo   = "Update secret info"
+	actionDeleteInfo   = "Delete secret info"
+	actionShareInfo    = "Share secret info"
+	actionRevokeShare  = "Revoke shared access"
+	actionCreateLink   = "Create one-time link"
+	actionSharedWithMe = "Secrets shared with me"
+	actionOrgs         = "Organizations"
+	actionEmergency    = "Emergency access"
+	actionTemplates    = "Templates"
+	actionGenerate     = "Generate password"
+	actionHealth       = "Vault health report"
+	actionActivityLog  = "View activity log"
+	actionEnableTOTP   = "Enable two-factor authentication"
+	actionChangePass   = "Change password"
+	actionRecoveryKey  = "Create new recovery key"
+	actionDeleteUser   = "Delete account"
+	actionExit         = "Exit"
+)
+
 func (cli *CommandLine) Action(ctx context.Context) error {
 	prompt := promptui.Select{
 		Label: "What would you like to do?",
-		Items: []string{"Add secret info", "Get secret info", "Exit"},
+		Items: []string{
+			actionAddInfo,
+			actionGetInfo,
+			actionFindLogins,
+			actionUpdateInfo,
+			actionDeleteInfo,
+			actionShareInfo,
+			actionRevokeShare,
+			actionCreateLink,
+			actionSharedWithMe,
+			actionOrgs,
+			actionEmergency,
+			actionTemplates,
+			actionGenerate,
+			actionHealth,
+			actionActivityLog,
+			actionEnableTOTP,
+			actionChangePass,
+			actionRecoveryKey,
+			actionDeleteUser,
+			actionExit,
+		},
+		Size: 21,
 	}
-	idx, _, err := prompt.Run()
+	_, choice, err := prompt.Run()
 	if err != nil {
 		return fmt.Errorf("error choose action prompt failed: %w", err)
 	}
-	if idx == 0 {
-		addInfo(ctx, cli.action.act)
-	}
-	if idx == 1 {
+	switch choice {
+	case actionAddInfo:
+		addInfo(ctx, cli.action.act, cli.action.act.SaveData)
+	case actionGetInfo:
 		getInfo(ctx, cli.action.act)
-	}
-	if idx == 2 {
-		exitCLI(ctx)
+	case actionFindLogins:
+		findLogins(ctx, cli.action.act)
+	case actionUpdateInfo:
+		addInfo(ctx, cli.action.act, cli.action.act.UpdateData)
+	case actionDeleteInfo:
+		deleteInfo(ctx, cli.action.act)
+	case actionShareInfo:
+		shareInfo(ctx, cli)
+	case actionRevokeShare:
+		revokeShare(ctx, cli.action.act)
+	case actionCreateLink:
+		createLink(ctx, cli.action.act)
+	case actionSharedWithMe:
+		showSharedWithMe(ctx, cli)
+	case actionOrgs:
+		showOrgs(ctx, cli.action.act)
+	case actionEmergency:
+		showEmergency(ctx, cli.action.act)
+	case actionTemplates:
+		showTemplates(ctx, cli.action.act)
+	case actionGenerate:
+		RunGenerator()
+	case actionHealth:
+		showHealthReport(ctx, cli.action.act, cli.breaches)
+	case actionActivityLog:
+		showActivity(ctx, cli.action.act)
+	case actionEnableTOTP:
+		enableTOTP(ctx, cli.action.act)
+	case actionChangePass:
+		changePassword(ctx, cli)
+	case actionRecoveryKey:
+		createRecoveryKey(ctx, cli)
+	case actionDeleteUser:
+		if deleteAccount(ctx, cli) {
+			return exitCLI(ctx)
+		}
+	case actionExit:
+		return exitCLI(ctx)
 	}
 	cli.Action(ctx)
 	return nil
 }
 
-func addInfo(ctx context.Context, client clienttypes.ClientAction) {
+type saveFunc func(ctx context.Context, req storage.Info, infoType storage.InfoType, infoName string) error
+
+// addInfo asks for secret and stores it with save, which either adds new secret or updates existing one
+func addInfo(ctx context.Context, client clienttypes.ClientAction, save saveFunc) {
 	infoType := getInfoType()
 	infoName := getInfoName()
+	saveInfo(ctx, infoType, infoName, save)
+}
 
-	switch infoType {
-	case storage.LoginPassword:
-		req := storage.InfoLoginPass{
-			Login:    getValueFromUser("Enter login"),
-			Password: getValueFromUser("Enter password"),
-		}
-		err := client.SaveData(ctx, &req, infoType, infoName)
-		if err != nil {
-			fmt.Println("Cant save your info!")
-		}
-		fmt.Println("Password Saved!")
+var (
+	// infoInputs ask for secrets of the types which are not typed in field by field, e.g. imported from files
+	infoInputs = map[storage.InfoType]func() (storage.Info, error){}
+	// infoViews show what is computed from secrets of the types after their fields are printed
+	infoViews = map[storage.InfoType]func(info storage.Info){}
+)
+
+// saveInfo asks for fields of the secret type and its custom fields and stores the secret with save
+func saveInfo(ctx context.Context, infoType storage.InfoType, infoName string, save saveFunc) {
+	spec, ok := storage.Lookup(infoType)
+	if !ok {
+		fmt.Println("Unknown secret type!")
 		return
-	case storage.Card:
-		req := storage.InfoCard{
-			CardNumber: getValueFromUser("Enter cardnumber"),
-			Holder:     getValueFromUser("Enter cardholder name"),
-			Date:       getValueFromUser("Enter expiration date"),
-			CVCcode:    getValueFromUser("Enter cvc code"),
+	}
+	req, err := inputInfo(spec)
+	if err == nil {
+		err = addCustomFields(req)
+	}
+	if err == nil {
+		err = addPolicy(req)
+	}
+	if err != nil {
+		fmt.Println("Secret was not saved:", err)
+		return
+	}
+	storeInfo(ctx, spec, req, infoName, save)
+}
+
+// inputInfo asks for the secret the way its type is typed in
+func inputInfo(spec storage.TypeSpec) (storage.Info, error) {
+	if input, ok := infoInputs[spec.Type]; ok {
+		return input()
+	}
+	return getFieldsFromUser(spec)
+}
+
+// storeInfo validates the secret and stores it with save
+func storeInfo(ctx context.Context, spec storage.TypeSpec, req storage.Info, infoName string, save saveFunc) {
+	if err := spec.Validate(req); err != nil {
+		printFieldErrors(spec, err)
+		return
+	}
+	err := save(ctx, req, spec.Type, infoName)
+	if err != nil {
+		printFieldErrors(spec, err)
+		return
+	}
+	fmt.Printf("%s Saved!\n", spec.Name)
+}
+
+// printFieldErrors tells which fields of the secret are invalid if err is FieldErrors
+func printFieldErrors(spec storage.TypeSpec, err error) {
+	var fieldErrs storage.FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		fmt.Println("Cant save your info!")
+		return
+	}
+	labels := make(map[string]string, len(spec.Fields))
+	for _, field := range spec.Fields {
+		labels[field.Name] = field.Label
+	}
+	fmt.Println("Secret was not saved, invalid fields:")
+	for _, fieldErr := range fieldErrs {
+		label, ok := labels[fieldErr.Field]
+		if !ok {
+			label = fieldErr.Field
 		}
-		err := client.SaveData(ctx, &req, infoType, infoName)
+		fmt.Printf("  %s %s\n", label, fieldErr.Message)
+	}
+}
+
+// getFieldsFromUser asks for all fields of the secret type
+func getFieldsFromUser(spec storage.TypeSpec) (storage.Info, error) {
+	values := make(map[string]string, len(spec.Fields))
+	for _, field := range spec.Fields {
+		value, err := getFieldFromUser(field)
 		if err != nil {
-			fmt.Println("Cant save your info!")
+			return nil, err
 		}
-		fmt.Println("Card Saved!")
-		return
-	case storage.Text:
-		req := storage.InfoText{
-			Text: getValueFromUser("Enter text"),
+		values[field.Name] = value
+	}
+	return spec.FromValues(values)
+}
+
+// getParsedOrFields asks for a string the secret is parsed from, e.g. URI, or for its fields if the string is empty
+func getParsedOrFields(infoType storage.InfoType, label string, parse func(value string) (storage.Info, error)) (storage.Info, error) {
+	prompt := promptui.Prompt{
+		Label: label,
+		Validate: func(value string) error {
+			if strings.TrimSpace(value) == "" {
+				return nil
+			}
+			_, err := parse(strings.TrimSpace(value))
+			return err
+		},
+	}
+	value, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(value) != "" {
+		return parse(strings.TrimSpace(value))
+	}
+	spec, ok := storage.Lookup(infoType)
+	if !ok {
+		return nil, storage.ErrInvalidData
+	}
+	return getFieldsFromUser(spec)
+}
+
+// getFieldFromUser asks for value of the field until it is valid
+func getFieldFromUser(field storage.Field) (string, error) {
+	if field.Kind == storage.KindBoolean {
+		prompt := promptui.Select{
+			Label: field.Label,
+			Items: []string{"true", "false"},
 		}
-		err := client.SaveData(ctx, &req, infoType, infoName)
-		if err != nil {
-			fmt.Println("Cant save your info!")
+		_, value, err := prompt.Run()
+		return value, err
+	}
+	if field.Generate {
+		value, ok, err := offerGenerated(field.Label)
+		if err != nil || ok {
+			return value, err
 		}
-		fmt.Println("Text Saved!")
-		return
 	}
+	prompt := promptui.Prompt{
+		Label:    "Enter " + strings.ToLower(field.Label),
+		Validate: field.Check,
+	}
+	if field.Masked || field.Kind == storage.KindHidden {
+		prompt.Mask = '*'
+	}
+	return prompt.Run()
 }
 
 func getInfo(ctx context.Context, client clienttypes.ClientAction) {
-	infoType := getInfoType()
-	infoName := getInfoName()
+	req := clienttypes.GetRequest{Type: getInfoType(), Name: getInfoName()}
+	showInfo(ctx, client, req)
+}
 
-	req := clienttypes.GetRequest{Name: infoName, Type: infoType}
+func showInfo(ctx context.Context, client clienttypes.ClientAction, req clienttypes.GetRequest) {
+	info, err := client.GetData(ctx, req)
+	if err != nil {
+		fmt.Println("Cant get your info!")
+		return
+	}
+	printInfo(req.Type, info)
+	fmt.Println(req.Type, req.Name)
+}
 
-	switch infoType {
-	case storage.LoginPassword:
-		resp, err := client.GetData(ctx, req)
+// printInfo prints the secret the way its type renders it
+func printInfo(infoType storage.InfoType, info storage.Info) {
+	spec, ok := storage.Lookup(infoType)
+	if !ok {
+		fmt.Println("Unknown secret type!")
+		return
+	}
+	text, err := spec.Format(info)
+	if err != nil {
+		fmt.Println("Cant show your info!")
+		return
+	}
+	fmt.Print(text)
+	printExpiry(info, time.Now())
+	if view, ok := infoViews[infoType]; ok {
+		view(info)
+	}
+	revealHiddenFields(info)
+}
 
-		if err != nil {
-			fmt.Println("Cant get your info!")
-		}
-		info, ok := resp.(*storage.InfoLoginPass)
-		if !ok {
-			fmt.Println("Cant get your info!")
-		}
-		fmt.Printf("Login: %s\n", info.Login)
-		fmt.Printf("Password: %s\n", info.Password)
+func enableTOTP(ctx context.Context, client clienttypes.ClientAction) {
+	enrollment, err := client.EnrollTOTP(ctx)
+	if err != nil {
+		fmt.Println("Cant enable two-factor authentication!")
+		return
+	}
+	fmt.Println("Add this URI to your authenticator app:")
+	fmt.Println(enrollment.URI)
+	fmt.Printf("Secret: %s\n", enrollment.Secret)
+	fmt.Println("Backup codes, each can be used once instead of authentication code:")
+	for _, code := range enrollment.BackupCodes {
+		fmt.Println("  " + code)
+	}
+	code, err := getTOTPCode()
+	if err != nil {
+		fmt.Println("Cant get authentication code!")
+		return
+	}
+	err = client.ConfirmTOTP(ctx, code)
+	if err != nil {
+		fmt.Println("Cant confirm two-factor authentication!")
 		return
-	case storage.Card:
-		resp, err := client.GetData(ctx, req)
+	}
+	fmt.Println("Two-factor authentication enabled!")
+}
 
+func changePassword(ctx context.Context, cli *CommandLine) {
+	oldPassword, err := getMaskedValueFromUser("Enter current password")
+	if err != nil {
+		fmt.Println("Cant get current password!")
+		return
+	}
+	newPassword, err := getMaskedValueFromUser("Enter new password")
+	if err != nil {
+		fmt.Println("Cant get new password!")
+		return
+	}
+	repeatPassword, err := getMaskedValueFromUser("Repeat new password")
+	if err != nil || repeatPassword != newPassword {
+		fmt.Println("Passwords do not match!")
+		return
+	}
+	req := clienttypes.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}
+	wrappedKey, err := cli.action.act.GetVaultKey(ctx)
+	if err != nil {
+		fmt.Println("Cant get your vault key!")
+		return
+	}
+	if wrappedKey != nil {
+		vaultKey, err := vault.Unwrap(wrappedKey, oldPassword)
 		if err != nil {
-			fmt.Println("Cant get your info!")
-		}
-		info, ok := resp.(*storage.InfoCard)
-		if !ok {
-			fmt.Println("Cant get your info!")
+			fmt.Println("Wrong current password!")
+			return
 		}
-		fmt.Printf("CardNumber: %s\n", info.CardNumber)
-		fmt.Printf("Holder: %s\n", info.Holder)
-		fmt.Printf("Date: %s\n", info.Date)
-		fmt.Printf("CVCcode: %s\n", info.CVCcode)
-	case storage.Text:
-		resp, err := client.GetData(ctx, req)
-
+		req.VaultKey, err = vault.Wrap(vaultKey, newPassword)
 		if err != nil {
-			fmt.Println("Cant get your info!")
-		}
-		info, ok := resp.(*storage.InfoText)
-		if !ok {
-			fmt.Println("Cant get your info!")
+			fmt.Println("Cant re-wrap your vault key!")
+			return
 		}
-		fmt.Printf("Text: %s\n", info.Text)
 	}
-	fmt.Println(infoType, infoName)
+	err = cli.action.act.ChangePassword(ctx, req)
+	if err != nil {
+		fmt.Println("Cant change your password!")
+		return
+	}
+	fmt.Println("Password changed! Other sessions were logged out.")
+}
+
+// deleteAccount reports whether the account was deleted
+func deleteAccount(ctx context.Context, cli *CommandLine) bool {
+	confirm := promptui.Prompt{
+		Label:     "Delete your account and all your secrets permanently",
+		IsConfirm: true,
+	}
+	if _, err := confirm.Run(); err != nil {
+		fmt.Println("Account was not deleted")
+		return false
+	}
+	password, err := getMaskedValueFromUser("Enter your password")
+	if err != nil {
+		fmt.Println("Cant get your password!")
+		return false
+	}
+	err = cli.action.act.DeleteAccount(ctx, password)
+	if err != nil {
+		fmt.Println("Cant delete your account!")
+		return false
+	}
+	cli.wipe()
+	fmt.Println("Account deleted!")
+	return true
+}
+
+// wipe clears everything the client keeps about the user
+func (cli *CommandLine) wipe() {
+	for i := range cli.vaultKey {
+		cli.vaultKey[i] = 0
+	}
+	cli.vaultKey = nil
+	for i := range cli.privateKey {
+		cli.privateKey[i] = 0
+	}
+	cli.privateKey, cli.publicKey = nil, nil
+}
+
+func getMaskedValueFromUser(label string) (string, error) {
+	prompt := promptui.Prompt{
+		Label: label,
+		Mask:  '*',
+	}
+	return prompt.Run()
+}
+
+func deleteInfo(ctx context.Context, client clienttypes.ClientAction) {
+	req := clienttypes.GetRequest{Type: getInfoType(), Name: getInfoName()}
+	confirm := promptui.Prompt{
+		Label:     fmt.Sprintf("Delete %s %q", req.Type, req.Name),
+		IsConfirm: true,
+	}
+	if _, err := confirm.Run(); err != nil {
+		fmt.Println("Secret was not deleted")
+		return
+	}
+	if err := client.DeleteData(ctx, req); err != nil {
+		fmt.Println("Cant delete your info!")
+		return
+	}
+	fmt.Println("Secret deleted!")
 }
 
 func getValueFromUser(label string) string {
@@ -276,21 +714,22 @@ func getInfoName() string {
 }
 
 func getInfoType() storage.InfoType {
-	infoTypes := []storage.InfoType{
-		storage.LoginPassword,
-		storage.Card,
-		storage.Text,
-	}
+	specs := storage.Types()
 	prompt := promptui.Select{
 		Label: "Select type of info",
-		Items: infoTypes,
+		Items: specs,
+		Templates: &promptui.SelectTemplates{
+			Active:   "> {{ .Name }}",
+			Inactive: "  {{ .Name }}",
+			Selected: "{{ .Name }}",
+		},
 	}
 	idx, _, err := prompt.Run()
 	if err != nil {
 		log.Fatal("Failed choose secret type prompt")
 	}
 
-	return infoTypes[idx]
+	return specs[idx].Type
 }
 
 func exitCLI(ctx context.Context) error {
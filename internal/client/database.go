@@ -0,0 +1,40 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/dsn"
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/export"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+func init() {
+	infoInputs[storage.Database] = getDatabaseFromUser
+	infoViews[storage.Database] = showDSN
+}
+
+// getDatabaseFromUser parses DSN of one of the known drivers or asks for the credential's fields
+func getDatabaseFromUser() (storage.Info, error) {
+	return getParsedOrFields(storage.Database, "Enter DSN or leave empty to enter fields", func(value string) (storage.Info, error) {
+		info, err := dsn.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		return &info, nil
+	})
+}
+
+// showDSN prints DSN ready to be used as DATABASE_DSN, e.g. by the server
+func showDSN(info storage.Info) {
+	credential, ok := info.(*storage.InfoDatabase)
+	if !ok {
+		return
+	}
+	value, err := dsn.Render(credential)
+	if err != nil {
+		fmt.Println("Cant render DSN:", err)
+		return
+	}
+	fmt.Printf("DSN: %s\n", value)
+	fmt.Print(export.ShellExport("DATABASE_DSN", value))
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+// dueDays is how many days before the due date the user is warned
+const dueDays = 30
+
+// showDueBanner warns the user about secrets which are expired or must be rotated soon
+func showDueBanner(ctx context.Context, client clienttypes.ClientAction) {
+	items, err := client.GetDue(ctx, dueDays)
+	if err != nil || len(items) == 0 {
+		return
+	}
+	fmt.Printf("Warning: %d secrets need your attention!\n", len(items))
+	for _, item := range items {
+		fmt.Printf("  %s (%s) %s\n", item.Name, item.Type, dueText(item.Reason, item.Overdue, item.Date))
+	}
+}
+
+func dueText(reason storage.DueReason, overdue bool, date string) string {
+	switch {
+	case reason == storage.DueRotate && overdue:
+		return "should have been rotated by " + date
+	case reason == storage.DueRotate:
+		return "should be rotated by " + date
+	case overdue:
+		return "expired on " + date
+	}
+	return "expires on " + date
+}
+
+// printExpiry warns if the secret has expired or is due soon
+func printExpiry(info storage.Info, now time.Time) {
+	due, ok := storage.NextDue(info)
+	if !ok || due.Date.After(now.AddDate(0, 0, dueDays)) {
+		return
+	}
+	fmt.Printf("Warning: %s!\n", dueText(due.Reason, !due.Date.After(now), due.Date.Format(storage.DateLayout)))
+}
+
+// addPolicy asks whether the user wants to be reminded to renew the secret
+func addPolicy(info storage.Info) error {
+	rotatable, ok := info.(storage.Rotatable)
+	if !ok {
+		return nil
+	}
+	prompt := promptui.Select{
+		Label: "Remind to renew the secret?",
+		Items: []string{"No reminder", "On expiry date", "Every N days"},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+	rotation := rotatable.RotationPolicy()
+	switch idx {
+	case 1:
+		value, err := getFieldFromUser(storage.Field{Label: "Expiry date (YYYY-MM-DD)", Kind: storage.KindDate, Required: true})
+		if err != nil {
+			return err
+		}
+		rotation.Policy = &storage.Policy{ExpiresAt: value}
+	case 2:
+		daysPrompt := promptui.Prompt{
+			Label: "Enter how many days the secret may be kept unchanged",
+			Validate: func(value string) error {
+				_, err := storage.ParseRotateDays(value)
+				return err
+			},
+		}
+		value, err := daysPrompt.Run()
+		if err != nil {
+			return err
+		}
+		days, _ := storage.ParseRotateDays(value)
+		rotation.Policy = &storage.Policy{RotateDays: days}
+	}
+	return nil
+}
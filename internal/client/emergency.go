@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/manifoldco/promptui"
+)
+
+const (
+	emergencyContacts  = "My trusted contacts"
+	emergencyAdd       = "Add trusted contact"
+	emergencyTrustedBy = "Accounts I'm trusted by"
+	emergencyReject    = "Reject access"
+	emergencyWait      = "Change waiting period"
+	emergencyRemove    = "Remove contact"
+	emergencyBack      = "Back"
+)
+
+// showEmergency lets the user manage trusted contacts and use emergency access to vaults of other users
+func showEmergency(ctx context.Context, client clienttypes.ClientAction) {
+	prompt := promptui.Select{
+		Label: "Emergency access",
+		Items: []string{emergencyContacts, emergencyAdd, emergencyTrustedBy, emergencyBack},
+	}
+	_, choice, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	switch choice {
+	case emergencyContacts:
+		manageEmergencyContacts(ctx, client)
+	case emergencyAdd:
+		login := getValueFromUser("Enter login of the trusted contact")
+		setEmergencyWait(ctx, client, login)
+	case emergencyTrustedBy:
+		useEmergencyAccess(ctx, client)
+	}
+}
+
+func manageEmergencyContacts(ctx context.Context, client clienttypes.ClientAction) {
+	contacts, err := client.GetEmergencyContacts(ctx)
+	if err != nil {
+		fmt.Println("Cant get your trusted contacts!")
+		return
+	}
+	if len(contacts) == 0 {
+		fmt.Println("You have no trusted contacts")
+		return
+	}
+	contact, ok := selectEmergencyContact("Your trusted contacts", contacts, func(c emergency.Contact) string { return c.Contact })
+	if !ok {
+		return
+	}
+	items := []string{emergencyWait, emergencyRemove, emergencyBack}
+	if contact.Status == emergency.StatusRequested || contact.Status == emergency.StatusGranted {
+		items = append([]string{emergencyReject}, items...)
+	}
+	prompt := promptui.Select{
+		Label: contact.Contact,
+		Items: items,
+	}
+	_, choice, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	switch choice {
+	case emergencyReject:
+		err = client.RejectEmergencyAccess(ctx, contact.Contact)
+	case emergencyWait:
+		setEmergencyWait(ctx, client, contact.Contact)
+		return
+	case emergencyRemove:
+		err = client.RemoveEmergencyContact(ctx, contact.Contact)
+	case emergencyBack:
+		return
+	}
+	if err != nil {
+		fmt.Println("Cant do it!")
+		return
+	}
+	fmt.Println("Done!")
+}
+
+func setEmergencyWait(ctx context.Context, client clienttypes.ClientAction, login string) {
+	waitHours, err := getIntFromUser("Waiting period in hours", "72")
+	if err != nil || !emergency.ValidWait(waitHours) {
+		fmt.Printf("Waiting period must be from %d to %d hours!\n", emergency.MinWaitHours, emergency.MaxWaitHours)
+		return
+	}
+	if err = client.AddEmergencyContact(ctx, login, waitHours); err != nil {
+		fmt.Println("Cant save trusted contact!")
+		return
+	}
+	fmt.Printf("%s can get access to your vault %d hours after requesting it unless you reject it\n", login, waitHours)
+}
+
+// useEmergencyAccess requests access to the chosen owner's vault or opens the vault once access is granted
+func useEmergencyAccess(ctx context.Context, client clienttypes.ClientAction) {
+	grantors, err := client.GetEmergencyGrantors(ctx)
+	if err != nil {
+		fmt.Println("Cant get users who trust you!")
+		return
+	}
+	if len(grantors) == 0 {
+		fmt.Println("Nobody made you a trusted contact")
+		return
+	}
+	contact, ok := selectEmergencyContact("Users who trust you", grantors, func(c emergency.Contact) string { return c.Owner })
+	if !ok {
+		return
+	}
+	switch contact.Status {
+	case emergency.StatusGranted:
+		showEmergencyVault(ctx, client, contact.Owner)
+	case emergency.StatusRequested:
+		fmt.Printf("Access will be granted at %s unless %s rejects it\n", formatTime(contact.GrantsAt()), contact.Owner)
+	default:
+		confirm := promptui.Prompt{
+			Label:     fmt.Sprintf("Request emergency access to the vault of %s", contact.Owner),
+			IsConfirm: true,
+		}
+		if _, err = confirm.Run(); err != nil {
+			return
+		}
+		contact, err = client.RequestEmergencyAccess(ctx, contact.Owner)
+		if err != nil {
+			fmt.Println("Cant request access!")
+			return
+		}
+		fmt.Printf("Access requested, it will be granted at %s unless %s rejects it\n", formatTime(contact.GrantsAt()), contact.Owner)
+	}
+}
+
+func showEmergencyVault(ctx context.Context, client clienttypes.ClientAction, owner string) {
+	infos, err := client.GetEmergencyVault(ctx, owner)
+	if err != nil {
+		fmt.Println("Cant get the vault!")
+		return
+	}
+	if len(infos) == 0 {
+		fmt.Printf("Vault of %s is empty\n", owner)
+		return
+	}
+	prompt := promptui.Select{
+		Label: "Vault of " + owner,
+		Items: infos,
+		Templates: &promptui.SelectTemplates{
+			Active:   "> {{ .Type }} {{ .Name | printf \"%q\" }}",
+			Inactive: "  {{ .Type }} {{ .Name | printf \"%q\" }}",
+			Selected: "{{ .Type }} {{ .Name | printf \"%q\" }}",
+		},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	info, err := client.GetEmergencyData(ctx, clienttypes.GetRequest{Type: infos[idx].Type, Name: infos[idx].Name, Owner: owner})
+	if err != nil {
+		fmt.Println("Cant get the secret!")
+		return
+	}
+	printInfo(infos[idx].Type, info)
+}
+
+// selectEmergencyContact asks to choose one of the contacts described by the other party's login
+func selectEmergencyContact(label string, contacts []emergency.Contact, party func(emergency.Contact) string) (emergency.Contact, bool) {
+	items := make([]string, 0, len(contacts))
+	for _, c := range contacts {
+		item := fmt.Sprintf("%s: %s, waiting period %dh", party(c), c.Status, c.WaitHours)
+		if c.Status == emergency.StatusRequested {
+			item += ", granted at " + formatTime(c.GrantsAt())
+		}
+		items = append(items, item)
+	}
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return emergency.Contact{}, false
+	}
+	return contacts[idx], true
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format(time.DateTime)
+}
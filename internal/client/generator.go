@@ -0,0 +1,144 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/generator"
+	"github.com/manifoldco/promptui"
+)
+
+const (
+	generateOwn        = "Type it in"
+	generatePassword   = "Generate password"
+	generatePassphrase = "Generate passphrase"
+)
+
+// offerGenerated lets the user generate value of the field instead of typing it in, it is false if the user types it in
+func offerGenerated(label string) (string, bool, error) {
+	prompt := promptui.Select{
+		Label: label,
+		Items: []string{generateOwn, generatePassword, generatePassphrase},
+	}
+	_, choice, err := prompt.Run()
+	if err != nil || choice == generateOwn {
+		return "", false, err
+	}
+	value, err := generate(choice)
+	if err != nil {
+		return "", false, err
+	}
+	fmt.Printf("%s: %s\n", label, value)
+	return value, true, nil
+}
+
+// RunGenerator generates passwords and passphrases until the user is done, nothing is saved
+func RunGenerator() {
+	for {
+		prompt := promptui.Select{
+			Label: "Generate",
+			Items: []string{generatePassword, generatePassphrase, "Back"},
+		}
+		idx, choice, err := prompt.Run()
+		if err != nil || idx == 2 {
+			return
+		}
+		value, err := generate(choice)
+		if err != nil {
+			fmt.Println("Cant generate:", err)
+			continue
+		}
+		fmt.Println(value)
+	}
+}
+
+func generate(choice string) (string, error) {
+	if choice == generatePassphrase {
+		opts, err := getPassphraseOptions()
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Entropy: %.0f bits\n", opts.Entropy())
+		return generator.Passphrase(opts)
+	}
+	opts, err := getPasswordOptions()
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Entropy: %.0f bits\n", opts.Entropy())
+	return generator.Password(opts)
+}
+
+func getPasswordOptions() (generator.PasswordOptions, error) {
+	opts := generator.DefaultPasswordOptions
+	length, err := getNumberFromUser("Length", opts.Length, generator.MinLength, generator.MaxLength)
+	if err != nil {
+		return opts, err
+	}
+	opts.Length = length
+	for _, class := range []struct {
+		label string
+		value *bool
+	}{
+		{"Use lower case letters?", &opts.Lower},
+		{"Use upper case letters?", &opts.Upper},
+		{"Use digits?", &opts.Digits},
+		{"Use symbols?", &opts.Symbols},
+		{"Exclude ambiguous characters like l, 1, O and 0?", &opts.ExcludeAmbiguous},
+	} {
+		if *class.value, err = getYesNo(class.label, *class.value); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func getPassphraseOptions() (generator.PassphraseOptions, error) {
+	opts := generator.DefaultPassphraseOptions
+	words, err := getNumberFromUser("Number of words", opts.Words, generator.MinWords, generator.MaxWords)
+	if err != nil {
+		return opts, err
+	}
+	opts.Words = words
+	separator := promptui.Prompt{Label: "Separator", Default: opts.Separator, AllowEdit: true}
+	if opts.Separator, err = separator.Run(); err != nil {
+		return opts, err
+	}
+	if opts.Capitalize, err = getYesNo("Capitalize words?", opts.Capitalize); err != nil {
+		return opts, err
+	}
+	if opts.Number, err = getYesNo("Add a digit?", opts.Number); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func getNumberFromUser(label string, value int, min int, max int) (int, error) {
+	prompt := promptui.Prompt{
+		Label:   fmt.Sprintf("%s (%d-%d)", label, min, max),
+		Default: strconv.Itoa(value),
+		Validate: func(input string) error {
+			n, err := strconv.Atoi(input)
+			if err != nil || n < min || n > max {
+				return fmt.Errorf("must be a number from %d to %d", min, max)
+			}
+			return nil
+		},
+	}
+	input, err := prompt.Run()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(input)
+}
+
+// getYesNo asks yes or no question, the default answer is selected first
+func getYesNo(label string, value bool) (bool, error) {
+	items := []string{"Yes", "No"}
+	if !value {
+		items = []string{"No", "Yes"}
+	}
+	prompt := promptui.Select{Label: label, Items: items}
+	_, choice, err := prompt.Run()
+	return choice == "Yes", err
+}
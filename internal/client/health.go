@@ -0,0 +1,73 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/health"
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/breach"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+// showHealthReport analyzes logins and cards of the user locally and prints the report
+func showHealthReport(ctx context.Context, client clienttypes.ClientAction, breaches *breach.Ranges) {
+	maxAge, err := getNumberFromUser("Passwords older than, days", health.DefaultMaxAgeDays, 1, storage.MaxRotateDays)
+	if err != nil {
+		return
+	}
+	var items []health.Item
+	for _, infoType := range []storage.InfoType{storage.LoginPassword, storage.Card} {
+		typeItems, err := loadHealthItems(ctx, client, infoType)
+		if err != nil {
+			fmt.Println("Cant get your secrets!")
+			return
+		}
+		items = append(items, typeItems...)
+	}
+	report := health.Analyze(items, health.Options{MaxAgeDays: maxAge, Now: time.Now(), Breaches: breaches})
+	if err = report.WriteText(os.Stdout); err != nil {
+		return
+	}
+	save, err := getYesNo("Save the report as JSON?", false)
+	if err != nil || !save {
+		return
+	}
+	pathPrompt := promptui.Prompt{Label: "Save to", Default: "vault-health.json"}
+	path, err := pathPrompt.Run()
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err = report.WriteJSON(&buf); err != nil {
+		fmt.Println("Cant save the report:", err)
+		return
+	}
+	if err = os.WriteFile(expandHome(path), buf.Bytes(), 0600); err != nil {
+		fmt.Println("Cant save the report:", err)
+		return
+	}
+	fmt.Printf("Saved to %s\n", path)
+}
+
+// loadHealthItems gets and decrypts all secrets of the type, secrets which can't be got are skipped
+func loadHealthItems(ctx context.Context, client clienttypes.ClientAction, infoType storage.InfoType) ([]health.Item, error) {
+	metas, err := client.ListData(ctx, infoType)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing secrets: %w", err)
+	}
+	items := make([]health.Item, 0, len(metas))
+	for _, meta := range metas {
+		info, err := client.GetData(ctx, clienttypes.GetRequest{Type: meta.Type, Name: meta.Name})
+		if err != nil {
+			fmt.Printf("Cant get secret %q!\n", meta.Name)
+			continue
+		}
+		items = append(items, health.Item{Name: meta.Name, Type: meta.Type, Info: info})
+	}
+	return items, nil
+}
@@ -8,8 +8,12 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 
 	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
 )
 
@@ -27,29 +31,306 @@ func NewHTTPClient(address string) *HTTPClient {
 }
 
 func (c *HTTPClient) SaveData(ctx context.Context, req storage.Info, infoType storage.InfoType, infoName string) error {
+	return c.sendData(ctx, "/user/add-data/", req, types.GetRequest{Type: infoType, Name: infoName})
+}
+
+func (c *HTTPClient) UpdateData(ctx context.Context, req storage.Info, infoType storage.InfoType, infoName string) error {
+	return c.sendData(ctx, "/user/update-data/", req, types.GetRequest{Type: infoType, Name: infoName})
+}
+
+// SaveDataAt saves secret to the place described by meta, e.g. organization's collection
+func (c *HTTPClient) SaveDataAt(ctx context.Context, req storage.Info, meta types.GetRequest) error {
+	return c.sendData(ctx, "/user/add-data/", req, meta)
+}
+
+// UpdateDataAt updates secret of another user or of organization's collection described by meta
+func (c *HTTPClient) UpdateDataAt(ctx context.Context, req storage.Info, meta types.GetRequest) error {
+	return c.sendData(ctx, "/user/update-data/", req, meta)
+}
+
+func (c *HTTPClient) ShareData(ctx context.Context, req types.ShareRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/share/", req, nil)
+	return err
+}
+
+func (c *HTTPClient) RevokeShare(ctx context.Context, req types.ShareRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/revoke-share/", req, nil)
+	return err
+}
+
+// GetShares returns users the user's secret is shared with
+func (c *HTTPClient) GetShares(ctx context.Context, req types.GetRequest) ([]storage.Share, error) {
+	var resp struct {
+		Shares []storage.Share `json:"shares"`
+	}
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/get-shares/", req, &resp)
+	return resp.Shares, err
+}
+
+// GetSharedWithMe returns secrets of other users shared with the user
+func (c *HTTPClient) GetSharedWithMe(ctx context.Context) ([]storage.SharedInfo, error) {
+	var resp struct {
+		Infos []storage.SharedInfo `json:"infos"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/shared-with-me/", nil, &resp)
+	return resp.Infos, err
+}
+
+func (c *HTTPClient) DeleteData(ctx context.Context, req types.GetRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/delete-data/", req, nil)
+	return err
+}
+
+// ListData returns the user's secrets of the type or of all types if it is empty
+func (c *HTTPClient) ListData(ctx context.Context, infoType storage.InfoType) ([]storage.InfoMeta, error) {
+	var resp struct {
+		Infos []storage.InfoMeta `json:"infos"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/get-users-data/?type="+url.QueryEscape(string(infoType)), nil, &resp)
+	return resp.Infos, err
+}
+
+func (c *HTTPClient) MatchLogins(ctx context.Context, loginURL string) ([]types.MatchedLogin, error) {
+	var resp struct {
+		Logins []types.MatchedLogin `json:"logins"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/logins/?url="+url.QueryEscape(loginURL), nil, &resp)
+	return resp.Logins, err
+}
+
+// GetAudit returns page of user's activity log from the newest entries, zero beforeID means the first page
+func (c *HTTPClient) GetAudit(ctx context.Context, beforeID int64, limit int) ([]audit.Entry, error) {
+	var page struct {
+		Entries []audit.Entry `json:"entries"`
+	}
+	path := fmt.Sprintf("/user/audit/?limit=%d", limit)
+	if beforeID != 0 {
+		path += fmt.Sprintf("&before=%d", beforeID)
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, path, nil, &page)
+	return page.Entries, err
+}
+
+func (c *HTTPClient) GetDue(ctx context.Context, days int) ([]storage.DueInfo, error) {
+	var resp struct {
+		Items []storage.DueInfo `json:"items"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/user/due/?days=%d", days), nil, &resp)
+	return resp.Items, err
+}
+
+// GetTemplates returns the user's templates of secrets
+func (c *HTTPClient) GetTemplates(ctx context.Context) ([]storage.Template, error) {
+	var resp struct {
+		Templates []storage.Template `json:"templates"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/templates/", nil, &resp)
+	return resp.Templates, err
+}
+
+// SaveTemplate adds the template or replaces the user's template with the same name
+func (c *HTTPClient) SaveTemplate(ctx context.Context, template storage.Template) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/templates/", template, nil)
+	return err
+}
+
+func (c *HTTPClient) DeleteTemplate(ctx context.Context, name string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/templates/delete/", storage.Template{Name: name}, nil)
+	return err
+}
+
+// GetEmergencyContacts returns the user's trusted contacts
+func (c *HTTPClient) GetEmergencyContacts(ctx context.Context) ([]emergency.Contact, error) {
+	var resp struct {
+		Contacts []emergency.Contact `json:"contacts"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/emergency/contacts/", nil, &resp)
+	return resp.Contacts, err
+}
+
+func (c *HTTPClient) AddEmergencyContact(ctx context.Context, login string, waitHours int) error {
+	req := types.EmergencyRequest{Login: login, WaitHours: waitHours}
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/emergency/contacts/", req, nil)
+	return err
+}
+
+func (c *HTTPClient) RemoveEmergencyContact(ctx context.Context, login string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/emergency/contacts/delete/", types.EmergencyRequest{Login: login}, nil)
+	return err
+}
+
+func (c *HTTPClient) RejectEmergencyAccess(ctx context.Context, login string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/emergency/reject/", types.EmergencyRequest{Login: login}, nil)
+	return err
+}
+
+// GetEmergencyGrantors returns users who trust the user
+func (c *HTTPClient) GetEmergencyGrantors(ctx context.Context) ([]emergency.Contact, error) {
+	var resp struct {
+		Contacts []emergency.Contact `json:"contacts"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/emergency/trusted-by/", nil, &resp)
+	return resp.Contacts, err
+}
+
+func (c *HTTPClient) RequestEmergencyAccess(ctx context.Context, owner string) (emergency.Contact, error) {
+	var contact emergency.Contact
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/emergency/request/", types.EmergencyRequest{Owner: owner}, &contact)
+	return contact, err
+}
+
+// GetEmergencyVault returns names and types of the owner's secrets once emergency access is granted
+func (c *HTTPClient) GetEmergencyVault(ctx context.Context, owner string) ([]storage.InfoMeta, error) {
+	var resp struct {
+		Infos []storage.InfoMeta `json:"infos"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/emergency/vault/?owner="+url.QueryEscape(owner), nil, &resp)
+	return resp.Infos, err
+}
+
+func (c *HTTPClient) GetEmergencyData(ctx context.Context, req types.GetRequest) (storage.Info, error) {
+	info := storage.NewInfo(req.Type)
+	if info == nil {
+		return nil, storage.ErrInvalidData
+	}
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/emergency/get-data/", req, info)
+	return info, err
+}
+
+func (c *HTTPClient) CreateOrg(ctx context.Context, org string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/", types.OrgRequest{Org: org}, nil)
+	return err
+}
+
+func (c *HTTPClient) DeleteOrg(ctx context.Context, org string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/delete/", types.OrgRequest{Org: org}, nil)
+	return err
+}
+
+// GetOrgs returns organizations the user is a member of
+func (c *HTTPClient) GetOrgs(ctx context.Context) ([]orgs.Membership, error) {
+	var resp struct {
+		Orgs []orgs.Membership `json:"orgs"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/orgs/", nil, &resp)
+	return resp.Orgs, err
+}
+
+func (c *HTTPClient) GetMembers(ctx context.Context, org string) ([]orgs.Member, error) {
+	var resp struct {
+		Members []orgs.Member `json:"members"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/orgs/members/?org="+url.QueryEscape(org), nil, &resp)
+	return resp.Members, err
+}
+
+func (c *HTTPClient) InviteMember(ctx context.Context, req types.OrgRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/invite/", req, nil)
+	return err
+}
+
+func (c *HTTPClient) SetMemberRole(ctx context.Context, req types.OrgRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/set-role/", req, nil)
+	return err
+}
+
+func (c *HTTPClient) RemoveMember(ctx context.Context, req types.OrgRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/remove-member/", req, nil)
+	return err
+}
+
+// GetInvitations returns the user's pending invitations to organizations
+func (c *HTTPClient) GetInvitations(ctx context.Context) ([]orgs.Invitation, error) {
+	var resp struct {
+		Invitations []orgs.Invitation `json:"invitations"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/orgs/invitations/", nil, &resp)
+	return resp.Invitations, err
+}
+
+func (c *HTTPClient) AcceptInvitation(ctx context.Context, org string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/accept/", types.OrgRequest{Org: org}, nil)
+	return err
+}
+
+func (c *HTTPClient) DeclineInvitation(ctx context.Context, org string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/decline/", types.OrgRequest{Org: org}, nil)
+	return err
+}
+
+func (c *HTTPClient) CreateCollection(ctx context.Context, req types.OrgRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/collections/", req, nil)
+	return err
+}
+
+func (c *HTTPClient) DeleteCollection(ctx context.Context, req types.OrgRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/orgs/collections/delete/", req, nil)
+	return err
+}
+
+func (c *HTTPClient) GetCollections(ctx context.Context, org string) ([]string, error) {
+	var resp struct {
+		Collections []string `json:"collections"`
+	}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/orgs/collections/?org="+url.QueryEscape(org), nil, &resp)
+	return resp.Collections, err
+}
+
+// GetCollectionData returns names and types of secrets in organization's collection
+func (c *HTTPClient) GetCollectionData(ctx context.Context, org string, collection string) ([]storage.InfoMeta, error) {
+	var resp struct {
+		Infos []storage.InfoMeta `json:"infos"`
+	}
+	query := url.Values{"org": {org}, "collection": {collection}}
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/orgs/collections/data/?"+query.Encode(), nil, &resp)
+	return resp.Infos, err
+}
+
+// ShareSealed stores copy of the user's secret sealed for the recipient
+func (c *HTTPClient) ShareSealed(ctx context.Context, share storage.SealedShare) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/share-sealed/", share, nil)
+	return err
+}
+
+func (c *HTTPClient) GetSealedShare(ctx context.Context, req types.GetRequest) (storage.SealedShare, error) {
+	var share storage.SealedShare
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/get-sealed-share/", req, &share)
+	return share, err
+}
+
+func (c *HTTPClient) CreateLink(ctx context.Context, req types.LinkRequest) (types.LinkResponse, error) {
+	var link types.LinkResponse
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/links/", req, &link)
+	return link, err
+}
+
+// OpenLink uses one view of the one-time link, no login is needed
+func (c *HTTPClient) OpenLink(ctx context.Context, token string) (storage.Link, error) {
+	var link storage.Link
+	_, err := c.doJSON(ctx, http.MethodPost, "/links/"+url.PathEscape(token), nil, &link)
+	return link, err
+}
+
+// sendData sends secret's fields together with its metadata in one JSON object
+func (c *HTTPClient) sendData(ctx context.Context, path string, req storage.Info, meta types.GetRequest) error {
 	byteBody, err := json.Marshal(req)
 	if err != nil {
 		log.Println("error, while marshalling json body:", err)
 		return err
 	}
-	byteBody = []byte(string(byteBody[:len(byteBody)-1]) + fmt.Sprintf(", \"type\":\"%s\", \"name\":\"%s\"}", infoType, infoName))
-	reqBody := bytes.NewBuffer(byteBody)
-	httpReq, err := http.NewRequest("Post", c.address+"/user/add-data/", reqBody)
+	body := make(map[string]any)
+	if err = json.Unmarshal(byteBody, &body); err != nil {
+		return fmt.Errorf("cannot unmarshal request body: %w", err)
+	}
+	byteMeta, err := json.Marshal(meta)
 	if err != nil {
-		log.Println("error, while creating http request:", err)
 		return err
 	}
-	httpReq.Header.Set("Authorization", c.auth)
-	httpReq.Header.Set("Content-Type", "application/json")
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("error while doing request: %w", err)
+	if err = json.Unmarshal(byteMeta, &body); err != nil {
+		return fmt.Errorf("cannot unmarshal request body: %w", err)
 	}
-	resp.Body.Close()
-	if resp.StatusCode > 299 {
-		return fmt.Errorf("server returned status %d, error", resp.StatusCode)
-	}
-	return nil
+	_, err = c.doJSON(ctx, http.MethodPost, path, body, nil)
+	return err
 }
 
 func (c *HTTPClient) GetData(ctx context.Context, req types.GetRequest) (storage.Info, error) {
@@ -93,7 +374,7 @@ func (c *HTTPClient) Register(ctx context.Context, req types.AuthRequest) error
 		return err
 	}
 	reqBody := bytes.NewBuffer(byteBody)
-	httpReq, err := http.NewRequest("Post", c.address+"/user/auth/register/", reqBody)
+	httpReq, err := http.NewRequest(http.MethodPost, c.address+"/user/auth/register/", reqBody)
 	if err != nil {
 		log.Println("error, while creating http request:", err)
 		return err
@@ -103,7 +384,18 @@ func (c *HTTPClient) Register(ctx context.Context, req types.AuthRequest) error
 	if err != nil {
 		return fmt.Errorf("error while doing request: %w", err)
 	}
+	body, err := io.ReadAll(resp.Body)
 	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("cannot read response body: %w", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return tooManyAttempts(resp)
+	}
+	// the server explains why it rejected the password
+	if resp.StatusCode == http.StatusBadRequest && len(body) != 0 {
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
 	if resp.StatusCode > 299 {
 		return fmt.Errorf("server returned status %d, error", resp.StatusCode)
 	}
@@ -111,27 +403,180 @@ func (c *HTTPClient) Register(ctx context.Context, req types.AuthRequest) error
 	return nil
 }
 
-func (c *HTTPClient) Login(ctx context.Context, req types.AuthRequest) error {
+// Login returns challenge token if the user has to confirm login with TOTP code
+func (c *HTTPClient) Login(ctx context.Context, req types.AuthRequest) (string, error) {
 	byteBody, err := json.Marshal(req)
 	if err != nil {
 		log.Println("error, while marshalling json body:", err)
-		return err
+		return "", err
 	}
 	reqBody := bytes.NewBuffer(byteBody)
-	httpReq, err := http.NewRequest("Post", c.address+"/user/auth/login/", reqBody)
+	httpReq, err := http.NewRequest(http.MethodPost, c.address+"/user/auth/login/", reqBody)
 	if err != nil {
 		log.Println("error, while creating http request:", err)
-		return err
+		return "", err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("error while doing request: %w", err)
+		return "", fmt.Errorf("error while doing request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", tooManyAttempts(resp)
 	}
-	resp.Body.Close()
 	if resp.StatusCode > 299 {
-		return fmt.Errorf("server returned status %d, error", resp.StatusCode)
+		return "", fmt.Errorf("server returned status %d, error", resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusAccepted {
+		var challenge struct {
+			ChallengeToken string `json:"challenge_token"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&challenge); err != nil {
+			return "", fmt.Errorf("cannot unmarshal response body: %w", err)
+		}
+		return challenge.ChallengeToken, nil
+	}
+	c.auth = "Bearer " + resp.Header.Get("Authorization")
+	return "", nil
+}
+
+func (c *HTTPClient) LoginTOTP(ctx context.Context, req types.TOTPLoginRequest) error {
+	resp, err := c.doJSON(ctx, http.MethodPost, "/user/auth/login/totp/", req, nil)
+	if err != nil {
+		return err
 	}
 	c.auth = "Bearer " + resp.Header.Get("Authorization")
 	return nil
 }
+
+func (c *HTTPClient) EnrollTOTP(ctx context.Context) (types.TOTPEnrollment, error) {
+	var enrollment types.TOTPEnrollment
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/totp/enroll/", nil, &enrollment)
+	return enrollment, err
+}
+
+func (c *HTTPClient) ConfirmTOTP(ctx context.Context, code string) error {
+	req := struct {
+		Code string `json:"code"`
+	}{Code: code}
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/totp/confirm/", req, nil)
+	return err
+}
+
+func (c *HTTPClient) ChangePassword(ctx context.Context, req types.ChangePasswordRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/change-password/", req, nil)
+	return err
+}
+
+// GetVaultKey returns wrapped vault key or nil if the user has none
+func (c *HTTPClient) GetVaultKey(ctx context.Context) ([]byte, error) {
+	var vaultKey types.VaultKeyData
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/vault-key/", nil, &vaultKey)
+	return vaultKey.VaultKey, err
+}
+
+func (c *HTTPClient) SetVaultKey(ctx context.Context, vaultKey []byte) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/vault-key/", types.VaultKeyData{VaultKey: vaultKey}, nil)
+	return err
+}
+
+// GetKeyPair returns user's key pair, public key is empty if the user has none
+func (c *HTTPClient) GetKeyPair(ctx context.Context) (types.KeyPairData, error) {
+	var keyPair types.KeyPairData
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/keys/", nil, &keyPair)
+	return keyPair, err
+}
+
+func (c *HTTPClient) SetKeyPair(ctx context.Context, keyPair types.KeyPairData) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/keys/", keyPair, nil)
+	return err
+}
+
+func (c *HTTPClient) GetPublicKey(ctx context.Context, login string) ([]byte, error) {
+	var keyPair types.KeyPairData
+	_, err := c.doJSON(ctx, http.MethodGet, "/user/public-key/?login="+url.QueryEscape(login), nil, &keyPair)
+	return keyPair.PublicKey, err
+}
+
+// DeleteAccount deletes the user's account and forgets the user's token
+func (c *HTTPClient) DeleteAccount(ctx context.Context, password string) error {
+	req := struct {
+		Password string `json:"password"`
+	}{Password: password}
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/delete-account/", req, nil)
+	if err != nil {
+		return err
+	}
+	c.auth = ""
+	return nil
+}
+
+func (c *HTTPClient) SetRecoveryKey(ctx context.Context, recovery types.RecoveryKeyData) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/recovery-key/", recovery, nil)
+	return err
+}
+
+// GetRecoveryVaultKey returns vault key wrapped with the recovery key, no login is needed
+func (c *HTTPClient) GetRecoveryVaultKey(ctx context.Context, req types.RecoveryRequest) ([]byte, error) {
+	var vaultKey types.VaultKeyData
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/auth/recovery/", req, &vaultKey)
+	return vaultKey.VaultKey, err
+}
+
+// ResetPassword sets new password with the recovery key, the user has to log in afterwards
+func (c *HTTPClient) ResetPassword(ctx context.Context, req types.RecoveryRequest) error {
+	_, err := c.doJSON(ctx, http.MethodPost, "/user/auth/recovery/reset/", req, nil)
+	return err
+}
+
+// doJSON sends req as JSON body and decodes JSON response into resp if it is not nil and the response has content
+func (c *HTTPClient) doJSON(ctx context.Context, method string, path string, req any, resp any) (*http.Response, error) {
+	var reqBody io.Reader
+	if req != nil {
+		byteBody, err := json.Marshal(req)
+		if err != nil {
+			log.Println("error, while marshalling json body:", err)
+			return nil, err
+		}
+		reqBody = bytes.NewBuffer(byteBody)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.address+path, reqBody)
+	if err != nil {
+		log.Println("error, while creating http request:", err)
+		return nil, err
+	}
+	if c.auth != "" {
+		httpReq.Header.Set("Authorization", c.auth)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error while doing request: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode == http.StatusTooManyRequests {
+		return httpResp, tooManyAttempts(httpResp)
+	}
+	if httpResp.StatusCode == http.StatusBadRequest {
+		var invalid struct {
+			Fields storage.FieldErrors `json:"fields"`
+		}
+		if json.NewDecoder(httpResp.Body).Decode(&invalid) == nil && len(invalid.Fields) != 0 {
+			return httpResp, invalid.Fields
+		}
+	}
+	if httpResp.StatusCode > 299 {
+		return httpResp, fmt.Errorf("server returned status %d, error", httpResp.StatusCode)
+	}
+	if resp != nil && httpResp.StatusCode != http.StatusNoContent {
+		if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+			return httpResp, fmt.Errorf("cannot unmarshal response body: %w", err)
+		}
+	}
+	return httpResp, nil
+}
+
+func tooManyAttempts(resp *http.Response) error {
+	return fmt.Errorf("%w, retry after %s seconds", types.ErrTooManyAttempts, resp.Header.Get("Retry-After"))
+}
@@ -0,0 +1,47 @@
+package httpclient
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/handlers"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/config"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage/mockstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) *HTTPClient {
+	cfg := config.Config{
+		Address:   "localhost:8080",
+		SecretKey: "secretKeyReallyy",
+		JWTSecret: "jwt_secret",
+	}
+	s := handlers.NewServer(cfg)
+	ms := mockstorage.NewMockStorage()
+	s.Storage = ms
+	s.Auth = handlers.NewAuth(context.Background(), ms, cfg.JWTSecret)
+	server := httptest.NewServer(s.Route())
+	t.Cleanup(server.Close)
+	return NewHTTPClient(strings.TrimPrefix(server.URL, "http://"))
+}
+
+// TestRegisterAuthorizes tests the session of registration is used by the next requests without logging in
+func TestRegisterAuthorizes(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	require.NoError(t, c.Register(ctx, types.AuthRequest{Login: "user", Password: "password", VaultKey: []byte("wrapped")}))
+	keyPair, err := c.GetKeyPair(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keyPair.PublicKey)
+
+	keyPair = types.KeyPairData{PublicKey: []byte("public"), PrivateKey: []byte("sealed_private")}
+	require.NoError(t, c.SetKeyPair(ctx, keyPair))
+	got, err := c.GetKeyPair(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, keyPair, got)
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+func init() {
+	infoInputs[storage.Identity] = getIdentityFromUser
+	infoViews[storage.Identity] = showAttachments
+}
+
+// getIdentityFromUser asks for fields of the document and paths of its scans
+func getIdentityFromUser() (storage.Info, error) {
+	spec, _ := storage.Lookup(storage.Identity)
+	info, err := getFieldsFromUser(spec)
+	if err != nil {
+		return nil, err
+	}
+	identity := info.(*storage.InfoIdentity)
+	for len(identity.Attachments) < storage.MaxAttachments {
+		prompt := promptui.Prompt{Label: "Enter path to scan to attach or leave empty to finish"}
+		path, err := prompt.Run()
+		if err != nil {
+			return nil, err
+		}
+		if path == "" {
+			break
+		}
+		attachment, err := readAttachment(expandHome(path))
+		if err != nil {
+			fmt.Println("Cant attach the file:", err)
+			continue
+		}
+		identity.Attachments = append(identity.Attachments, attachment)
+	}
+	return identity, nil
+}
+
+func readAttachment(path string) (storage.Attachment, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return storage.Attachment{}, err
+	}
+	if stat.Size() > storage.MaxAttachmentSize {
+		return storage.Attachment{}, fmt.Errorf("file is larger than %d MiB", storage.MaxAttachmentSize>>20)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return storage.Attachment{}, err
+	}
+	return storage.Attachment{
+		Name:      filepath.Base(path),
+		MediaType: http.DetectContentType(data),
+		Data:      data,
+	}, nil
+}
+
+// showAttachments lists scans of the document and saves the chosen ones
+func showAttachments(info storage.Info) {
+	identity, ok := info.(*storage.InfoIdentity)
+	if !ok || len(identity.Attachments) == 0 {
+		return
+	}
+	for {
+		prompt := promptui.Select{
+			Label: "Attachments, choose one to save it",
+			Items: append([]string{"Done"}, attachmentItems(identity.Attachments)...),
+		}
+		idx, _, err := prompt.Run()
+		if err != nil || idx == 0 {
+			return
+		}
+		attachment := identity.Attachments[idx-1]
+		pathPrompt := promptui.Prompt{Label: "Save to", Default: attachment.Name}
+		path, err := pathPrompt.Run()
+		if err != nil {
+			continue
+		}
+		if err = os.WriteFile(expandHome(path), attachment.Data, 0600); err != nil {
+			fmt.Println("Cant save the attachment:", err)
+			continue
+		}
+		fmt.Printf("Saved to %s\n", path)
+	}
+}
+
+func attachmentItems(attachments []storage.Attachment) []string {
+	items := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		items = append(items, fmt.Sprintf("%s (%s, %d KiB)", attachment.Name, attachment.MediaType, (len(attachment.Data)+1023)/1024))
+	}
+	return items
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/vault"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+var errWrongLink = errors.New("error link is malformed")
+
+// linkPayload is what is encrypted with the link's key, the server sees only the ciphertext
+type linkPayload struct {
+	Type storage.InfoType `json:"type"`
+	Name string           `json:"name"`
+	Info json.RawMessage  `json:"info"`
+}
+
+// createLink encrypts the secret with new key and puts the key into the link's fragment,
+// fragments are never sent to servers so the server can't decrypt the secret
+func createLink(ctx context.Context, client clienttypes.ClientAction) {
+	req := clienttypes.GetRequest{Type: getInfoType(), Name: getInfoName()}
+	info, err := client.GetData(ctx, req)
+	if err != nil {
+		fmt.Println("Cant get your info!")
+		return
+	}
+	plain, err := json.Marshal(info)
+	if err != nil {
+		fmt.Println("Cant encode your info!")
+		return
+	}
+	payload, err := json.Marshal(linkPayload{Type: req.Type, Name: req.Name, Info: plain})
+	if err != nil {
+		fmt.Println("Cant encode your info!")
+		return
+	}
+	linkKey, err := vault.NewKey()
+	if err != nil {
+		fmt.Println("Cant create link key!")
+		return
+	}
+	data, err := vault.Seal(payload, linkKey)
+	if err != nil {
+		fmt.Println("Cant encrypt your info!")
+		return
+	}
+	maxViews, err := getIntFromUser("Max views", "1")
+	if err != nil {
+		fmt.Println("Wrong number of views!")
+		return
+	}
+	expiresIn, err := getDurationFromUser("Expires in", "24h")
+	if err != nil {
+		fmt.Println("Wrong expiry!")
+		return
+	}
+	link, err := client.CreateLink(ctx, clienttypes.LinkRequest{
+		Name:      req.Name,
+		Type:      req.Type,
+		Data:      data,
+		MaxViews:  maxViews,
+		ExpiresIn: int64(expiresIn / time.Second),
+	})
+	if err != nil {
+		fmt.Println("Cant create link!")
+		return
+	}
+	fmt.Printf("Anyone with this link can view the secret %d time(s) until %s:\n", link.MaxViews, formatTime(link.ExpiresAt))
+	fmt.Println(link.URL + "#" + base64.RawURLEncoding.EncodeToString(linkKey))
+}
+
+// openLink opens one-time link without login, the link can't be opened again after its last view
+func openLink(ctx context.Context, client clienttypes.ClientAction) {
+	token, linkKey, err := parseLink(getValueFromUser("Paste the link"))
+	if err != nil {
+		fmt.Println("Wrong link!")
+		return
+	}
+	link, err := client.OpenLink(ctx, token)
+	if err != nil {
+		fmt.Println("Link is used up, expired or never existed!")
+		return
+	}
+	plain, err := vault.Open(link.Data, linkKey)
+	if err != nil {
+		fmt.Println("Cant decrypt the secret, the link is incomplete!")
+		return
+	}
+	var payload linkPayload
+	if err = json.Unmarshal(plain, &payload); err != nil {
+		fmt.Println("Cant decode the secret!")
+		return
+	}
+	info := storage.NewInfo(payload.Type)
+	if info == nil || json.Unmarshal(payload.Info, info) != nil {
+		fmt.Println("Cant decode the secret!")
+		return
+	}
+	fmt.Printf("%s %q:\n", payload.Type, payload.Name)
+	printInfo(payload.Type, info)
+	fmt.Printf("Views left: %d\n", link.ViewsLeft)
+}
+
+// parseLink returns token from the link's path and key from its fragment
+func parseLink(link string) (token string, key []byte, err error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", nil, err
+	}
+	token = path.Base(u.Path)
+	key, err = base64.RawURLEncoding.DecodeString(u.Fragment)
+	if err != nil || len(key) != vault.KeySize || token == "" || token == "." || token == "/" {
+		return "", nil, errWrongLink
+	}
+	return token, key, nil
+}
+
+func getIntFromUser(label string, def string) (int, error) {
+	prompt := promptui.Prompt{
+		Label:   label,
+		Default: def,
+	}
+	value, err := prompt.Run()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+func getDurationFromUser(label string, def string) (time.Duration, error) {
+	prompt := promptui.Prompt{
+		Label:   label,
+		Default: def,
+	}
+	value, err := prompt.Run()
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+func init() {
+	infoInputs[storage.LoginPassword] = getLoginFromUser
+}
+
+// getLoginFromUser asks for login and password and for sites they are used on
+func getLoginFromUser() (storage.Info, error) {
+	spec, _ := storage.Lookup(storage.LoginPassword)
+	info, err := getFieldsFromUser(spec)
+	if err != nil {
+		return nil, err
+	}
+	login := info.(*storage.InfoLoginPass)
+	for {
+		prompt := promptui.Prompt{Label: "Enter site the login is used on or leave empty to finish"}
+		uri, err := prompt.Run()
+		if err != nil {
+			return nil, err
+		}
+		if uri == "" {
+			return login, nil
+		}
+		match := promptui.Select{Label: "Select how the site is matched", Items: storage.MatchRules}
+		idx, _, err := match.Run()
+		if err != nil {
+			return nil, err
+		}
+		loginURI := storage.LoginURI{URI: uri, Match: storage.MatchRules[idx]}
+		if err = loginURI.Validate(); err != nil {
+			fmt.Println("Site", err)
+			continue
+		}
+		login.URIs = append(login.URIs, loginURI)
+	}
+}
+
+// findLogins shows the user's logins used on the URL
+func findLogins(ctx context.Context, client clienttypes.ClientAction) {
+	prompt := promptui.Prompt{
+		Label: "Enter URL",
+		Validate: func(value string) error {
+			_, err := storage.ParseLoginURL(value)
+			return err
+		},
+	}
+	url, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	logins, err := client.MatchLogins(ctx, url)
+	if err != nil {
+		fmt.Println("Cant find your logins!")
+		return
+	}
+	if len(logins) == 0 {
+		fmt.Println("No logins are used on the URL")
+		return
+	}
+	for _, login := range logins {
+		fmt.Printf("%s: %s\n", login.Name, login.Login)
+	}
+}
@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+const (
+	orgInvitations   = "Invitations"
+	orgCreate        = "Create organization"
+	orgBack          = "Back"
+	orgCollections   = "Collections"
+	orgMembers       = "Members"
+	orgInvite        = "Invite member"
+	orgSetRole       = "Change member role"
+	orgRemoveMember  = "Remove member"
+	orgLeave         = "Leave organization"
+	orgDelete        = "Delete organization"
+	collectionCreate = "Create collection"
+	collectionAdd    = "Add secret"
+	collectionGet    = "Get secret"
+	collectionUpdate = "Update secret"
+	collectionRemove = "Delete secret"
+	collectionDelete = "Delete collection"
+)
+
+var roles = []orgs.Role{orgs.RoleOwner, orgs.RoleAdmin, orgs.RoleMember, orgs.RoleReadOnly}
+
+// showOrgs lists the user's organizations, pending invitations and lets create new organization
+func showOrgs(ctx context.Context, client clienttypes.ClientAction) {
+	memberships, err := client.GetOrgs(ctx)
+	if err != nil {
+		fmt.Println("Cant get your organizations!")
+		return
+	}
+	items := []string{orgInvitations, orgCreate}
+	for _, membership := range memberships {
+		items = append(items, fmt.Sprintf("%s (%s)", membership.Org, membership.Role))
+	}
+	items = append(items, orgBack)
+	prompt := promptui.Select{
+		Label: "Organizations",
+		Items: items,
+	}
+	idx, choice, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	switch choice {
+	case orgInvitations:
+		showInvitations(ctx, client)
+	case orgCreate:
+		name := getValueFromUser("Enter organization name")
+		if err = client.CreateOrg(ctx, name); err != nil {
+			fmt.Println("Cant create organization!")
+			return
+		}
+		fmt.Println("Organization created!")
+	case orgBack:
+		return
+	default:
+		manageOrg(ctx, client, memberships[idx-2])
+	}
+}
+
+func showInvitations(ctx context.Context, client clienttypes.ClientAction) {
+	invitations, err := client.GetInvitations(ctx)
+	if err != nil {
+		fmt.Println("Cant get your invitations!")
+		return
+	}
+	if len(invitations) == 0 {
+		fmt.Println("You have no invitations")
+		return
+	}
+	prompt := promptui.Select{
+		Label: "Invitations",
+		Items: invitations,
+		Templates: &promptui.SelectTemplates{
+			Active:   "> {{ .Org }} as {{ .Role }} from {{ .InvitedBy }}",
+			Inactive: "  {{ .Org }} as {{ .Role }} from {{ .InvitedBy }}",
+			Selected: "{{ .Org }}",
+		},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	org := invitations[idx].Org
+	answer := promptui.Select{
+		Label: fmt.Sprintf("Join %s?", org),
+		Items: []string{"Accept", "Decline"},
+	}
+	idx, _, err = answer.Run()
+	if err != nil {
+		return
+	}
+	if idx == 0 {
+		err = client.AcceptInvitation(ctx, org)
+	} else {
+		err = client.DeclineInvitation(ctx, org)
+	}
+	if err != nil {
+		fmt.Println("Cant answer the invitation!")
+		return
+	}
+	fmt.Println("Done!")
+}
+
+// manageOrg shows actions allowed by the user's role in the organization
+func manageOrg(ctx context.Context, client clienttypes.ClientAction, membership orgs.Membership) {
+	items := []string{orgCollections, orgMembers}
+	if membership.Role.CanManage() {
+		items = append(items, orgInvite, orgSetRole, orgRemoveMember)
+	}
+	items = append(items, orgLeave)
+	if membership.Role.CanDelete() {
+		items = append(items, orgDelete)
+	}
+	items = append(items, orgBack)
+	prompt := promptui.Select{
+		Label: membership.Org,
+		Items: items,
+	}
+	_, choice, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	req := clienttypes.OrgRequest{Org: membership.Org}
+	switch choice {
+	case orgCollections:
+		showCollections(ctx, client, membership)
+		return
+	case orgMembers:
+		members, err := client.GetMembers(ctx, membership.Org)
+		if err != nil {
+			fmt.Println("Cant get members!")
+			return
+		}
+		for _, member := range members {
+			fmt.Printf("  %s (%s)\n", member.Login, member.Role)
+		}
+		return
+	case orgInvite:
+		req.Login = getValueFromUser("Enter login of the user to invite")
+		if req.Role, err = getRole(); err == nil {
+			err = client.InviteMember(ctx, req)
+		}
+	case orgSetRole:
+		req.Login = getValueFromUser("Enter login of the member")
+		if req.Role, err = getRole(); err == nil {
+			err = client.SetMemberRole(ctx, req)
+		}
+	case orgRemoveMember:
+		req.Login = getValueFromUser("Enter login of the member")
+		err = client.RemoveMember(ctx, req)
+	case orgLeave:
+		req.Login = getValueFromUser("Enter your login to confirm")
+		err = client.RemoveMember(ctx, req)
+	case orgDelete:
+		confirm := promptui.Prompt{
+			Label:     fmt.Sprintf("Delete %s with all of its secrets", membership.Org),
+			IsConfirm: true,
+		}
+		if _, err = confirm.Run(); err == nil {
+			err = client.DeleteOrg(ctx, membership.Org)
+		}
+	case orgBack:
+		return
+	}
+	if err != nil {
+		fmt.Println("Cant do it!")
+		return
+	}
+	fmt.Println("Done!")
+}
+
+func showCollections(ctx context.Context, client clienttypes.ClientAction, membership orgs.Membership) {
+	collections, err := client.GetCollections(ctx, membership.Org)
+	if err != nil {
+		fmt.Println("Cant get collections!")
+		return
+	}
+	items := append([]string{}, collections...)
+	if membership.Role.CanManage() {
+		items = append(items, collectionCreate)
+	}
+	items = append(items, orgBack)
+	prompt := promptui.Select{
+		Label: membership.Org + " collections",
+		Items: items,
+	}
+	idx, choice, err := prompt.Run()
+	if err != nil || choice == orgBack {
+		return
+	}
+	if idx >= len(collections) {
+		req := clienttypes.OrgRequest{Org: membership.Org, Name: getValueFromUser("Enter collection name")}
+		if err = client.CreateCollection(ctx, req); err != nil {
+			fmt.Println("Cant create collection!")
+			return
+		}
+		fmt.Println("Collection created!")
+		return
+	}
+	manageCollection(ctx, client, membership, collections[idx])
+}
+
+// manageCollection lists secrets of the collection and works with them like with personal secrets
+func manageCollection(ctx context.Context, client clienttypes.ClientAction, membership orgs.Membership, collection string) {
+	infos, err := client.GetCollectionData(ctx, membership.Org, collection)
+	if err != nil {
+		fmt.Println("Cant get collection secrets!")
+		return
+	}
+	for _, info := range infos {
+		fmt.Printf("  %s %q\n", info.Type, info.Name)
+	}
+	items := []string{collectionGet}
+	if membership.Role.CanWrite() {
+		items = append(items, collectionAdd, collectionUpdate, collectionRemove)
+	}
+	if membership.Role.CanManage() {
+		items = append(items, collectionDelete)
+	}
+	items = append(items, orgBack)
+	prompt := promptui.Select{
+		Label: membership.Org + "/" + collection,
+		Items: items,
+	}
+	_, choice, err := prompt.Run()
+	if err != nil || choice == orgBack {
+		return
+	}
+	if choice == collectionDelete {
+		confirm := promptui.Prompt{
+			Label:     fmt.Sprintf("Delete collection %s with all of its secrets", collection),
+			IsConfirm: true,
+		}
+		if _, err = confirm.Run(); err != nil {
+			return
+		}
+		if err = client.DeleteCollection(ctx, clienttypes.OrgRequest{Org: membership.Org, Name: collection}); err != nil {
+			fmt.Println("Cant delete collection!")
+			return
+		}
+		fmt.Println("Collection deleted!")
+		return
+	}
+	meta := clienttypes.GetRequest{Type: getInfoType(), Name: getInfoName(), Org: membership.Org, Collection: collection}
+	switch choice {
+	case collectionGet:
+		showInfo(ctx, client, meta)
+	case collectionAdd, collectionUpdate:
+		send := client.SaveDataAt
+		if choice == collectionUpdate {
+			send = client.UpdateDataAt
+		}
+		saveInfo(ctx, meta.Type, meta.Name, func(ctx context.Context, req storage.Info, infoType storage.InfoType, infoName string) error {
+			return send(ctx, req, meta)
+		})
+	case collectionRemove:
+		if err = client.DeleteData(ctx, meta); err != nil {
+			fmt.Println("Cant delete the secret!")
+			return
+		}
+		fmt.Println("Secret deleted!")
+	}
+}
+
+func getRole() (orgs.Role, error) {
+	prompt := promptui.Select{
+		Label: "Select role",
+		Items: roles,
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+	return roles[idx], nil
+}
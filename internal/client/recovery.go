@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/vault"
+	"github.com/manifoldco/promptui"
+)
+
+// newRecoveryKey generates recovery key and wraps the vault key with it
+func newRecoveryKey(vaultKey []byte) (string, clienttypes.RecoveryKeyData, error) {
+	recoveryKey, err := vault.NewRecoveryKey()
+	if err != nil {
+		return "", clienttypes.RecoveryKeyData{}, err
+	}
+	wrappedKey, err := vault.Wrap(vaultKey, recoveryKey)
+	if err != nil {
+		return "", clienttypes.RecoveryKeyData{}, fmt.Errorf("error: can't wrap vault key: %w", err)
+	}
+	return recoveryKey, clienttypes.RecoveryKeyData{
+		VaultKey: wrappedKey,
+		Verifier: vault.RecoveryVerifier(recoveryKey),
+	}, nil
+}
+
+// showRecoveryKey shows the recovery key once and waits until the user confirms it is written down
+func showRecoveryKey(recoveryKey string) {
+	fmt.Println("Your recovery key:")
+	fmt.Println()
+	fmt.Println("    " + recoveryKey)
+	fmt.Println()
+	fmt.Println("Write it down and keep it offline. It is shown only once and is the only way")
+	fmt.Println("to reset forgotten password without losing your secrets.")
+	confirm := promptui.Prompt{
+		Label:     "I have written down my recovery key",
+		IsConfirm: true,
+	}
+	for {
+		if _, err := confirm.Run(); err == nil {
+			return
+		}
+	}
+}
+
+// createRecoveryKey replaces the user's recovery key, the previous one stops working
+func createRecoveryKey(ctx context.Context, cli *CommandLine) {
+	confirm := promptui.Prompt{
+		Label:     "Create new recovery key, the old one will stop working",
+		IsConfirm: true,
+	}
+	if _, err := confirm.Run(); err != nil {
+		return
+	}
+	recoveryKey, recovery, err := newRecoveryKey(cli.vaultKey)
+	if err != nil {
+		fmt.Println("Cant create recovery key!")
+		return
+	}
+	if err = cli.action.act.SetRecoveryKey(ctx, recovery); err != nil {
+		fmt.Println("Cant save recovery key!")
+		return
+	}
+	showRecoveryKey(recoveryKey)
+}
+
+// resetPassword sets new password with the recovery key, the vault key is re-wrapped so the user's secrets are kept
+func resetPassword(ctx context.Context, cli *CommandLine) error {
+	login, err := getLogin()
+	if err != nil {
+		return fmt.Errorf("error: can't get username: %w", err)
+	}
+	typed, err := getMaskedValueFromUser("Enter your recovery key")
+	if err != nil {
+		return fmt.Errorf("error: can't get recovery key: %w", err)
+	}
+	recoveryKey, err := vault.NormalizeRecoveryKey(typed)
+	if err != nil {
+		return fmt.Errorf("error: wrong recovery key: %w", err)
+	}
+	req := clienttypes.RecoveryRequest{Login: login, Verifier: vault.RecoveryVerifier(recoveryKey)}
+	wrappedKey, err := cli.action.act.GetRecoveryVaultKey(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error: can't recover account: %w", err)
+	}
+	vaultKey, err := vault.Unwrap(wrappedKey, recoveryKey)
+	if err != nil {
+		return fmt.Errorf("error: can't unwrap vault key: %w", err)
+	}
+	req.NewPassword, err = getPassword()
+	if err != nil {
+		return fmt.Errorf("error: can't get password: %w", err)
+	}
+	req.VaultKey, err = vault.Wrap(vaultKey, req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("error: can't wrap vault key: %w", err)
+	}
+	if err = cli.action.act.ResetPassword(ctx, req); err != nil {
+		return fmt.Errorf("error: can't reset password: %w", err)
+	}
+	fmt.Println("Password reset! Log in with the new password")
+
+	return authorize(ctx, cli)
+}
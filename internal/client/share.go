@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/vault"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+const sealedAccessLabel = "sealed: end-to-end encrypted read-only copy"
+
+func shareInfo(ctx context.Context, cli *CommandLine) {
+	client := cli.action.act
+	meta := clienttypes.GetRequest{Type: getInfoType(), Name: getInfoName()}
+	shares, err := client.GetShares(ctx, meta)
+	if err != nil {
+		fmt.Println("Cant get users the secret is shared with!")
+		return
+	}
+	printShares(shares)
+	req := clienttypes.ShareRequest{
+		Type:      meta.Type,
+		Name:      meta.Name,
+		Recipient: getValueFromUser("Enter login of the user to share with"),
+	}
+	accesses := []storage.Access{storage.AccessRead, storage.AccessReadWrite, storage.AccessSealed}
+	prompt := promptui.Select{
+		Label: "Select access",
+		Items: []string{string(storage.AccessRead), string(storage.AccessReadWrite), sealedAccessLabel},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		fmt.Println("Secret was not shared")
+		return
+	}
+	req.Access = accesses[idx]
+	if req.Access == storage.AccessSealed {
+		err = shareSealed(ctx, client, req)
+	} else {
+		err = client.ShareData(ctx, req)
+	}
+	if err != nil {
+		fmt.Println("Cant share your info!")
+		return
+	}
+	fmt.Printf("Secret shared with %s!\n", req.Recipient)
+}
+
+func revokeShare(ctx context.Context, client clienttypes.ClientAction) {
+	meta := clienttypes.GetRequest{Type: getInfoType(), Name: getInfoName()}
+	shares, err := client.GetShares(ctx, meta)
+	if err != nil {
+		fmt.Println("Cant get users the secret is shared with!")
+		return
+	}
+	if len(shares) == 0 {
+		fmt.Println("Secret is not shared with anyone")
+		return
+	}
+	prompt := promptui.Select{
+		Label: "Select user to revoke access from",
+		Items: shares,
+		Templates: &promptui.SelectTemplates{
+			Active:   "> {{ .Login }} ({{ .Access }})",
+			Inactive: "  {{ .Login }} ({{ .Access }})",
+			Selected: "{{ .Login }}",
+		},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		fmt.Println("Access was not revoked")
+		return
+	}
+	req := clienttypes.ShareRequest{Type: meta.Type, Name: meta.Name, Recipient: shares[idx].Login}
+	if err = client.RevokeShare(ctx, req); err != nil {
+		fmt.Println("Cant revoke access!")
+		return
+	}
+	fmt.Printf("Access of %s revoked!\n", req.Recipient)
+}
+
+// shareSealed encrypts the secret with new item key and seals the item key for the recipient's public key,
+// so the server stores the copy without being able to read it
+func shareSealed(ctx context.Context, client clienttypes.ClientAction, req clienttypes.ShareRequest) error {
+	publicKey, err := client.GetPublicKey(ctx, req.Recipient)
+	if err != nil {
+		return fmt.Errorf("error while getting public key: %w", err)
+	}
+	info, err := client.GetData(ctx, clienttypes.GetRequest{Type: req.Type, Name: req.Name})
+	if err != nil {
+		return fmt.Errorf("error while getting secret: %w", err)
+	}
+	plain, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("error while marshalling secret: %w", err)
+	}
+	itemKey, err := vault.NewKey()
+	if err != nil {
+		return err
+	}
+	data, err := vault.Seal(plain, itemKey)
+	if err != nil {
+		return err
+	}
+	sealedKey, err := vault.SealFor(itemKey, publicKey)
+	if err != nil {
+		return err
+	}
+	return client.ShareSealed(ctx, storage.SealedShare{
+		Type:      req.Type,
+		Name:      req.Name,
+		Recipient: req.Recipient,
+		SealedKey: sealedKey,
+		Data:      data,
+	})
+}
+
+// openSealed decrypts copy of the secret sealed for the user
+func openSealed(ctx context.Context, cli *CommandLine, req clienttypes.GetRequest) (storage.Info, error) {
+	share, err := cli.action.act.GetSealedShare(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting sealed secret: %w", err)
+	}
+	itemKey, err := vault.OpenSealed(share.SealedKey, cli.publicKey, cli.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := vault.Open(share.Data, itemKey)
+	if err != nil {
+		return nil, err
+	}
+	info := storage.NewInfo(req.Type)
+	if info == nil {
+		return nil, storage.ErrInvalidData
+	}
+	if err = json.Unmarshal(plain, info); err != nil {
+		return nil, fmt.Errorf("error while unmarshalling secret: %w", err)
+	}
+	return info, nil
+}
+
+// showSharedWithMe lists secrets of other users shared with the user and opens the chosen one
+func showSharedWithMe(ctx context.Context, cli *CommandLine) {
+	client := cli.action.act
+	infos, err := client.GetSharedWithMe(ctx)
+	if err != nil {
+		fmt.Println("Cant get secrets shared with you!")
+		return
+	}
+	if len(infos) == 0 {
+		fmt.Println("No secrets are shared with you")
+		return
+	}
+	prompt := promptui.Select{
+		Label: "Secrets shared with you",
+		Items: infos,
+		Templates: &promptui.SelectTemplates{
+			Active:   "> {{ .Type }} {{ .Name | printf \"%q\" }} of {{ .Owner }} ({{ .Access }})",
+			Inactive: "  {{ .Type }} {{ .Name | printf \"%q\" }} of {{ .Owner }} ({{ .Access }})",
+			Selected: "{{ .Type }} {{ .Name | printf \"%q\" }} of {{ .Owner }}",
+		},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	info := infos[idx]
+	req := clienttypes.GetRequest{Type: info.Type, Name: info.Name, Owner: info.Owner}
+	if info.Access == storage.AccessSealed {
+		data, err := openSealed(ctx, cli, req)
+		if err != nil {
+			fmt.Println("Cant open the secret!")
+			return
+		}
+		printInfo(req.Type, data)
+		return
+	}
+	if info.Access != storage.AccessReadWrite {
+		showInfo(ctx, client, req)
+		return
+	}
+	actionPrompt := promptui.Select{
+		Label: "What would you like to do with the secret?",
+		Items: []string{"View", "Edit"},
+	}
+	idx, _, err = actionPrompt.Run()
+	if err != nil {
+		return
+	}
+	if idx == 0 {
+		showInfo(ctx, client, req)
+		return
+	}
+	saveInfo(ctx, info.Type, info.Name, func(ctx context.Context, data storage.Info, infoType storage.InfoType, infoName string) error {
+		return client.UpdateDataAt(ctx, data, req)
+	})
+}
+
+func printShares(shares []storage.Share) {
+	if len(shares) == 0 {
+		fmt.Println("Secret is not shared with anyone yet")
+		return
+	}
+	fmt.Println("Secret is shared with:")
+	for _, share := range shares {
+		fmt.Printf("  %s (%s)\n", share.Login, share.Access)
+	}
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/sshkey"
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func init() {
+	infoInputs[storage.SSHKey] = getSSHKeyFromUser
+}
+
+// getSSHKeyFromUser generates new key or imports OpenSSH key file
+func getSSHKeyFromUser() (storage.Info, error) {
+	prompt := promptui.Select{
+		Label: "SSH key",
+		Items: []string{"Generate new ed25519 key", "Import OpenSSH key file"},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return nil, err
+	}
+	if idx == 0 {
+		info, err := sshkey.Generate(getValueFromUser("Enter comment"))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Println("Add the public key to authorized_keys of your servers:")
+		fmt.Println(info.PublicKey)
+		return &info, nil
+	}
+	return importSSHKey()
+}
+
+func importSSHKey() (storage.Info, error) {
+	path := expandHome(getValueFromUser("Enter path to private key file"))
+	privateKey, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading private key: %w", err)
+	}
+	// comment is kept in the encrypted part of the key, so it is taken from the public key next to it
+	comment := ""
+	if publicKey, err := os.ReadFile(path + ".pub"); err == nil {
+		comment, _ = sshkey.ParsePublicKey(publicKey)
+	}
+	if comment == "" {
+		comment = getValueFromUser("Enter comment")
+	}
+	info, err := sshkey.Import(privateKey, "", comment)
+	if errors.Is(err, sshkey.ErrPassphraseRequired) {
+		passphrase, err := getMaskedValueFromUser("Enter passphrase of the key")
+		if err != nil {
+			return nil, err
+		}
+		info, err = sshkey.Import(privateKey, passphrase, comment)
+		if err != nil {
+			return nil, err
+		}
+		return &info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// RunSSHAgent logs the user in and serves SSH keys of the vault over the agent protocol until ctx is done,
+// the keys are kept in memory only
+func (cli *CommandLine) RunSSHAgent(ctx context.Context, socketPath string) error {
+	err := cli.Authentication(ctx)
+	if err == clienttypes.ErrExitCLI {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Authentication error: %w", err)
+	}
+	keyring, err := loadSSHKeys(ctx, cli.action.act)
+	if err != nil {
+		return err
+	}
+	defer keyring.RemoveAll()
+	if socketPath == "" {
+		dir, err := os.MkdirTemp("", "gophkeeper-agent-")
+		if err != nil {
+			return fmt.Errorf("error while creating socket directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		socketPath = filepath.Join(dir, "agent.sock")
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("error while listening on %s: %w", socketPath, err)
+	}
+	defer listener.Close()
+	if err = os.Chmod(socketPath, 0600); err != nil {
+		return fmt.Errorf("error while restricting socket permissions: %w", err)
+	}
+	fmt.Printf("SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", socketPath)
+	fmt.Println("Agent is running, press Ctrl+C to stop it")
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("error while accepting connection: %w", err)
+		}
+		go func() {
+			defer conn.Close()
+			if err := agent.ServeAgent(keyring, conn); err != nil && !errors.Is(err, io.EOF) {
+				log.Println("error while serving ssh agent:", err)
+			}
+		}()
+	}
+}
+
+// loadSSHKeys decrypts all SSH keys of the user into new keyring, keys which can't be decrypted are skipped
+func loadSSHKeys(ctx context.Context, client clienttypes.ClientAction) (agent.Agent, error) {
+	infos, err := client.ListData(ctx, storage.SSHKey)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing ssh keys: %w", err)
+	}
+	keyring := agent.NewKeyring()
+	for _, meta := range infos {
+		data, err := client.GetData(ctx, clienttypes.GetRequest{Type: meta.Type, Name: meta.Name})
+		if err != nil {
+			fmt.Printf("Cant get ssh key %q!\n", meta.Name)
+			continue
+		}
+		info, ok := data.(*storage.InfoSSHKey)
+		if !ok {
+			continue
+		}
+		key, err := sshkey.RawKey(info)
+		if err != nil {
+			fmt.Printf("Cant decrypt ssh key %q: %v\n", meta.Name, err)
+			continue
+		}
+		comment := info.Comment
+		if comment == "" {
+			comment = meta.Name
+		}
+		if err = keyring.Add(agent.AddedKey{PrivateKey: key, Comment: comment}); err != nil {
+			fmt.Printf("Cant add ssh key %q: %v\n", meta.Name, err)
+			continue
+		}
+		fmt.Printf("Loaded ssh key %q\n", meta.Name)
+	}
+	return keyring, nil
+}
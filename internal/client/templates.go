@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	clienttypes "github.com/AbramovArseniy/GophKeeper/internal/client/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/manifoldco/promptui"
+)
+
+const (
+	templatesUse    = "Add secret from template"
+	templatesCreate = "Create template"
+	templatesDelete = "Delete template"
+	templatesBack   = "Back"
+)
+
+// showTemplates lets the user manage templates kept on the server and create secrets from them
+func showTemplates(ctx context.Context, client clienttypes.ClientAction) {
+	prompt := promptui.Select{
+		Label: "Templates",
+		Items: []string{templatesUse, templatesCreate, templatesDelete, templatesBack},
+	}
+	_, choice, err := prompt.Run()
+	if err != nil {
+		return
+	}
+	switch choice {
+	case templatesUse:
+		if template, ok := selectTemplate(ctx, client); ok {
+			addFromTemplate(ctx, client, template)
+		}
+	case templatesCreate:
+		createTemplate(ctx, client)
+	case templatesDelete:
+		template, ok := selectTemplate(ctx, client)
+		if !ok {
+			return
+		}
+		if err := client.DeleteTemplate(ctx, template.Name); err != nil {
+			fmt.Println("Cant delete the template!")
+			return
+		}
+		fmt.Println("Template deleted!")
+	}
+}
+
+func selectTemplate(ctx context.Context, client clienttypes.ClientAction) (storage.Template, bool) {
+	templates, err := client.GetTemplates(ctx)
+	if err != nil {
+		fmt.Println("Cant get your templates!")
+		return storage.Template{}, false
+	}
+	if len(templates) == 0 {
+		fmt.Println("You have no templates")
+		return storage.Template{}, false
+	}
+	prompt := promptui.Select{
+		Label: "Your templates",
+		Items: templates,
+		Templates: &promptui.SelectTemplates{
+			Active:   "> {{ .Name }} ({{ .Type }})",
+			Inactive: "  {{ .Name }} ({{ .Type }})",
+			Selected: "{{ .Name }}",
+		},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return storage.Template{}, false
+	}
+	return templates[idx], true
+}
+
+// addFromTemplate asks for fields of the template's type, then for the template's fields and stores the secret
+func addFromTemplate(ctx context.Context, client clienttypes.ClientAction, template storage.Template) {
+	spec, ok := storage.Lookup(template.Type)
+	if !ok {
+		fmt.Println("Unknown secret type!")
+		return
+	}
+	infoName := getInfoName()
+	req, err := inputInfo(spec)
+	if err != nil {
+		fmt.Println("Secret was not saved:", err)
+		return
+	}
+	custom, ok := req.(storage.Customizable)
+	if !ok {
+		fmt.Println("Secrets of the type can't have custom fields!")
+		return
+	}
+	fields := custom.CustomFieldSet()
+	for _, templateField := range template.Fields {
+		value, err := getFieldFromUser(templateField.Field())
+		if err != nil {
+			fmt.Println("Secret was not saved:", err)
+			return
+		}
+		fields.Custom = append(fields.Custom, storage.CustomField{Name: templateField.Name, Kind: templateField.Kind, Value: value})
+	}
+	if info, ok := req.(*storage.InfoCustom); ok {
+		info.Template = template.Name
+	}
+	err = addCustomFields(req)
+	if err == nil {
+		err = addPolicy(req)
+	}
+	if err != nil {
+		fmt.Println("Secret was not saved:", err)
+		return
+	}
+	storeInfo(ctx, spec, req, infoName, client.SaveData)
+}
+
+func createTemplate(ctx context.Context, client clienttypes.ClientAction) {
+	template := storage.Template{Name: getValueFromUser("Enter template name")}
+	fmt.Println("Choose type of secrets, their fields are asked before the template's ones")
+	template.Type = getInfoType()
+	for len(template.Fields) < storage.MaxCustomFields {
+		prompt := promptui.Prompt{Label: "Enter name of the template's field or leave empty to finish"}
+		name, err := prompt.Run()
+		if err != nil {
+			return
+		}
+		if name == "" {
+			break
+		}
+		kind, err := getFieldKind()
+		if err != nil {
+			return
+		}
+		required := promptui.Select{Label: "Is the field required?", Items: []string{"No", "Yes"}}
+		idx, _, err := required.Run()
+		if err != nil {
+			return
+		}
+		template.Fields = append(template.Fields, storage.TemplateField{Name: name, Kind: kind, Required: idx == 1})
+	}
+	err := template.Validate()
+	if err == nil {
+		err = client.SaveTemplate(ctx, template)
+	}
+	var fieldErrs storage.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		fmt.Println("Template was not saved:", fieldErrs)
+		return
+	}
+	if err != nil {
+		fmt.Println("Cant save the template!")
+		return
+	}
+	fmt.Println("Template saved!")
+}
+
+// addCustomFields asks for fields the user adds to the secret until the user is done
+func addCustomFields(info storage.Info) error {
+	custom, ok := info.(storage.Customizable)
+	if !ok {
+		return nil
+	}
+	fields := custom.CustomFieldSet()
+	for len(fields.Custom) < storage.MaxCustomFields {
+		prompt := promptui.Prompt{Label: "Enter name of custom field to add or leave empty to finish"}
+		name, err := prompt.Run()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return nil
+		}
+		kind, err := getFieldKind()
+		if err != nil {
+			return err
+		}
+		field := storage.CustomField{Name: name, Kind: kind}
+		field.Value, err = getFieldFromUser(field.Field())
+		if err != nil {
+			return err
+		}
+		fields.Custom = append(fields.Custom, field)
+	}
+	return nil
+}
+
+func getFieldKind() (storage.FieldKind, error) {
+	items := make([]string, 0, len(storage.CustomFieldKinds))
+	for _, kind := range storage.CustomFieldKinds {
+		items = append(items, kindName(kind))
+	}
+	prompt := promptui.Select{Label: "Select kind of the field", Items: items}
+	idx, _, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+	return storage.CustomFieldKinds[idx], nil
+}
+
+func kindName(kind storage.FieldKind) string {
+	if kind == storage.KindText {
+		return "text"
+	}
+	return string(kind)
+}
+
+// revealHiddenFields prints values of hidden custom fields only if the user asks for them
+func revealHiddenFields(info storage.Info) {
+	custom, ok := info.(storage.Customizable)
+	if !ok {
+		return
+	}
+	var hidden []storage.CustomField
+	for _, field := range custom.CustomFieldSet().Custom {
+		if field.Kind == storage.KindHidden {
+			hidden = append(hidden, field)
+		}
+	}
+	if len(hidden) == 0 {
+		return
+	}
+	prompt := promptui.Select{
+		Label: "Hidden fields",
+		Items: []string{"Keep hidden", "Reveal"},
+	}
+	idx, _, err := prompt.Run()
+	if err != nil || idx == 0 {
+		return
+	}
+	for _, field := range hidden {
+		fmt.Printf("%s: %s\n", field.Name, field.Value)
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/otp"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+func init() {
+	infoInputs[storage.TOTP] = getTOTPSeedFromUser
+	infoViews[storage.TOTP] = showTOTPCodes
+}
+
+// getTOTPSeedFromUser imports the seed from otpauth:// URI or asks for its fields
+func getTOTPSeedFromUser() (storage.Info, error) {
+	return getParsedOrFields(storage.TOTP, "Enter otpauth:// URI or leave empty to enter fields", func(uri string) (storage.Info, error) {
+		key, err := otp.ParseURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		return &storage.InfoTOTP{
+			Secret:    key.Secret,
+			Algorithm: string(key.Params.Algorithm),
+			Digits:    key.Params.Digits,
+			Period:    key.Params.Period,
+			Issuer:    key.Issuer,
+			Account:   key.Account,
+		}, nil
+	})
+}
+
+// showTOTPCodes shows the current code with time left until it expires and refreshes it until Enter is pressed
+func showTOTPCodes(info storage.Info) {
+	seed, ok := info.(*storage.InfoTOTP)
+	if !ok {
+		return
+	}
+	p := seed.Params()
+	if _, err := otp.GenerateCode(seed.Secret, time.Now(), p); err != nil {
+		fmt.Println("Cant generate code:", err)
+		return
+	}
+	fmt.Println("Press Enter to stop")
+	stop := make(chan struct{})
+	go func() {
+		_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+		close(stop)
+	}()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		code, _ := otp.GenerateCode(seed.Secret, now, p)
+		fmt.Printf("\rCode: %s  expires in %2ds ", code, int(otp.Remaining(now, p).Seconds()))
+		select {
+		case <-stop:
+			fmt.Println()
+			return
+		case <-ticker.C:
+		}
+	}
+}
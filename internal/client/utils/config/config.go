@@ -10,6 +10,8 @@ import (
 
 type Config struct {
 	ServerAddr string `json:"address"`
+	// BreachedPasswords is directory of HIBP range files the passwords are checked against offline
+	BreachedPasswords string `json:"breached_passwords"`
 }
 
 const defaultAddress = "localhost:8080"
@@ -18,11 +20,13 @@ func SetClientParams() (cfg Config) {
 	var (
 		flagAddress    string
 		flagConfigFile string
+		flagBreached   string
 		cfgFile        string
 		exists         bool
 	)
 	flag.StringVar(&flagAddress, "a", defaultAddress, "server_address")
 	flag.StringVar(&flagConfigFile, "c", "", "config_as_json")
+	flag.StringVar(&flagBreached, "bp", "", "breached_passwords_dir")
 	flag.Parse()
 	if cfgFile, exists = os.LookupEnv("CONFIG"); !exists {
 		cfgFile = flagConfigFile
@@ -45,5 +49,10 @@ func SetClientParams() (cfg Config) {
 	if !exists {
 		cfg.ServerAddr = flagAddress
 	}
+	if envBreached, exists := os.LookupEnv("BREACHED_PASSWORDS"); exists {
+		cfg.BreachedPasswords = envBreached
+	} else if flagBreached != "" {
+		cfg.BreachedPasswords = flagBreached
+	}
 	return cfg
 }
@@ -0,0 +1,178 @@
+// Package dsn renders database credentials as DSNs of their drivers and parses DSNs back.
+package dsn
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+var ErrUnknownDriver = errors.New("error unknown database driver")
+
+// driver describes URL-style DSN of the driver
+type driver struct {
+	scheme string
+	// tlsParam is query parameter TLSMode is rendered to
+	tlsParam string
+	// tlsScheme replaces scheme if TLSMode is "true" for drivers which have no TLS parameter
+	tlsScheme string
+	// databaseParam is query parameter the database is rendered to, the database is URL path if it is empty
+	databaseParam string
+}
+
+var drivers = map[string]driver{
+	"postgres":  {scheme: "postgres", tlsParam: "sslmode"},
+	"sqlserver": {scheme: "sqlserver", tlsParam: "encrypt", databaseParam: "database"},
+	"mongodb":   {scheme: "mongodb", tlsParam: "tls"},
+	"redis":     {scheme: "redis", tlsScheme: "rediss"},
+}
+
+// schemes are URL schemes drivers are known by
+var schemes = map[string]string{
+	"postgres":   "postgres",
+	"postgresql": "postgres",
+	"sqlserver":  "sqlserver",
+	"mongodb":    "mongodb",
+	"redis":      "redis",
+	"rediss":     "redis",
+}
+
+// mysqlDSN matches DSN of go-sql-driver/mysql: user:password@tcp(host:port)/database?params
+var mysqlDSN = regexp.MustCompile(`^(?:([^:@]*)(?::(.*))?@)?tcp\(([^)]*)\)/([^?]*)(?:\?(.*))?$`)
+
+// Render returns DSN of the credential in the format of its driver
+func Render(info *storage.InfoDatabase) (string, error) {
+	query, err := url.ParseQuery(info.Params)
+	if err != nil {
+		return "", fmt.Errorf("error while parsing params: %w", err)
+	}
+	host := info.Host
+	if info.Port != 0 {
+		host = net.JoinHostPort(info.Host, strconv.Itoa(info.Port))
+	}
+	if info.Driver == "mysql" {
+		if info.TLSMode != "" {
+			query.Set("tls", info.TLSMode)
+		}
+		dsn := fmt.Sprintf("tcp(%s)/%s", host, info.Database)
+		if info.User != "" || info.Password != "" {
+			credentials := info.User
+			if info.Password != "" {
+				credentials += ":" + info.Password
+			}
+			dsn = credentials + "@" + dsn
+		}
+		if len(query) != 0 {
+			dsn += "?" + query.Encode()
+		}
+		return dsn, nil
+	}
+	d, ok := drivers[info.Driver]
+	if !ok {
+		return "", ErrUnknownDriver
+	}
+	u := url.URL{Scheme: d.scheme, Host: host}
+	if info.User != "" || info.Password != "" {
+		u.User = url.UserPassword(info.User, info.Password)
+		if info.Password == "" {
+			u.User = url.User(info.User)
+		}
+	}
+	switch {
+	case d.tlsScheme != "" && info.TLSMode == "true":
+		u.Scheme = d.tlsScheme
+	case d.tlsParam != "" && info.TLSMode != "":
+		query.Set(d.tlsParam, info.TLSMode)
+	}
+	if d.databaseParam != "" {
+		if info.Database != "" {
+			query.Set(d.databaseParam, info.Database)
+		}
+	} else if info.Database != "" {
+		u.Path = "/" + info.Database
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Parse returns credential from DSN of one of the known drivers
+func Parse(dsn string) (storage.InfoDatabase, error) {
+	dsn = strings.TrimSpace(dsn)
+	if match := mysqlDSN.FindStringSubmatch(dsn); match != nil {
+		return parseMySQL(match)
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return storage.InfoDatabase{}, fmt.Errorf("error while parsing dsn: %w", err)
+	}
+	name, ok := schemes[u.Scheme]
+	if !ok {
+		return storage.InfoDatabase{}, ErrUnknownDriver
+	}
+	d := drivers[name]
+	info := storage.InfoDatabase{Driver: name, Host: u.Hostname()}
+	if info.Port, err = parsePort(u.Port()); err != nil {
+		return storage.InfoDatabase{}, err
+	}
+	if u.User != nil {
+		info.User = u.User.Username()
+		info.Password, _ = u.User.Password()
+	}
+	query := u.Query()
+	if d.tlsParam != "" {
+		info.TLSMode = query.Get(d.tlsParam)
+		query.Del(d.tlsParam)
+	}
+	if d.tlsScheme != "" && u.Scheme == d.tlsScheme {
+		info.TLSMode = "true"
+	}
+	if d.databaseParam != "" {
+		info.Database = query.Get(d.databaseParam)
+		query.Del(d.databaseParam)
+	} else {
+		info.Database = strings.TrimPrefix(u.Path, "/")
+	}
+	info.Params = query.Encode()
+	return info, nil
+}
+
+func parseMySQL(match []string) (storage.InfoDatabase, error) {
+	info := storage.InfoDatabase{
+		Driver:   "mysql",
+		User:     match[1],
+		Password: match[2],
+		Host:     match[3],
+		Database: match[4],
+	}
+	if host, port, err := net.SplitHostPort(match[3]); err == nil {
+		info.Host = host
+		if info.Port, err = parsePort(port); err != nil {
+			return storage.InfoDatabase{}, err
+		}
+	}
+	query, err := url.ParseQuery(match[5])
+	if err != nil {
+		return storage.InfoDatabase{}, fmt.Errorf("error while parsing params: %w", err)
+	}
+	info.TLSMode = query.Get("tls")
+	query.Del("tls")
+	info.Params = query.Encode()
+	return info, nil
+}
+
+func parsePort(port string) (int, error) {
+	if port == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(port)
+	if err != nil || number <= 0 || number > 65535 {
+		return 0, fmt.Errorf("error port %q is invalid", port)
+	}
+	return number, nil
+}
@@ -0,0 +1,81 @@
+// Package export renders secrets in formats other tools read them from.
+package export
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+// AWSCredentials renders the key as a profile section of ~/.aws/credentials
+func AWSCredentials(profile string, key *storage.InfoAPIKey) string {
+	if profile == "" {
+		profile = "default"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", profile)
+	fmt.Fprintf(&b, "aws_access_key_id = %s\n", key.KeyID)
+	fmt.Fprintf(&b, "aws_secret_access_key = %s\n", key.Secret)
+	return b.String()
+}
+
+// Netrc renders the key as ~/.netrc entry, tools send the key ID as login and the secret as password
+func Netrc(key *storage.InfoAPIKey) string {
+	machine := key.Host
+	if machine == "" {
+		machine = key.Provider
+	}
+	login := key.KeyID
+	if login == "" {
+		login = key.Provider
+	}
+	return fmt.Sprintf("machine %s\n  login %s\n  password %s\n", netrcToken(machine), netrcToken(login), netrcToken(key.Secret))
+}
+
+// EnvVars renders the key as shell exports, AWS keys use the variables AWS tools read
+func EnvVars(key *storage.InfoAPIKey) string {
+	idName, secretName := "KEY_ID", "SECRET"
+	prefix := EnvName(key.Provider)
+	if prefix == "AWS" {
+		idName, secretName = "ACCESS_KEY_ID", "SECRET_ACCESS_KEY"
+	}
+	if prefix == "" {
+		prefix = "API"
+	}
+	var b strings.Builder
+	if key.KeyID != "" {
+		b.WriteString(ShellExport(prefix+"_"+idName, key.KeyID))
+	}
+	b.WriteString(ShellExport(prefix+"_"+secretName, key.Secret))
+	return b.String()
+}
+
+// EnvName turns name into environment variable name: upper case letters, digits and underscores
+func EnvName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// ShellExport renders export statement with the value quoted for POSIX shells
+func ShellExport(name, value string) string {
+	return fmt.Sprintf("export %s='%s'\n", name, strings.ReplaceAll(value, "'", `'"'"'`))
+}
+
+// netrcToken quotes tokens with spaces, quoting is understood by curl and Python's netrc
+func netrcToken(token string) string {
+	if token == "" || strings.ContainsAny(token, " \t\n\"\\") {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(token) + `"`
+	}
+	return token
+}
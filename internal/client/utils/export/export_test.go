@@ -0,0 +1,68 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSCredentials(t *testing.T) {
+	key := &storage.InfoAPIKey{Provider: "AWS", KeyID: "AKIAEXAMPLE", Secret: "wJalrXUtnFEMI"}
+	assert.Equal(t, "[ci]\naws_access_key_id = AKIAEXAMPLE\naws_secret_access_key = wJalrXUtnFEMI\n", AWSCredentials("ci", key))
+	assert.Contains(t, AWSCredentials("", key), "[default]\n")
+}
+
+func TestNetrc(t *testing.T) {
+	tests := []struct {
+		name string
+		key  *storage.InfoAPIKey
+		want string
+	}{
+		{
+			name: "host and key id",
+			key:  &storage.InfoAPIKey{Provider: "github", Host: "api.github.com", KeyID: "bot", Secret: "ghp_token"},
+			want: "machine api.github.com\n  login bot\n  password ghp_token\n",
+		},
+		{
+			name: "provider only",
+			key:  &storage.InfoAPIKey{Provider: "heroku", Secret: `se"cret key`},
+			want: "machine heroku\n  login heroku\n  password \"se\\\"cret key\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Netrc(tt.key))
+		})
+	}
+}
+
+func TestEnvVars(t *testing.T) {
+	tests := []struct {
+		name string
+		key  *storage.InfoAPIKey
+		want string
+	}{
+		{
+			name: "aws",
+			key:  &storage.InfoAPIKey{Provider: "aws", KeyID: "AKIAEXAMPLE", Secret: "wJalrXUtnFEMI"},
+			want: "export AWS_ACCESS_KEY_ID='AKIAEXAMPLE'\nexport AWS_SECRET_ACCESS_KEY='wJalrXUtnFEMI'\n",
+		},
+		{
+			name: "token with quote",
+			key:  &storage.InfoAPIKey{Provider: "my-service", Secret: "it's"},
+			want: "export MY_SERVICE_SECRET='it'\"'\"'s'\n",
+		},
+		{
+			name: "no provider",
+			key:  &storage.InfoAPIKey{Secret: "token"},
+			want: "export API_SECRET='token'\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EnvVars(tt.key))
+		})
+	}
+	assert.Equal(t, "_1PASSWORD", EnvName("1password"))
+}
@@ -0,0 +1,183 @@
+// Package generator generates random passwords and diceware passphrases with crypto/rand.
+package generator
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/wordlist"
+)
+
+const (
+	MinLength = 4
+	MaxLength = 128
+	MinWords  = 3
+	MaxWords  = 20
+)
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+	// ambiguousChars are easily confused when the password is read or typed by hand
+	ambiguousChars = "Il1O0o|"
+)
+
+var (
+	ErrNoClasses     = errors.New("error no character classes are chosen")
+	ErrLength        = fmt.Errorf("error length must be from %d to %d", MinLength, MaxLength)
+	ErrWords         = fmt.Errorf("error number of words must be from %d to %d", MinWords, MaxWords)
+	ErrLengthClasses = errors.New("error length is less than number of character classes")
+)
+
+// PasswordOptions are length and character classes of a password
+type PasswordOptions struct {
+	Length  int
+	Lower   bool
+	Upper   bool
+	Digits  bool
+	Symbols bool
+	// ExcludeAmbiguous drops characters like l, 1, O and 0
+	ExcludeAmbiguous bool
+}
+
+// DefaultPasswordOptions are used when the user doesn't change them
+var DefaultPasswordOptions = PasswordOptions{Length: 20, Lower: true, Upper: true, Digits: true, Symbols: true}
+
+// PassphraseOptions are number of words of a passphrase and how they are joined
+type PassphraseOptions struct {
+	Words     int
+	Separator string
+	// Capitalize makes the first letter of every word upper case
+	Capitalize bool
+	// Number appends a random digit to one of the words
+	Number bool
+}
+
+// DefaultPassphraseOptions are used when the user doesn't change them
+var DefaultPassphraseOptions = PassphraseOptions{Words: 6, Separator: "-"}
+
+func (o PasswordOptions) classes() []string {
+	var classes []string
+	for _, class := range []struct {
+		enabled bool
+		chars   string
+	}{{o.Lower, lowerChars}, {o.Upper, upperChars}, {o.Digits, digitChars}, {o.Symbols, symbolChars}} {
+		if !class.enabled {
+			continue
+		}
+		chars := class.chars
+		if o.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		classes = append(classes, chars)
+	}
+	return classes
+}
+
+// Entropy returns bits of entropy of passwords generated with the options
+func (o PasswordOptions) Entropy() float64 {
+	return float64(o.Length) * math.Log2(float64(len(strings.Join(o.classes(), ""))))
+}
+
+// Password generates password with at least one character of every chosen class
+func Password(opts PasswordOptions) (string, error) {
+	if opts.Length < MinLength || opts.Length > MaxLength {
+		return "", ErrLength
+	}
+	classes := opts.classes()
+	if len(classes) == 0 {
+		return "", ErrNoClasses
+	}
+	if opts.Length < len(classes) {
+		return "", ErrLengthClasses
+	}
+	all := strings.Join(classes, "")
+	password := make([]byte, 0, opts.Length)
+	for _, class := range classes {
+		c, err := randomChar(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < opts.Length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	// characters of the required classes are moved from the beginning to random places
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// Entropy returns bits of entropy of passphrases generated with the options
+func (o PassphraseOptions) Entropy() float64 {
+	bits := float64(o.Words) * math.Log2(float64(len(wordlist.Words())))
+	if o.Number {
+		bits += math.Log2(10 * float64(o.Words))
+	}
+	return bits
+}
+
+// Passphrase generates diceware passphrase of words of EFF's large wordlist
+func Passphrase(opts PassphraseOptions) (string, error) {
+	if opts.Words < MinWords || opts.Words > MaxWords {
+		return "", ErrWords
+	}
+	words, err := wordlist.Random(opts.Words)
+	if err != nil {
+		return "", err
+	}
+	if opts.Capitalize {
+		for i, word := range words {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	if opts.Number {
+		i, err := randomInt(len(words))
+		if err != nil {
+			return "", err
+		}
+		digit, err := randomChar(digitChars)
+		if err != nil {
+			return "", err
+		}
+		words[i] += string(digit)
+	}
+	return strings.Join(words, opts.Separator), nil
+}
+
+func randomChar(chars string) (byte, error) {
+	i, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+func randomInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("error while generating random number: %w", err)
+	}
+	return int(i.Int64()), nil
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/wordlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassword(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		password, err := Password(PasswordOptions{Length: 4, Lower: true, Upper: true, Digits: true, Symbols: true, ExcludeAmbiguous: true})
+		require.NoError(t, err)
+		require.Len(t, password, 4)
+		assert.True(t, strings.ContainsAny(password, lowerChars))
+		assert.True(t, strings.ContainsAny(password, upperChars))
+		assert.True(t, strings.ContainsAny(password, digitChars))
+		assert.True(t, strings.ContainsAny(password, symbolChars))
+		assert.False(t, strings.ContainsAny(password, ambiguousChars))
+	}
+
+	password, err := Password(PasswordOptions{Length: 32, Digits: true})
+	require.NoError(t, err)
+	assert.Len(t, strings.Trim(password, digitChars), 0)
+
+	_, err = Password(PasswordOptions{Length: 20})
+	assert.ErrorIs(t, err, ErrNoClasses)
+	_, err = Password(PasswordOptions{Length: 3, Lower: true})
+	assert.ErrorIs(t, err, ErrLength)
+
+	assert.InDelta(t, 20*6.5, DefaultPasswordOptions.Entropy(), 1)
+}
+
+func TestPassphrase(t *testing.T) {
+	passphrase, err := Passphrase(PassphraseOptions{Words: 6, Separator: " "})
+	require.NoError(t, err)
+	words := strings.Split(passphrase, " ")
+	require.Len(t, words, 6)
+	for _, word := range words {
+		assert.True(t, wordlist.Contains(word), word)
+	}
+
+	passphrase, err = Passphrase(PassphraseOptions{Words: 4, Separator: " ", Capitalize: true, Number: true})
+	require.NoError(t, err)
+	words = strings.Split(passphrase, " ")
+	require.Len(t, words, 4)
+	assert.True(t, strings.ContainsAny(passphrase, digitChars))
+	for _, word := range words {
+		assert.Equal(t, strings.ToUpper(word[:1]), word[:1])
+	}
+
+	_, err = Passphrase(PassphraseOptions{Words: 2})
+	assert.ErrorIs(t, err, ErrWords)
+
+	assert.InDelta(t, 6*12.9, DefaultPassphraseOptions.Entropy(), 0.5)
+}
@@ -0,0 +1,221 @@
+// Package health analyzes the user's secrets on the client, so the server never sees passwords being checked.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/breach"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+// DefaultMaxAgeDays is age of passwords which should be changed
+const DefaultMaxAgeDays = 365
+
+// Item is the user's decrypted secret
+type Item struct {
+	Name string
+	Type storage.InfoType
+	Info storage.Info
+}
+
+// Options tune the report
+type Options struct {
+	// MaxAgeDays is age of passwords reported as old
+	MaxAgeDays int
+	Now        time.Time
+	// Breaches are looked up for every password if they are set
+	Breaches *breach.Ranges
+}
+
+// Report has no passwords, only names of the secrets and what is wrong with them
+type Report struct {
+	GeneratedAt  time.Time     `json:"generated_at"`
+	MaxAgeDays   int           `json:"max_age_days"`
+	Summary      Summary       `json:"summary"`
+	Logins       []LoginReport `json:"logins"`
+	ExpiredCards []CardReport  `json:"expired_cards"`
+}
+
+// Summary counts problems of the vault
+type Summary struct {
+	Logins       int `json:"logins"`
+	Weak         int `json:"weak"`
+	Reused       int `json:"reused"`
+	Old          int `json:"old"`
+	Breached     int `json:"breached"`
+	ExpiredCards int `json:"expired_cards"`
+}
+
+// LoginReport is what is wrong with the login entry
+type LoginReport struct {
+	Name     string  `json:"name"`
+	Login    string  `json:"login"`
+	Score    Score   `json:"score"`
+	Strength string  `json:"strength"`
+	Entropy  float64 `json:"entropy_bits"`
+	// ReusedWith are names of the other entries with the same password
+	ReusedWith []string `json:"reused_with,omitempty"`
+	// AgeDays is days since the password was saved, it is -1 if it is unknown
+	AgeDays int  `json:"age_days"`
+	Old     bool `json:"old"`
+	// Breaches is how many times the password was seen in data breaches, it is -1 if it wasn't checked
+	Breaches int `json:"breaches"`
+}
+
+// Weak reports whether the password is weaker than Fair
+func (l LoginReport) Weak() bool {
+	return l.Score < Fair
+}
+
+// CardReport is expired card
+type CardReport struct {
+	Name      string `json:"name"`
+	Number    string `json:"number"`
+	ExpiredOn string `json:"expired_on"`
+}
+
+// Analyze checks strength, reuse, age and breaches of passwords and expiry of cards
+func Analyze(items []Item, opts Options) Report {
+	if opts.MaxAgeDays == 0 {
+		opts.MaxAgeDays = DefaultMaxAgeDays
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	report := Report{
+		GeneratedAt:  opts.Now,
+		MaxAgeDays:   opts.MaxAgeDays,
+		Logins:       []LoginReport{},
+		ExpiredCards: []CardReport{},
+	}
+	byPassword := make(map[string][]string)
+	for _, item := range items {
+		if login, ok := item.Info.(*storage.InfoLoginPass); ok {
+			byPassword[login.Password] = append(byPassword[login.Password], item.Name)
+		}
+	}
+	for _, item := range items {
+		switch info := item.Info.(type) {
+		case *storage.InfoLoginPass:
+			report.Logins = append(report.Logins, analyzeLogin(item.Name, info, byPassword[info.Password], opts))
+		case *storage.InfoCard:
+			expires, ok := info.ExpiresOn()
+			if ok && !expires.After(opts.Now) {
+				report.ExpiredCards = append(report.ExpiredCards, CardReport{
+					Name:      item.Name,
+					Number:    storage.MaskCardNumber(info.CardNumber),
+					ExpiredOn: expires.Format(storage.DateLayout),
+				})
+			}
+		}
+	}
+	sort.SliceStable(report.Logins, func(i, j int) bool { return report.Logins[i].Score < report.Logins[j].Score })
+	report.Summary = summarize(report)
+	return report
+}
+
+func analyzeLogin(name string, info *storage.InfoLoginPass, samePassword []string, opts Options) LoginReport {
+	score, bits := Strength(info.Password, info.Login)
+	login := LoginReport{
+		Name:     name,
+		Login:    info.Login,
+		Score:    score,
+		Strength: score.String(),
+		Entropy:  float64(int(bits*10)) / 10,
+		AgeDays:  -1,
+		Breaches: -1,
+	}
+	for _, other := range samePassword {
+		if other != name {
+			login.ReusedWith = append(login.ReusedWith, other)
+		}
+	}
+	if changed, ok := info.ChangedOn(); ok {
+		login.AgeDays = int(opts.Now.Sub(changed).Hours() / 24)
+		login.Old = login.AgeDays > opts.MaxAgeDays
+	}
+	if opts.Breaches != nil {
+		if count, err := opts.Breaches.Count(info.Password); err == nil {
+			login.Breaches = count
+		}
+	}
+	return login
+}
+
+func summarize(report Report) Summary {
+	summary := Summary{Logins: len(report.Logins), ExpiredCards: len(report.ExpiredCards)}
+	for _, login := range report.Logins {
+		if login.Weak() {
+			summary.Weak++
+		}
+		if len(login.ReusedWith) != 0 {
+			summary.Reused++
+		}
+		if login.Old {
+			summary.Old++
+		}
+		if login.Breaches > 0 {
+			summary.Breached++
+		}
+	}
+	return summary
+}
+
+// WriteJSON writes the report for tools of the security team
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report for the terminal, entries without problems are skipped
+func (r Report) WriteText(w io.Writer) error {
+	s := r.Summary
+	_, err := fmt.Fprintf(w, "Logins: %d, weak: %d, reused: %d, older than %d days: %d, breached: %d, expired cards: %d\n",
+		s.Logins, s.Weak, s.Reused, r.MaxAgeDays, s.Old, s.Breached, s.ExpiredCards)
+	if err != nil {
+		return err
+	}
+	for _, login := range r.Logins {
+		var problems []string
+		if login.Breaches > 0 {
+			problems = append(problems, fmt.Sprintf("password seen %d times in data breaches", login.Breaches))
+		}
+		if login.Weak() {
+			problems = append(problems, fmt.Sprintf("%s password (%.0f bits)", login.Strength, login.Entropy))
+		}
+		if len(login.ReusedWith) != 0 {
+			problems = append(problems, fmt.Sprintf("password reused in %v", login.ReusedWith))
+		}
+		if login.Old {
+			problems = append(problems, fmt.Sprintf("password is %d days old", login.AgeDays))
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		if _, err = fmt.Fprintf(w, "  %s (%s): ", login.Name, login.Login); err != nil {
+			return err
+		}
+		for i, problem := range problems {
+			if i > 0 {
+				problem = ", " + problem
+			}
+			if _, err = io.WriteString(w, problem); err != nil {
+				return err
+			}
+		}
+		if _, err = io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	for _, card := range r.ExpiredCards {
+		if _, err = fmt.Fprintf(w, "  %s (%s): card expired on %s\n", card.Name, card.Number, card.ExpiredOn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
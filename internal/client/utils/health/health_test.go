@@ -0,0 +1,95 @@
+package health
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/breach"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		hints    []string
+		want     Score
+	}{
+		{name: "empty", password: "", want: VeryWeak},
+		{name: "sequence", password: "abcdefgh12345678", want: VeryWeak},
+		{name: "repeats", password: "aaaaaaaaaaaaaaaa", want: VeryWeak},
+		{name: "short", password: "Tr0ub", want: Weak},
+		{name: "mixed", password: "Xq7#mP2v!zR9", want: Strong},
+		{name: "random", password: "Xq7#mP2v!zR9wL4$kT8n", want: VeryStrong},
+		{name: "contains login", password: "arseniy2023", hints: []string{"arseniy"}, want: VeryWeak},
+		{name: "passphrase", password: "correct-battery-staple-mango-pebble", want: Strong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, _ := Strength(tt.password, tt.hints...)
+			assert.Equal(t, tt.want, score, score.String())
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+	oldLogin := &storage.InfoLoginPass{Login: "old", Password: "Xq7#mP2v!zR9wL4$kT8n"}
+	oldLogin.Touch(now.AddDate(-2, 0, 0))
+	items := []Item{
+		{Name: "mail", Type: storage.LoginPassword, Info: &storage.InfoLoginPass{Login: "me", Password: "qwerty"}},
+		{Name: "shop", Type: storage.LoginPassword, Info: &storage.InfoLoginPass{Login: "me", Password: "qwerty"}},
+		{Name: "bank", Type: storage.LoginPassword, Info: oldLogin},
+		{Name: "visa", Type: storage.Card, Info: &storage.InfoCard{CardNumber: "4111111111111111", Date: "01/24"}},
+		{Name: "mir", Type: storage.Card, Info: &storage.InfoCard{CardNumber: "2200000000000004", Date: "12/30"}},
+	}
+
+	dir := t.TempDir()
+	prefix, suffix := breach.Hash("qwerty")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, prefix), []byte(suffix+":42\n"), 0600))
+	ranges, err := breach.Open(dir)
+	require.NoError(t, err)
+
+	report := Analyze(items, Options{MaxAgeDays: 365, Now: now, Breaches: ranges})
+	assert.Equal(t, Summary{Logins: 3, Weak: 2, Reused: 2, Old: 1, Breached: 2, ExpiredCards: 1}, report.Summary)
+	require.Len(t, report.Logins, 3)
+	assert.Equal(t, []string{"shop"}, report.Logins[0].ReusedWith)
+	assert.Equal(t, -1, report.Logins[0].AgeDays)
+	assert.Equal(t, 42, report.Logins[0].Breaches)
+	assert.Equal(t, -1, report.Logins[2].Breaches)
+	assert.Equal(t, "bank", report.Logins[2].Name)
+	assert.Equal(t, 731, report.Logins[2].AgeDays)
+	assert.True(t, report.Logins[2].Old)
+	require.Len(t, report.ExpiredCards, 1)
+	assert.Equal(t, CardReport{Name: "visa", Number: storage.MaskCardNumber("4111111111111111"), ExpiredOn: "2024-02-01"}, report.ExpiredCards[0])
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "mail (me): password seen 42 times in data breaches, weak password")
+	assert.Contains(t, text.String(), "password reused in [shop]")
+	assert.Contains(t, text.String(), "bank (old): password is 731 days old")
+	assert.Contains(t, text.String(), "visa (")
+
+	var js bytes.Buffer
+	require.NoError(t, report.WriteJSON(&js))
+	assert.NotContains(t, js.String(), "qwerty")
+	assert.NotContains(t, js.String(), "Xq7#mP2v")
+	assert.Contains(t, js.String(), `"reused_with": [`)
+}
+
+func TestAnalyzeCardLastDay(t *testing.T) {
+	items := []Item{{Name: "visa", Type: storage.Card, Info: &storage.InfoCard{CardNumber: "4111111111111111", Date: "02/24"}}}
+
+	lastDay := time.Date(2024, time.February, 29, 23, 59, 0, 0, time.UTC)
+	report := Analyze(items, Options{Now: lastDay})
+	assert.Empty(t, report.ExpiredCards)
+
+	report = Analyze(items, Options{Now: lastDay.Add(time.Minute)})
+	require.Len(t, report.ExpiredCards, 1)
+	assert.Equal(t, "2024-03-01", report.ExpiredCards[0].ExpiredOn)
+}
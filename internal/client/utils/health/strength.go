@@ -0,0 +1,127 @@
+package health
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/wordlist"
+)
+
+const (
+	VeryWeak Score = iota
+	Weak
+	Fair
+	Strong
+	VeryStrong
+)
+
+// Score is strength of a password from VeryWeak to VeryStrong
+type Score int
+
+func (s Score) String() string {
+	switch s {
+	case VeryWeak:
+		return "very weak"
+	case Weak:
+		return "weak"
+	case Fair:
+		return "fair"
+	case Strong:
+		return "strong"
+	}
+	return "very strong"
+}
+
+// scoreBits are the least bits of entropy of passwords of the scores above VeryWeak
+var scoreBits = []float64{28, 36, 60, 80}
+
+// Strength estimates entropy of the password and scores it, hints like the login make the password weaker if it contains them
+func Strength(password string, hints ...string) (Score, float64) {
+	bits := entropy(password)
+	lower := strings.ToLower(password)
+	for _, hint := range hints {
+		if len(hint) >= 3 && strings.Contains(lower, strings.ToLower(hint)) {
+			bits -= float64(len(hint)) * math.Log2(poolSize(hint))
+		}
+	}
+	if bits < 0 {
+		bits = 0
+	}
+	score := VeryWeak
+	for _, min := range scoreBits {
+		if bits >= min {
+			score++
+		}
+	}
+	return score, bits
+}
+
+// entropy is length of the password without repeats and sequences times bits of its character pool,
+// passphrases of the wordlist are counted by words
+func entropy(password string) float64 {
+	if bits, ok := passphraseEntropy(password); ok {
+		return bits
+	}
+	return float64(effectiveLength(password)) * math.Log2(poolSize(password))
+}
+
+func passphraseEntropy(password string) (float64, bool) {
+	words := strings.FieldsFunc(strings.ToLower(password), func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(words) < 3 {
+		return 0, false
+	}
+	for _, word := range words {
+		if !wordlist.Contains(word) {
+			return 0, false
+		}
+	}
+	return float64(len(words)) * math.Log2(float64(len(wordlist.Words()))), true
+}
+
+// effectiveLength doesn't count characters which repeat or continue sequence of the previous ones, e.g. "aaaa" or "1234"
+func effectiveLength(password string) int {
+	runes := []rune(password)
+	length := 0
+	for i, r := range runes {
+		if i > 0 {
+			diff := r - runes[i-1]
+			if diff == 0 || ((diff == 1 || diff == -1) && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+				continue
+			}
+		}
+		length++
+	}
+	return length
+}
+
+func poolSize(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 1
+	}
+	return float64(pool)
+}
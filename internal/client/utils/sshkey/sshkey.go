@@ -0,0 +1,167 @@
+// Package sshkey generates and imports SSH keys stored in the vault and decrypts them for the agent.
+package sshkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	openSSHMagic     = "openssh-key-v1\x00"
+	openSSHBlockSize = 8
+)
+
+var (
+	ErrPassphraseRequired = errors.New("error private key is encrypted, passphrase is required")
+	ErrWrongPassphrase    = errors.New("error wrong passphrase or corrupted private key")
+)
+
+// openSSHKey is unencrypted private section of OpenSSH key file with ed25519 key
+type openSSHKey struct {
+	Check1  uint32
+	Check2  uint32
+	KeyType string
+	Public  []byte
+	Private []byte
+	Comment string
+	Pad     []byte `ssh:"rest"`
+}
+
+type openSSHEnvelope struct {
+	CipherName   string
+	KdfName      string
+	KdfOpts      string
+	NumKeys      uint32
+	PublicKey    []byte
+	PrivateBlock []byte
+}
+
+// Generate creates ed25519 key pair, the private key is in OpenSSH format, so it can be exported for ssh as is
+func Generate(comment string) (storage.InfoSSHKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return storage.InfoSSHKey{}, fmt.Errorf("error while generating ssh key: %w", err)
+	}
+	publicKey, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return storage.InfoSSHKey{}, fmt.Errorf("error while making ssh public key: %w", err)
+	}
+	privateKey, err := marshalOpenSSH(public, private, comment)
+	if err != nil {
+		return storage.InfoSSHKey{}, err
+	}
+	return storage.InfoSSHKey{
+		PrivateKey: privateKey,
+		PublicKey:  authorizedKey(publicKey, comment),
+		Comment:    comment,
+	}, nil
+}
+
+// Import checks the private key and derives its public key, encrypted keys are kept encrypted with the passphrase
+func Import(privateKey []byte, passphrase string, comment string) (storage.InfoSSHKey, error) {
+	info := storage.InfoSSHKey{
+		PrivateKey: string(privateKey),
+		Passphrase: passphrase,
+		Comment:    comment,
+	}
+	signer, err := Signer(&info)
+	if err != nil {
+		return storage.InfoSSHKey{}, err
+	}
+	info.PublicKey = authorizedKey(signer.PublicKey(), comment)
+	return info, nil
+}
+
+// ParsePublicKey returns comment of the key in authorized_keys format, it is used to take comment from .pub file
+func ParsePublicKey(publicKey []byte) (string, error) {
+	_, comment, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("error while parsing public key: %w", err)
+	}
+	return comment, nil
+}
+
+// RawKey decrypts the private key with the stored passphrase
+func RawKey(info *storage.InfoSSHKey) (any, error) {
+	key, err := ssh.ParseRawPrivateKey([]byte(info.PrivateKey))
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing private key: %w", err)
+		}
+		return key, nil
+	}
+	if info.Passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+	key, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(info.PrivateKey), []byte(info.Passphrase))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+func Signer(info *storage.InfoSSHKey) (ssh.Signer, error) {
+	key, err := RawKey(info)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("error while making signer: %w", err)
+	}
+	return signer, nil
+}
+
+func authorizedKey(publicKey ssh.PublicKey, comment string) string {
+	key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+	if comment != "" {
+		key += " " + comment
+	}
+	return key
+}
+
+// marshalOpenSSH encodes unencrypted ed25519 key the way ssh-keygen does
+func marshalOpenSSH(public ed25519.PublicKey, private ed25519.PrivateKey, comment string) (string, error) {
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return "", fmt.Errorf("error while generating check bytes: %w", err)
+	}
+	key := openSSHKey{
+		Check1:  binary.BigEndian.Uint32(check[:]),
+		Check2:  binary.BigEndian.Uint32(check[:]),
+		KeyType: ssh.KeyAlgoED25519,
+		Public:  public,
+		Private: private,
+		Comment: comment,
+	}
+	// padding bytes are 1, 2, 3... up to the cipher's block size, which is 8 for "none"
+	unpadded := len(ssh.Marshal(key))
+	for i := 0; (unpadded+i)%openSSHBlockSize != 0; i++ {
+		key.Pad = append(key.Pad, byte(i+1))
+	}
+	publicKey, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return "", fmt.Errorf("error while making ssh public key: %w", err)
+	}
+	envelope := openSSHEnvelope{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PublicKey:    publicKey.Marshal(),
+		PrivateBlock: ssh.Marshal(key),
+	}
+	block := &pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte(openSSHMagic), ssh.Marshal(envelope)...),
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
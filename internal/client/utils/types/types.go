@@ -3,25 +3,176 @@ package types
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
 )
 
-var ErrExitCLI = errors.New("Exit")
+var (
+	ErrExitCLI         = errors.New("Exit")
+	ErrTooManyAttempts = errors.New("too many failed attempts")
+)
 
 type ClientAction interface {
 	SaveData(ctx context.Context, req storage.Info, infoType storage.InfoType, infoName string) error
 	GetData(ctx context.Context, req GetRequest) (storage.Info, error)
+	UpdateData(ctx context.Context, req storage.Info, infoType storage.InfoType, infoName string) error
+	DeleteData(ctx context.Context, req GetRequest) error
+	// ListData returns the user's own secrets of the type, all of them if the type is empty
+	ListData(ctx context.Context, infoType storage.InfoType) ([]storage.InfoMeta, error)
+	// MatchLogins returns the user's logins with URIs matching the URL
+	MatchLogins(ctx context.Context, url string) ([]MatchedLogin, error)
+	SaveDataAt(ctx context.Context, req storage.Info, meta GetRequest) error
+	UpdateDataAt(ctx context.Context, req storage.Info, meta GetRequest) error
+	ShareData(ctx context.Context, req ShareRequest) error
+	RevokeShare(ctx context.Context, req ShareRequest) error
+	GetShares(ctx context.Context, req GetRequest) ([]storage.Share, error)
+	GetSharedWithMe(ctx context.Context) ([]storage.SharedInfo, error)
+	ShareSealed(ctx context.Context, share storage.SealedShare) error
+	GetSealedShare(ctx context.Context, req GetRequest) (storage.SealedShare, error)
+	CreateLink(ctx context.Context, req LinkRequest) (LinkResponse, error)
+	OpenLink(ctx context.Context, token string) (storage.Link, error)
+	GetEmergencyContacts(ctx context.Context) ([]emergency.Contact, error)
+	AddEmergencyContact(ctx context.Context, login string, waitHours int) error
+	RemoveEmergencyContact(ctx context.Context, login string) error
+	RejectEmergencyAccess(ctx context.Context, login string) error
+	GetEmergencyGrantors(ctx context.Context) ([]emergency.Contact, error)
+	RequestEmergencyAccess(ctx context.Context, owner string) (emergency.Contact, error)
+	GetEmergencyVault(ctx context.Context, owner string) ([]storage.InfoMeta, error)
+	GetEmergencyData(ctx context.Context, req GetRequest) (storage.Info, error)
+	GetAudit(ctx context.Context, beforeID int64, limit int) ([]audit.Entry, error)
+	// GetDue returns the user's secrets which are overdue or due within days
+	GetDue(ctx context.Context, days int) ([]storage.DueInfo, error)
+	GetTemplates(ctx context.Context) ([]storage.Template, error)
+	SaveTemplate(ctx context.Context, template storage.Template) error
+	DeleteTemplate(ctx context.Context, name string) error
+	CreateOrg(ctx context.Context, org string) error
+	DeleteOrg(ctx context.Context, org string) error
+	GetOrgs(ctx context.Context) ([]orgs.Membership, error)
+	GetMembers(ctx context.Context, org string) ([]orgs.Member, error)
+	InviteMember(ctx context.Context, req OrgRequest) error
+	SetMemberRole(ctx context.Context, req OrgRequest) error
+	RemoveMember(ctx context.Context, req OrgRequest) error
+	GetInvitations(ctx context.Context) ([]orgs.Invitation, error)
+	AcceptInvitation(ctx context.Context, org string) error
+	DeclineInvitation(ctx context.Context, org string) error
+	CreateCollection(ctx context.Context, req OrgRequest) error
+	DeleteCollection(ctx context.Context, req OrgRequest) error
+	GetCollections(ctx context.Context, org string) ([]string, error)
+	GetCollectionData(ctx context.Context, org string, collection string) ([]storage.InfoMeta, error)
 	Register(ctx context.Context, req AuthRequest) error
-	Login(ctx context.Context, req AuthRequest) error
+	Login(ctx context.Context, req AuthRequest) (string, error)
+	LoginTOTP(ctx context.Context, req TOTPLoginRequest) error
+	EnrollTOTP(ctx context.Context) (TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, code string) error
+	ChangePassword(ctx context.Context, req ChangePasswordRequest) error
+	GetVaultKey(ctx context.Context) ([]byte, error)
+	SetVaultKey(ctx context.Context, vaultKey []byte) error
+	GetKeyPair(ctx context.Context) (KeyPairData, error)
+	SetKeyPair(ctx context.Context, keyPair KeyPairData) error
+	GetPublicKey(ctx context.Context, login string) ([]byte, error)
+	DeleteAccount(ctx context.Context, password string) error
+	SetRecoveryKey(ctx context.Context, recovery RecoveryKeyData) error
+	GetRecoveryVaultKey(ctx context.Context, req RecoveryRequest) ([]byte, error)
+	ResetPassword(ctx context.Context, req RecoveryRequest) error
+}
+
+// MatchedLogin is the user's login entry used on the URL
+type MatchedLogin struct {
+	Name string `json:"name"`
+	storage.InfoLoginPass
 }
 
 type GetRequest struct {
-	Name string           `json:"name"`
-	Type storage.InfoType `json:"type"`
+	Name       string           `json:"name"`
+	Type       storage.InfoType `json:"type"`
+	Owner      string           `json:"owner,omitempty"`
+	Org        string           `json:"org,omitempty"`
+	Collection string           `json:"collection,omitempty"`
+}
+
+type OrgRequest struct {
+	Org   string    `json:"org"`
+	Name  string    `json:"name,omitempty"`
+	Login string    `json:"login,omitempty"`
+	Role  orgs.Role `json:"role,omitempty"`
+}
+
+type ShareRequest struct {
+	Name      string           `json:"name"`
+	Type      storage.InfoType `json:"type"`
+	Recipient string           `json:"recipient"`
+	Access    storage.Access   `json:"access,omitempty"`
+}
+
+// LinkRequest asks for one-time link to the secret encrypted with the link's key
+type LinkRequest struct {
+	Name      string           `json:"name"`
+	Type      storage.InfoType `json:"type"`
+	Data      []byte           `json:"data"`
+	MaxViews  int              `json:"max_views,omitempty"`
+	ExpiresIn int64            `json:"expires_in,omitempty"`
+}
+
+type LinkResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	MaxViews  int       `json:"max_views"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type EmergencyRequest struct {
+	Login     string `json:"login,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+	WaitHours int    `json:"wait_hours,omitempty"`
 }
 
 type AuthRequest struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
+	Login    string           `json:"login"`
+	Password string           `json:"password"`
+	VaultKey []byte           `json:"vault_key,omitempty"`
+	Recovery *RecoveryKeyData `json:"recovery,omitempty"`
+}
+
+// RecoveryKeyData is vault key wrapped with the recovery key and verifier proving knowledge of the recovery key
+type RecoveryKeyData struct {
+	VaultKey []byte `json:"vault_key"`
+	Verifier string `json:"verifier"`
+}
+
+type RecoveryRequest struct {
+	Login       string `json:"login"`
+	Verifier    string `json:"verifier"`
+	NewPassword string `json:"new_password,omitempty"`
+	VaultKey    []byte `json:"vault_key,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+	VaultKey    []byte `json:"vault_key,omitempty"`
+}
+
+type VaultKeyData struct {
+	VaultKey []byte `json:"vault_key"`
+}
+
+// KeyPairData is user's key pair, private key is encrypted with the user's vault key
+type KeyPairData struct {
+	PublicKey  []byte `json:"public_key"`
+	PrivateKey []byte `json:"private_key,omitempty"`
+}
+
+type TOTPLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TOTPEnrollment struct {
+	URI         string   `json:"uri"`
+	Secret      string   `json:"secret"`
+	BackupCodes []string `json:"backup_codes"`
 }
@@ -0,0 +1,184 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/client/utils/wordlist"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const (
+	KeySize = 32
+	// KeyPairSize is size of public and private keys used to seal secrets for other users
+	KeyPairSize = 32
+	// RecoveryKeyWords is number of words in recovery key, 10 words of EFF's wordlist give about 129 bits of entropy
+	RecoveryKeyWords = 10
+
+	wrapVersion  = 1
+	saltSize     = 16
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+)
+
+var (
+	ErrWrongKey         = errors.New("error wrong password or corrupted vault key")
+	ErrWrongRecoveryKey = errors.New("error recovery key is malformed")
+)
+
+// recoveryVerifierPrefix separates verifier from the key wrapping the vault key, both are derived from the recovery key
+const recoveryVerifierPrefix = "gophkeeper recovery verifier\x00"
+
+// NewKey generates random vault key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error while generating vault key: %w", err)
+	}
+	return key, nil
+}
+
+// DeriveKey derives key encryption key from the password with argon2id
+func DeriveKey(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, KeySize)
+}
+
+// Wrap encrypts vault key with key derived from the password
+func Wrap(vaultKey []byte, password string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("error while generating salt: %w", err)
+	}
+	sealed, err := Seal(vaultKey, DeriveKey(password, salt))
+	if err != nil {
+		return nil, err
+	}
+	wrapped := append([]byte{wrapVersion}, salt...)
+	return append(wrapped, sealed...), nil
+}
+
+// Unwrap decrypts vault key wrapped by Wrap
+func Unwrap(wrapped []byte, password string) ([]byte, error) {
+	if len(wrapped) < 1+saltSize || wrapped[0] != wrapVersion {
+		return nil, ErrWrongKey
+	}
+	salt := wrapped[1 : 1+saltSize]
+	return Open(wrapped[1+saltSize:], DeriveKey(password, salt))
+}
+
+// Seal encrypts data with AES-GCM, random nonce is prepended to the result
+func Seal(data, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error while generating nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Open decrypts data encrypted by Seal
+func Open(sealed, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	nonce, cipherText := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	data, err := gcm.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return data, nil
+}
+
+// NewRecoveryKey generates high-entropy recovery key as words separated with spaces
+func NewRecoveryKey() (string, error) {
+	words, err := wordlist.Random(RecoveryKeyWords)
+	if err != nil {
+		return "", fmt.Errorf("error while generating recovery key: %w", err)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// NormalizeRecoveryKey checks words of the recovery key typed by the user and joins them the way NewRecoveryKey does
+func NormalizeRecoveryKey(key string) (string, error) {
+	words := strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == ','
+	})
+	if len(words) != RecoveryKeyWords {
+		return "", ErrWrongRecoveryKey
+	}
+	for _, word := range words {
+		if !wordlist.Contains(word) {
+			return "", ErrWrongRecoveryKey
+		}
+	}
+	return strings.Join(words, " "), nil
+}
+
+// RecoveryVerifier derives value which proves knowledge of the recovery key to the server,
+// the server can't unwrap the vault key with it
+func RecoveryVerifier(recoveryKey string) string {
+	sum := sha256.Sum256([]byte(recoveryVerifierPrefix + recoveryKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewKeyPair generates curve25519 key pair for sharing secrets
+func NewKeyPair() (publicKey []byte, privateKey []byte, err error) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while generating key pair: %w", err)
+	}
+	return public[:], private[:], nil
+}
+
+// SealFor encrypts data so that only the owner of the public key can decrypt it
+func SealFor(data, publicKey []byte) ([]byte, error) {
+	if len(publicKey) != KeyPairSize {
+		return nil, ErrWrongKey
+	}
+	var public [KeyPairSize]byte
+	copy(public[:], publicKey)
+	sealed, err := box.SealAnonymous(nil, data, &public, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error while sealing data: %w", err)
+	}
+	return sealed, nil
+}
+
+// OpenSealed decrypts data encrypted by SealFor with the recipient's key pair
+func OpenSealed(sealed, publicKey, privateKey []byte) ([]byte, error) {
+	if len(publicKey) != KeyPairSize || len(privateKey) != KeyPairSize {
+		return nil, ErrWrongKey
+	}
+	var public, private [KeyPairSize]byte
+	copy(public[:], publicKey)
+	copy(private[:], privateKey)
+	data, ok := box.OpenAnonymous(nil, sealed, &public, &private)
+	if !ok {
+		return nil, ErrWrongKey
+	}
+	return data, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,174 @@
+package vault
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func flipLast(b []byte) []byte {
+	tampered := bytes.Clone(b)
+	tampered[len(tampered)-1] ^= 0xff
+	return tampered
+}
+
+func TestWrap(t *testing.T) {
+	vaultKey, err := NewKey()
+	require.NoError(t, err)
+	wrapped, err := Wrap(vaultKey, "password")
+	require.NoError(t, err)
+
+	unknownVersion := bytes.Clone(wrapped)
+	unknownVersion[0] = wrapVersion + 1
+
+	tests := []struct {
+		name     string
+		wrapped  []byte
+		password string
+		wantErr  error
+	}{
+		{name: "round trip", wrapped: wrapped, password: "password"},
+		{name: "wrong password", wrapped: wrapped, password: "wrong_password", wantErr: ErrWrongKey},
+		{name: "tampered ciphertext", wrapped: flipLast(wrapped), password: "password", wantErr: ErrWrongKey},
+		{name: "unknown version", wrapped: unknownVersion, password: "password", wantErr: ErrWrongKey},
+		{name: "truncated", wrapped: wrapped[:saltSize], password: "password", wantErr: ErrWrongKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unwrap(tt.wrapped, tt.password)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, vaultKey, got)
+		})
+	}
+}
+
+func TestSeal(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+	otherKey, err := NewKey()
+	require.NoError(t, err)
+	data := []byte("some secret data")
+	sealed, err := Seal(data, key)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		sealed  []byte
+		key     []byte
+		wantErr error
+	}{
+		{name: "round trip", sealed: sealed, key: key},
+		{name: "wrong key", sealed: sealed, key: otherKey, wantErr: ErrWrongKey},
+		{name: "tampered ciphertext", sealed: flipLast(sealed), key: key, wantErr: ErrWrongKey},
+		{name: "shorter than nonce", sealed: sealed[:4], key: key, wantErr: ErrWrongKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(tt.sealed, tt.key)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+}
+
+func TestSealFor(t *testing.T) {
+	public, private, err := NewKeyPair()
+	require.NoError(t, err)
+	otherPublic, otherPrivate, err := NewKeyPair()
+	require.NoError(t, err)
+	data := []byte("shared secret data")
+	sealed, err := SealFor(data, public)
+	require.NoError(t, err)
+
+	_, err = SealFor(data, public[:KeyPairSize-1])
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	tests := []struct {
+		name    string
+		sealed  []byte
+		public  []byte
+		private []byte
+		wantErr error
+	}{
+		{name: "round trip", sealed: sealed, public: public, private: private},
+		{name: "wrong key pair", sealed: sealed, public: otherPublic, private: otherPrivate, wantErr: ErrWrongKey},
+		{name: "tampered ciphertext", sealed: flipLast(sealed), public: public, private: private, wantErr: ErrWrongKey},
+		{name: "short private key", sealed: sealed, public: public, private: private[1:], wantErr: ErrWrongKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OpenSealed(tt.sealed, tt.public, tt.private)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+}
+
+func TestNormalizeRecoveryKey(t *testing.T) {
+	key, err := NewRecoveryKey()
+	require.NoError(t, err)
+	words := strings.Fields(key)
+	require.Len(t, words, RecoveryKeyWords)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{name: "as generated", key: key},
+		{name: "upper case", key: strings.ToUpper(key)},
+		{name: "hyphens", key: strings.Join(words, "-")},
+		{name: "commas and extra spaces", key: "  " + strings.Join(words, ",  ") + "\n"},
+		{name: "too few words", key: strings.Join(words[1:], " "), wantErr: ErrWrongRecoveryKey},
+		{name: "too many words", key: key + " " + words[0], wantErr: ErrWrongRecoveryKey},
+		{name: "unknown word", key: strings.Join(append([]string{"gophkeeper"}, words[1:]...), " "), wantErr: ErrWrongRecoveryKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRecoveryKey(tt.key)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, key, got)
+		})
+	}
+}
+
+func TestRecoveryVerifier(t *testing.T) {
+	key, err := NewRecoveryKey()
+	require.NoError(t, err)
+	otherKey, err := NewRecoveryKey()
+	require.NoError(t, err)
+	normalized, err := NormalizeRecoveryKey(strings.ToUpper(key))
+	require.NoError(t, err)
+
+	verifier := RecoveryVerifier(key)
+	assert.Len(t, verifier, 64)
+	assert.Equal(t, verifier, RecoveryVerifier(normalized))
+	assert.NotEqual(t, verifier, RecoveryVerifier(otherKey))
+
+	// the verifier must not be usable as the password wrapping the vault key
+	vaultKey, err := NewKey()
+	require.NoError(t, err)
+	wrapped, err := Wrap(vaultKey, key)
+	require.NoError(t, err)
+	_, err = Unwrap(wrapped, verifier)
+	assert.ErrorIs(t, err, ErrWrongKey)
+}
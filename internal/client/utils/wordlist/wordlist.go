@@ -0,0 +1,65 @@
+// Package wordlist provides EFF's large wordlist for diceware passphrases and recovery keys.
+//
+// The list is https://www.eff.org/files/2016/07/18/eff_large_wordlist.txt, every word is chosen
+// with equal probability and gives log2(7776) ≈ 12.9 bits of entropy.
+package wordlist
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+//go:embed eff_large_wordlist.txt
+var effLarge string
+
+var (
+	parseOnce sync.Once
+	list      []string
+)
+
+func words() []string {
+	parseOnce.Do(func() {
+		lines := strings.Split(strings.TrimSpace(effLarge), "\n")
+		list = make([]string, 0, len(lines))
+		for _, line := range lines {
+			// lines are "<dice rolls>\t<word>"
+			_, word, _ := strings.Cut(line, "\t")
+			list = append(list, strings.TrimSpace(word))
+		}
+	})
+	return list
+}
+
+// Words returns all words of the list
+func Words() []string {
+	return words()
+}
+
+// Contains reports whether the word is in the list
+func Contains(word string) bool {
+	for _, w := range words() {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Random returns n words chosen with crypto/rand
+func Random(n int) ([]string, error) {
+	all := words()
+	max := big.NewInt(int64(len(all)))
+	chosen := make([]string, n)
+	for i := range chosen {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, fmt.Errorf("error while choosing random word: %w", err)
+		}
+		chosen[i] = all[idx.Int64()]
+	}
+	return chosen, nil
+}
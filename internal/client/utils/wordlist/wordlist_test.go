@@ -0,0 +1,31 @@
+package wordlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWords(t *testing.T) {
+	list := Words()
+	require.Len(t, list, 6*6*6*6*6)
+	assert.Equal(t, "abacus", list[0])
+	assert.Equal(t, "zoom", list[len(list)-1])
+	seen := make(map[string]bool, len(list))
+	for _, w := range list {
+		assert.NotEmpty(t, w)
+		assert.False(t, seen[w], "duplicate word %q", w)
+		seen[w] = true
+	}
+}
+
+func TestRandom(t *testing.T) {
+	chosen, err := Random(8)
+	require.NoError(t, err)
+	require.Len(t, chosen, 8)
+	for _, w := range chosen {
+		assert.True(t, Contains(w))
+	}
+	assert.False(t, Contains("gophkeeper"))
+}
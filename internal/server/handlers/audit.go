@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/bruteforce"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+type auditPage struct {
+	Entries []audit.Entry `json:"entries"`
+}
+
+// GetAuditHandler returns page of the user's activity from the newest entries, older pages are requested with ?before=<id>
+func (s *Server) GetAuditHandler(c echo.Context) error {
+	var (
+		beforeID int64
+		limit    int
+		err      error
+	)
+	if before := c.QueryParam("before"); before != "" {
+		beforeID, err = strconv.ParseInt(before, 10, 64)
+		if err != nil {
+			http.Error(c.Response().Writer, "wrong before param", http.StatusBadRequest)
+			return nil
+		}
+	}
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			http.Error(c.Response().Writer, "wrong limit param", http.StatusBadRequest)
+			return nil
+		}
+	}
+	page := auditPage{Entries: []audit.Entry{}}
+	if s.Audit == nil {
+		return writeJSON(c, http.StatusOK, page)
+	}
+	entries, err := s.Audit.Get(s.Auth.GetUserLogin(c.Request()), beforeID, limit)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get audit log", http.StatusInternalServerError)
+		log.Println("error while getting audit log:", err)
+		return nil
+	}
+	if entries != nil {
+		page.Entries = entries
+	}
+	return writeJSON(c, http.StatusOK, page)
+}
+
+func newAuditEntry(r *http.Request, login string, action audit.Action, success bool) audit.Entry {
+	return audit.Entry{
+		Login:     login,
+		Action:    action,
+		Success:   success,
+		IP:        bruteforce.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func (s *Server) recordDataAudit(r *http.Request, action audit.Action, meta storage.InfoMeta, success bool) {
+	if meta.Login == "" && s.Auth != nil {
+		meta.Login = s.Auth.GetUserLogin(r)
+	}
+	entry := newAuditEntry(r, meta.Login, action, success)
+	entry.SecretType = string(meta.Type)
+	entry.SecretName = meta.Name
+	if meta.InCollection() {
+		entry.SecretName = meta.Org + "/" + meta.Collection + "/" + meta.Name
+	}
+	if meta.Shared() && success {
+		// access to a shared secret goes to the owner's log, the owner comes from the request,
+		// so it is trusted only after storage found the share
+		entry.Login = meta.Owner
+		entry.Actor = meta.Login
+	} else if meta.Shared() {
+		entry.Target = meta.Owner
+	}
+	s.Audit.Record(entry)
+}
+
+// peekBody reads request body and puts it back for the handler
+func peekBody(r *http.Request) []byte {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Println("error while reading request body:", err)
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+func peekLogin(r *http.Request) string {
+	var userData struct {
+		Login string `json:"login"`
+	}
+	_ = json.Unmarshal(peekBody(r), &userData)
+	return userData.Login
+}
+
+func peekChallenge(r *http.Request) string {
+	var totpData struct {
+		ChallengeToken string `json:"challenge_token"`
+	}
+	_ = json.Unmarshal(peekBody(r), &totpData)
+	return totpData.ChallengeToken
+}
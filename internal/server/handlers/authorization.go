@@ -2,11 +2,19 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/breach"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/otp"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/types"
 	"github.com/go-chi/jwtauth"
@@ -17,21 +25,39 @@ import (
 const (
 	UserIDReq    = "user_id"
 	UserLoginReq = "login"
+	SessionIDReq = "session_id"
+
+	tokenTTL      = 10 * time.Hour
+	sessionIDSize = 16
+
+	totpIssuer       = "GophKeeper"
+	totpSkew         = 1
+	challengeTTL     = 5 * time.Minute
+	backupCodesCount = 10
+	backupCodeSize   = 5
+	// challengeAttempts is how many codes can be tried with one challenge, it can't be used after success
+	challengeAttempts = 3
 )
 
 type AuthJWT struct {
-	UserStorage types.UserDB
-	AuthToken   *jwtauth.JWTAuth
-	context     context.Context
+	UserStorage    types.UserDB
+	AuthToken      *jwtauth.JWTAuth
+	ChallengeToken *jwtauth.JWTAuth
+	// Breaches are checked for passwords of new users if they are set
+	Breaches *breach.Ranges
+	context  context.Context
 }
 
 func NewAuth(context context.Context, store types.UserDB, secret string) *AuthJWT {
 	jwtAuth := jwtauth.New("HS256", []byte(secret), nil)
+	// challenge tokens are signed with another key so they can't pass as access tokens
+	challengeAuth := jwtauth.New("HS256", []byte(secret+"/totp-challenge"), nil)
 
 	return &AuthJWT{
-		AuthToken:   jwtAuth,
-		UserStorage: store,
-		context:     context,
+		AuthToken:      jwtAuth,
+		ChallengeToken: challengeAuth,
+		UserStorage:    store,
+		context:        context,
 	}
 }
 
@@ -42,6 +68,31 @@ func (a *AuthJWT) CheckData(u types.UserData) error {
 	if u.Password == "" {
 		return errors.New("error: password is empty")
 	}
+	if u.Recovery != nil && (len(u.Recovery.VaultKey) == 0 || u.Recovery.Verifier == "") {
+		return errors.New("error: recovery key is incomplete")
+	}
+
+	return nil
+}
+
+// CheckBreached rejects passwords of new users which were found in data breaches,
+// it is not a part of CheckData because CheckData also checks passwords of existing users at login
+func (a *AuthJWT) CheckBreached(password string) error {
+	if a.Breaches == nil {
+		return nil
+	}
+	count, err := a.Breaches.Count(password)
+	if errors.Is(err, breach.ErrNoRange) {
+		// the dataset may be partial, a password of a range missing from it is not known to be breached
+		log.Println("error while checking breached passwords:", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while checking breached passwords: %w", err)
+	}
+	if count > 0 {
+		return types.ErrBreached
+	}
 
 	return nil
 }
@@ -52,7 +103,16 @@ func (a *AuthJWT) RegisterUser(userdata types.UserData) (types.User, error) {
 		log.Println("error during bcrypt")
 		return types.User{}, types.ErrHashGenerate
 	}
-	user, err := a.UserStorage.RegisterNewUser(userdata.Login, string(hash))
+	user := types.User{
+		Login:        userdata.Login,
+		HashPassword: string(hash),
+		VaultKey:     userdata.VaultKey,
+	}
+	if userdata.Recovery != nil {
+		user.RecoveryVaultKey = userdata.Recovery.VaultKey
+		user.RecoveryHash = hashRecoveryVerifier(userdata.Recovery.Verifier)
+	}
+	user, err = a.UserStorage.RegisterNewUser(user)
 	if err != nil {
 		log.Println("error Register New User")
 		return types.User{}, storage.ErrUserExists
@@ -81,6 +141,15 @@ func (a *AuthJWT) GenerateToken(user types.User) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	sessionID, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+	err = a.UserStorage.CreateSession(user.ID, sessionID, time.Now().Add(tokenTTL))
+	if err != nil {
+		return "", err
+	}
+	reqs[SessionIDReq] = sessionID
 	_, tokenString, err := a.AuthToken.Encode(reqs)
 	if err != nil {
 		return "", err
@@ -92,11 +161,7 @@ func (a *AuthJWT) GenerateToken(user types.User) (string, error) {
 func (a *AuthJWT) getTokenReqs(user types.User) (map[string]interface{}, error) {
 	reqs := map[string]interface{}{}
 	jwtauth.SetIssuedNow(reqs)
-	duration, err := time.ParseDuration("10h")
-	if err != nil {
-		return nil, err
-	}
-	jwtauth.SetExpiryIn(reqs, duration)
+	jwtauth.SetExpiryIn(reqs, tokenTTL)
 	if user.Login == "" {
 		return nil, errors.New("user login is required")
 	}
@@ -106,6 +171,371 @@ func (a *AuthJWT) getTokenReqs(user types.User) (map[string]interface{}, error)
 	return reqs, nil
 }
 
+// GenerateChallengeToken returns short-lived token which can be exchanged for JWT with a TOTP or backup code
+func (a *AuthJWT) GenerateChallengeToken(user types.User) (string, error) {
+	if user.Login == "" {
+		return "", errors.New("user login is required")
+	}
+	challengeID, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+	if err = a.UserStorage.CreateChallenge(user.ID, challengeID, time.Now().Add(challengeTTL)); err != nil {
+		return "", err
+	}
+	reqs := map[string]interface{}{}
+	jwtauth.SetIssuedNow(reqs)
+	jwtauth.SetExpiryIn(reqs, challengeTTL)
+	reqs[UserLoginReq] = user.Login
+	reqs[jwx.JwtIDKey] = challengeID
+	_, tokenString, err := a.ChallengeToken.Encode(reqs)
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// GetChallengeLogin returns login from valid challenge token or empty string
+func (a *AuthJWT) GetChallengeLogin(challenge string) string {
+	login, _ := a.parseChallenge(challenge)
+	return login
+}
+
+// parseChallenge returns login and ID of valid challenge token or empty strings
+func (a *AuthJWT) parseChallenge(challenge string) (string, string) {
+	token, err := jwtauth.VerifyToken(a.ChallengeToken, challenge)
+	if err != nil {
+		return "", ""
+	}
+	claim, _ := token.Get(UserLoginReq)
+	login, _ := claim.(string)
+
+	return login, token.JwtID()
+}
+
+// LoginTOTP passes the challenge with TOTP or backup code, the challenge allows a few attempts and can't be used after success
+func (a *AuthJWT) LoginTOTP(data types.TOTPLoginData) (types.User, error) {
+	login, challengeID := a.parseChallenge(data.ChallengeToken)
+	if login == "" || challengeID == "" {
+		return types.User{}, types.ErrInvalidData
+	}
+	alive, err := a.UserStorage.UseChallenge(challengeID, challengeAttempts)
+	if err != nil {
+		return types.User{}, err
+	}
+	if !alive {
+		return types.User{}, types.ErrInvalidData
+	}
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return types.User{}, err
+	}
+	if user.ID == 0 || !user.TOTPEnabled {
+		return types.User{}, types.ErrInvalidData
+	}
+	passed, err := a.useTOTPCode(user, data.Code)
+	if err != nil {
+		return types.User{}, err
+	}
+	if !passed {
+		passed, err = a.UserStorage.UseBackupCode(user.ID, hashBackupCode(data.Code))
+		if err != nil {
+			return types.User{}, err
+		}
+	}
+	if !passed {
+		return types.User{}, types.ErrInvalidData
+	}
+	if err = a.UserStorage.DeleteChallenge(challengeID); err != nil {
+		log.Println("error while deleting challenge:", err)
+	}
+
+	return user, nil
+}
+
+// useTOTPCode accepts the code if it is valid and newer than the last accepted one, so it can't be replayed
+func (a *AuthJWT) useTOTPCode(user types.User, code string) (bool, error) {
+	step, ok := otp.Match(code, user.TOTPSecret, time.Now(), otp.DefaultParams, totpSkew)
+	if !ok || int64(step) <= user.TOTPCounter {
+		return false, nil
+	}
+	return a.UserStorage.SetTOTPCounter(user.ID, int64(step))
+}
+
+// EnrollTOTP generates new TOTP secret and backup codes, TOTP is enabled after ConfirmTOTP
+func (a *AuthJWT) EnrollTOTP(login string) (types.TOTPEnrollment, error) {
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return types.TOTPEnrollment{}, err
+	}
+	if user.ID == 0 {
+		return types.TOTPEnrollment{}, types.ErrInvalidData
+	}
+	if user.TOTPEnabled {
+		return types.TOTPEnrollment{}, types.ErrTOTPEnabled
+	}
+	secret, err := otp.NewSecret()
+	if err != nil {
+		return types.TOTPEnrollment{}, err
+	}
+	codes := make([]string, backupCodesCount)
+	hashes := make([]string, backupCodesCount)
+	for i := range codes {
+		codes[i], err = newBackupCode()
+		if err != nil {
+			return types.TOTPEnrollment{}, err
+		}
+		hashes[i] = hashBackupCode(codes[i])
+	}
+	if err = a.UserStorage.SetTOTPSecret(user.ID, secret, hashes); err != nil {
+		return types.TOTPEnrollment{}, err
+	}
+
+	return types.TOTPEnrollment{
+		URI:         otp.ProvisioningURI(secret, totpIssuer, user.Login, otp.DefaultParams),
+		Secret:      secret,
+		BackupCodes: codes,
+	}, nil
+}
+
+func (a *AuthJWT) ConfirmTOTP(login string, code string) error {
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return types.ErrInvalidData
+	}
+	if user.TOTPEnabled {
+		return types.ErrTOTPEnabled
+	}
+	if user.TOTPSecret == "" {
+		return types.ErrTOTPNotSet
+	}
+	passed, err := a.useTOTPCode(user, code)
+	if err != nil {
+		return err
+	}
+	if !passed {
+		return types.ErrInvalidData
+	}
+
+	return a.UserStorage.EnableTOTP(user.ID)
+}
+
+// GetSessionID returns ID of the session the request's token belongs to
+func (a *AuthJWT) GetSessionID(r *http.Request) string {
+	token := a.verify(r, jwtauth.TokenFromCookie, jwtauth.TokenFromHeader)
+	if token == nil {
+		return ""
+	}
+	claim, _ := token.Get(SessionIDReq)
+	sessionID, _ := claim.(string)
+
+	return sessionID
+}
+
+// CheckSession reports whether the request's session was not revoked
+func (a *AuthJWT) CheckSession(r *http.Request) bool {
+	sessionID := a.GetSessionID(r)
+	if sessionID == "" {
+		return false
+	}
+	active, err := a.UserStorage.SessionActive(sessionID)
+	if err != nil {
+		log.Println("error while checking session:", err)
+		return false
+	}
+
+	return active
+}
+
+// ChangePassword replaces password hash and wrapped vault key and revokes all sessions except sessionID
+func (a *AuthJWT) ChangePassword(login string, sessionID string, data types.ChangePasswordData) error {
+	user, err := a.LoginUser(types.UserData{Login: login, Password: data.OldPassword})
+	if err != nil {
+		return err
+	}
+	// secrets stay readable only if the vault key was re-wrapped with the new password
+	if len(user.VaultKey) != 0 && len(data.VaultKey) == 0 {
+		return types.ErrVaultKeyReq
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(data.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("error during bcrypt")
+		return types.ErrHashGenerate
+	}
+
+	return a.UserStorage.ChangePassword(user.ID, string(hash), data.VaultKey, sessionID)
+}
+
+func (a *AuthJWT) GetVaultKey(login string) ([]byte, error) {
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, types.ErrInvalidData
+	}
+
+	return user.VaultKey, nil
+}
+
+// SetVaultKey stores wrapped vault key for users registered without one
+func (a *AuthJWT) SetVaultKey(login string, vaultKey []byte) error {
+	if len(vaultKey) == 0 {
+		return types.ErrInvalidData
+	}
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return types.ErrInvalidData
+	}
+
+	return a.UserStorage.SetVaultKey(user.ID, vaultKey)
+}
+
+// GetKeyPair returns user's public key and private key encrypted with the user's vault key
+func (a *AuthJWT) GetKeyPair(login string) (types.KeyPairData, error) {
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return types.KeyPairData{}, err
+	}
+	if user.ID == 0 {
+		return types.KeyPairData{}, types.ErrInvalidData
+	}
+
+	return types.KeyPairData{PublicKey: user.PublicKey, PrivateKey: user.PrivateKey}, nil
+}
+
+// SetKeyPair publishes user's public key and stores the encrypted private key
+func (a *AuthJWT) SetKeyPair(login string, keyPair types.KeyPairData) error {
+	if len(keyPair.PublicKey) == 0 || len(keyPair.PrivateKey) == 0 {
+		return types.ErrInvalidData
+	}
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return types.ErrInvalidData
+	}
+
+	return a.UserStorage.SetKeyPair(user.ID, keyPair.PublicKey, keyPair.PrivateKey)
+}
+
+// GetPublicKey returns public key of any user to seal secrets for the user
+func (a *AuthJWT) GetPublicKey(login string) ([]byte, error) {
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 || len(user.PublicKey) == 0 {
+		return nil, types.ErrNoPublicKey
+	}
+
+	return user.PublicKey, nil
+}
+
+// DeleteAccount removes the user and all of the user's data after password check
+func (a *AuthJWT) DeleteAccount(login string, password string) error {
+	user, err := a.LoginUser(types.UserData{Login: login, Password: password})
+	if err != nil {
+		return err
+	}
+
+	return a.UserStorage.DeleteUser(user.ID, user.Login)
+}
+
+// SetRecoveryKey replaces user's recovery key, the previous one stops working
+func (a *AuthJWT) SetRecoveryKey(login string, recovery types.RecoveryKeyData) error {
+	if len(recovery.VaultKey) == 0 || recovery.Verifier == "" {
+		return types.ErrInvalidData
+	}
+	user, err := a.UserStorage.GetUserData(login)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return types.ErrInvalidData
+	}
+
+	return a.UserStorage.SetRecoveryKey(user.ID, recovery.VaultKey, hashRecoveryVerifier(recovery.Verifier))
+}
+
+// GetRecoveryVaultKey returns vault key wrapped with the recovery key to the user who proved knowledge of the recovery key
+func (a *AuthJWT) GetRecoveryVaultKey(data types.RecoveryData) ([]byte, error) {
+	user, err := a.checkRecovery(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.RecoveryVaultKey, nil
+}
+
+// ResetPassword sets new password without the old one and revokes all sessions, the vault key must be re-wrapped with the new password
+func (a *AuthJWT) ResetPassword(data types.RecoveryData) error {
+	user, err := a.checkRecovery(data)
+	if err != nil {
+		return err
+	}
+	if len(user.VaultKey) != 0 && len(data.VaultKey) == 0 {
+		return types.ErrVaultKeyReq
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(data.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("error during bcrypt")
+		return types.ErrHashGenerate
+	}
+
+	return a.UserStorage.ChangePassword(user.ID, string(hash), data.VaultKey, "")
+}
+
+// checkRecovery returns user if the verifier matches user's recovery key, unknown users and users without recovery key fail the same way
+func (a *AuthJWT) checkRecovery(data types.RecoveryData) (types.User, error) {
+	user, err := a.UserStorage.GetUserData(data.Login)
+	if err != nil {
+		return types.User{}, err
+	}
+	if user.ID == 0 || user.RecoveryHash == "" {
+		return types.User{}, types.ErrInvalidData
+	}
+	if subtle.ConstantTimeCompare([]byte(user.RecoveryHash), []byte(hashRecoveryVerifier(data.Verifier))) != 1 {
+		return types.User{}, types.ErrInvalidData
+	}
+
+	return user, nil
+}
+
+func newSessionID() (string, error) {
+	sessionID := make([]byte, sessionIDSize)
+	if _, err := rand.Read(sessionID); err != nil {
+		return "", fmt.Errorf("error while generating session id: %w", err)
+	}
+	return hex.EncodeToString(sessionID), nil
+}
+
+func newBackupCode() (string, error) {
+	code := make([]byte, backupCodeSize)
+	if _, err := rand.Read(code); err != nil {
+		return "", fmt.Errorf("error while generating backup code: %w", err)
+	}
+	return hex.EncodeToString(code), nil
+}
+
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashRecoveryVerifier(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return hex.EncodeToString(sum[:])
+}
+
 func (a *AuthJWT) GetUserID(r *http.Request) int {
 	token := a.verify(r, jwtauth.TokenFromCookie, jwtauth.TokenFromHeader)
 
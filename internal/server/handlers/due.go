@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultDueDays = 30
+	maxDueDays     = 365
+)
+
+type dueResponse struct {
+	Items []storage.DueInfo `json:"items"`
+}
+
+// GetDueHandler lists the user's secrets which are expired, overdue for rotation or due within ?days= days
+func (s *Server) GetDueHandler(c echo.Context) error {
+	days := defaultDueDays
+	if param := c.QueryParam("days"); param != "" {
+		var err error
+		days, err = strconv.Atoi(param)
+		if err != nil || days < 0 || days > maxDueDays {
+			http.Error(c.Response().Writer, "wrong days", http.StatusBadRequest)
+			return nil
+		}
+	}
+	metas, infos, err := s.userInfos(s.Auth.GetUserLogin(c.Request()), "")
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get data", http.StatusInternalServerError)
+		log.Println("error while getting due secrets:", err)
+		return nil
+	}
+	now := time.Now()
+	until := now.AddDate(0, 0, days)
+	resp := dueResponse{Items: []storage.DueInfo{}}
+	for i, info := range infos {
+		due, ok := storage.NextDue(info)
+		if !ok || due.Date.After(until) {
+			continue
+		}
+		resp.Items = append(resp.Items, storage.DueInfo{
+			Name:    metas[i].Name,
+			Type:    metas[i].Type,
+			Reason:  due.Reason,
+			Date:    due.Date.Format(storage.DateLayout),
+			Overdue: !due.Date.After(now),
+		})
+	}
+	sort.SliceStable(resp.Items, func(i, j int) bool { return resp.Items[i].Date < resp.Items[j].Date })
+	return writeJSON(c, http.StatusOK, resp)
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+type emergencyRequest struct {
+	// Login is the trusted contact for requests of the owner
+	Login string `json:"login,omitempty"`
+	// Owner is the owner of the vault for requests of the trusted contact
+	Owner     string           `json:"owner,omitempty"`
+	WaitHours int              `json:"wait_hours,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Type      storage.InfoType `json:"type,omitempty"`
+}
+
+type emergencyContactsResponse struct {
+	Contacts []emergency.Contact `json:"contacts"`
+}
+
+type emergencyVaultResponse struct {
+	Infos []storage.InfoMeta `json:"infos"`
+}
+
+// AddEmergencyContactHandler makes another user the user's trusted contact or changes the contact's waiting period
+func (s *Server) AddEmergencyContactHandler(c echo.Context) error {
+	req, ok := readEmergencyRequest(c)
+	if !ok {
+		return nil
+	}
+	owner := s.Auth.GetUserLogin(c.Request())
+	success := false
+	defer func() {
+		s.recordEmergencyAudit(c.Request(), audit.ActionEmergencyAdd, owner, req.Login, storage.InfoMeta{}, success)
+	}()
+	if req.Login == owner {
+		http.Error(c.Response().Writer, "cannot trust yourself", http.StatusBadRequest)
+		return nil
+	}
+	if !emergency.ValidWait(req.WaitHours) {
+		http.Error(c.Response().Writer, "wrong waiting period", http.StatusBadRequest)
+		return nil
+	}
+	err := s.Storage.AddEmergencyContact(owner, req.Login, req.WaitHours)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		http.Error(c.Response().Writer, "no such user", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot add emergency contact", http.StatusInternalServerError)
+		log.Println("error while adding emergency contact:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) RemoveEmergencyContactHandler(c echo.Context) error {
+	req, ok := readEmergencyRequest(c)
+	if !ok {
+		return nil
+	}
+	owner := s.Auth.GetUserLogin(c.Request())
+	success := false
+	defer func() {
+		s.recordEmergencyAudit(c.Request(), audit.ActionEmergencyRemove, owner, req.Login, storage.InfoMeta{}, success)
+	}()
+	err := s.Storage.RemoveEmergencyContact(owner, req.Login)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no such emergency contact", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot remove emergency contact", http.StatusInternalServerError)
+		log.Println("error while removing emergency contact:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// RejectEmergencyHandler rejects the contact's pending request or takes back access which is already granted
+func (s *Server) RejectEmergencyHandler(c echo.Context) error {
+	req, ok := readEmergencyRequest(c)
+	if !ok {
+		return nil
+	}
+	owner := s.Auth.GetUserLogin(c.Request())
+	success := false
+	defer func() {
+		s.recordEmergencyAudit(c.Request(), audit.ActionEmergencyReject, owner, req.Login, storage.InfoMeta{}, success)
+	}()
+	contact, ok := s.getEmergencyContact(c, owner, req.Login)
+	if !ok {
+		return nil
+	}
+	contact, err := contact.Reject()
+	if errors.Is(err, emergency.ErrNotRequested) {
+		http.Error(c.Response().Writer, "emergency access is not requested", http.StatusConflict)
+		return nil
+	}
+	if !s.setEmergencyStatus(c, contact) {
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// RequestEmergencyHandler starts waiting period after which the contact gets access to the owner's vault
+func (s *Server) RequestEmergencyHandler(c echo.Context) error {
+	req, ok := readEmergencyRequest(c)
+	if !ok {
+		return nil
+	}
+	login := s.Auth.GetUserLogin(c.Request())
+	success := false
+	defer func() {
+		s.recordEmergencyAudit(c.Request(), audit.ActionEmergencyRequest, req.Owner, login, storage.InfoMeta{}, success)
+	}()
+	contact, ok := s.getEmergencyContact(c, req.Owner, login)
+	if !ok {
+		return nil
+	}
+	contact, err := contact.Request(time.Now().UTC().Truncate(time.Microsecond))
+	if errors.Is(err, emergency.ErrAlreadyRequested) {
+		http.Error(c.Response().Writer, "emergency access is already requested", http.StatusConflict)
+		return nil
+	}
+	if !s.setEmergencyStatus(c, contact) {
+		return nil
+	}
+	success = true
+	return writeJSON(c, http.StatusOK, contact)
+}
+
+// GetEmergencyContactsHandler returns the user's trusted contacts with statuses of their access
+func (s *Server) GetEmergencyContactsHandler(c echo.Context) error {
+	contacts, err := s.Storage.GetEmergencyContacts(s.Auth.GetUserLogin(c.Request()))
+	return writeEmergencyContacts(c, contacts, err)
+}
+
+// GetEmergencyGrantorsHandler returns users who trust the user with statuses of the user's access
+func (s *Server) GetEmergencyGrantorsHandler(c echo.Context) error {
+	contacts, err := s.Storage.GetEmergencyGrantors(s.Auth.GetUserLogin(c.Request()))
+	return writeEmergencyContacts(c, contacts, err)
+}
+
+// GetEmergencyVaultHandler returns names and types of secrets of the owner from ?owner= param once access is granted
+func (s *Server) GetEmergencyVaultHandler(c echo.Context) error {
+	owner := c.QueryParam("owner")
+	if !s.checkEmergencyGrant(c, owner) {
+		return nil
+	}
+	infos, err := s.Storage.ListData(owner)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get data", http.StatusInternalServerError)
+		log.Println("error while listing data:", err)
+		return nil
+	}
+	resp := emergencyVaultResponse{Infos: []storage.InfoMeta{}}
+	if infos != nil {
+		resp.Infos = infos
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+// GetEmergencyDataHandler returns secret of the owner once access is granted
+func (s *Server) GetEmergencyDataHandler(c echo.Context) error {
+	req, ok := readEmergencyRequest(c)
+	if !ok {
+		return nil
+	}
+	meta := storage.InfoMeta{Name: req.Name, Type: req.Type, Login: req.Owner}
+	success := false
+	defer func() {
+		s.recordEmergencyAudit(c.Request(), audit.ActionEmergencyRead, req.Owner, s.Auth.GetUserLogin(c.Request()), meta, success)
+	}()
+	if !s.checkEmergencyGrant(c, req.Owner) {
+		return nil
+	}
+	encData, err := s.Storage.GetData(meta)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get data from database", http.StatusInternalServerError)
+		log.Println("error while getting data from database:", err)
+		return nil
+	}
+	if !s.writeInfo(c, meta.Type, encData) {
+		return nil
+	}
+	success = true
+	return nil
+}
+
+// checkEmergencyGrant checks that the user has emergency access to the owner's vault, on failure the error response is already written
+func (s *Server) checkEmergencyGrant(c echo.Context, owner string) bool {
+	contact, ok := s.getEmergencyContact(c, owner, s.Auth.GetUserLogin(c.Request()))
+	if !ok {
+		return false
+	}
+	if !contact.Granted(time.Now()) {
+		http.Error(c.Response().Writer, "emergency access is not granted", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// getEmergencyContact returns the owner's trusted contact, on failure the error response is already written
+func (s *Server) getEmergencyContact(c echo.Context, owner string, login string) (emergency.Contact, bool) {
+	contact, err := s.Storage.GetEmergencyContact(owner, login)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no such emergency contact", http.StatusNotFound)
+		return contact, false
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get emergency contact", http.StatusInternalServerError)
+		log.Println("error while getting emergency contact:", err)
+		return contact, false
+	}
+	return contact, true
+}
+
+func (s *Server) setEmergencyStatus(c echo.Context, contact emergency.Contact) bool {
+	if err := s.Storage.SetEmergencyStatus(contact); err != nil {
+		http.Error(c.Response().Writer, "cannot update emergency contact", http.StatusInternalServerError)
+		log.Println("error while updating emergency contact:", err)
+		return false
+	}
+	return true
+}
+
+func writeEmergencyContacts(c echo.Context, contacts []emergency.Contact, err error) error {
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get emergency contacts", http.StatusInternalServerError)
+		log.Println("error while getting emergency contacts:", err)
+		return nil
+	}
+	resp := emergencyContactsResponse{Contacts: []emergency.Contact{}}
+	now := time.Now()
+	for _, contact := range contacts {
+		resp.Contacts = append(resp.Contacts, contact.Resolve(now))
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+// recordEmergencyAudit records the step in the log of the user who did it and, if it succeeded, in the log of the other party
+func (s *Server) recordEmergencyAudit(r *http.Request, action audit.Action, owner string, contact string, meta storage.InfoMeta, success bool) {
+	actor := s.Auth.GetUserLogin(r)
+	for _, login := range []string{owner, contact} {
+		if login != actor && !success {
+			continue
+		}
+		entry := newAuditEntry(r, login, action, success)
+		entry.Actor = actor
+		entry.Target = contact
+		if login == contact {
+			entry.Target = owner
+		}
+		entry.SecretType = string(meta.Type)
+		entry.SecretName = meta.Name
+		s.Audit.Record(entry)
+	}
+}
+
+func readEmergencyRequest(c echo.Context) (emergencyRequest, bool) {
+	var req emergencyRequest
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return req, false
+	}
+	defer c.Request().Body.Close()
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return req, false
+	}
+	return req, true
+}
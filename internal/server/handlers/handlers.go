@@ -4,16 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/AbramovArseniy/GophKeeper/internal/server/services"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/breach"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/bruteforce"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/config"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/crypto"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage/database"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/types"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/upgrade"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 )
@@ -27,6 +35,9 @@ type Server struct {
 	jwtSecret string
 	Auth      types.Authorization
 	SecretKey []byte
+	Guard     *bruteforce.Guard
+	Audit     *audit.Logger
+	upgrader  *upgrade.Upgrader
 }
 
 // NewServer creates new MetricServer
@@ -38,104 +49,468 @@ func NewServer(cfg config.Config) *Server {
 	if err != nil {
 		log.Println("error while creating new database:", err)
 	}
+	var (
+		guard       *bruteforce.Guard
+		auditLogger *audit.Logger
+		upgrader    *upgrade.Upgrader
+	)
+	if db != nil {
+		auditLogger = audit.NewLogger(db)
+		upgrader = upgrade.NewUpgrader(db, secret)
+		policy := bruteforce.DefaultPolicy
+		policy.LockoutAttempts = cfg.LockoutAttempts
+		policy.LockoutDuration = cfg.LockoutDuration
+		guard = bruteforce.NewGuard(db, policy)
+	}
+	auth := NewAuth(context, db, cfg.JWTSecret)
+	if cfg.BreachedPasswords != "" {
+		auth.Breaches, err = breach.Open(cfg.BreachedPasswords)
+		if err != nil {
+			// the server must not silently accept breached passwords it was configured to reject
+			log.Fatalln("error while opening breached passwords:", err)
+		}
+	}
 	return &Server{
 		Addr:      cfg.Address,
 		Storage:   db,
 		SecretKey: secret,
 		jwtSecret: cfg.JWTSecret,
-		Auth:      NewAuth(context, db, cfg.JWTSecret),
+		Auth:      auth,
+		Guard:     guard,
+		Audit:     auditLogger,
+		upgrader:  upgrader,
+	}
+}
+
+// UpgradeData re-encodes secrets stored in outdated encoding, it is run in background when the server starts
+func (s *Server) UpgradeData(ctx context.Context) {
+	if s.upgrader == nil {
+		return
+	}
+	result, err := s.upgrader.Run(ctx)
+	if err != nil {
+		log.Println("error while upgrading secrets:", err)
 	}
+	log.Printf("secrets upgrade finished: checked %d, upgraded %d, skipped %d", result.Checked, result.Upgraded, result.Skipped)
 }
 
 func (s *Server) RegistHandler(c echo.Context) error {
-	httpStatus, token, err := services.RegistService(c.Request(), s.Auth)
+	login := peekLogin(c.Request())
+	httpStatus, token, err := services.RegistService(c.Request(), s.Auth, s.Guard)
+	if httpStatus == http.StatusTooManyRequests {
+		setRetryAfter(c, err)
+	}
+	s.Audit.Record(newAuditEntry(c.Request(), login, audit.ActionRegister, httpStatus == http.StatusOK))
+	if errors.Is(err, types.ErrBreached) {
+		log.Println(err)
+		http.Error(c.Response(), types.ErrBreached.Error(), httpStatus)
+		return nil
+	}
 
-	c.Response().Header().Set("Authorization", "Bearer "+token)
+	c.Response().Header().Set("Authorization", token)
 	c.Response().Writer.WriteHeader(httpStatus)
 
 	return err
 }
 
 func (s *Server) AuthHandler(c echo.Context) error {
-	body, _ := io.ReadAll(c.Request().Body)
-	log.Println(string(body))
-	httpStatus, token, err := services.AuthService(c.Request(), s.Auth)
+	login := peekLogin(c.Request())
+	httpStatus, token, err := services.AuthService(c.Request(), s.Auth, s.Guard)
+	if httpStatus == http.StatusTooManyRequests {
+		setRetryAfter(c, err)
+	}
+	// password step of 2FA login is successful too, the second step is recorded separately
+	success := httpStatus == http.StatusOK || httpStatus == http.StatusAccepted
+	s.Audit.Record(newAuditEntry(c.Request(), login, audit.ActionLogin, success))
+	if httpStatus == http.StatusAccepted {
+		return writeJSON(c, httpStatus, types.ChallengeResponse{ChallengeToken: token})
+	}
 	c.Response().Header().Set("Authorization", token)
 	c.Response().Writer.WriteHeader(httpStatus)
 	return err
 }
 
+func (s *Server) TOTPAuthHandler(c echo.Context) error {
+	login := s.Auth.GetChallengeLogin(peekChallenge(c.Request()))
+	httpStatus, token, err := services.TOTPAuthService(c.Request(), s.Auth, s.Guard)
+	if httpStatus == http.StatusTooManyRequests {
+		setRetryAfter(c, err)
+	}
+	s.Audit.Record(newAuditEntry(c.Request(), login, audit.ActionLoginTOTP, httpStatus == http.StatusOK))
+	c.Response().Header().Set("Authorization", token)
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+func (s *Server) TOTPEnrollHandler(c echo.Context) error {
+	httpStatus, enrollment, err := services.TOTPEnrollService(c.Request(), s.Auth)
+	login := s.Auth.GetUserLogin(c.Request())
+	s.Audit.Record(newAuditEntry(c.Request(), login, audit.ActionEnrollTOTP, httpStatus == http.StatusOK))
+	if err != nil {
+		c.Response().Writer.WriteHeader(httpStatus)
+		return err
+	}
+	return writeJSON(c, httpStatus, enrollment)
+}
+
+func (s *Server) TOTPConfirmHandler(c echo.Context) error {
+	httpStatus, err := services.TOTPConfirmService(c.Request(), s.Auth)
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+func (s *Server) ChangePasswordHandler(c echo.Context) error {
+	httpStatus, err := services.ChangePasswordService(c.Request(), s.Auth)
+	login := s.Auth.GetUserLogin(c.Request())
+	s.Audit.Record(newAuditEntry(c.Request(), login, audit.ActionChangePassword, httpStatus == http.StatusOK))
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+func (s *Server) SetRecoveryKeyHandler(c echo.Context) error {
+	httpStatus, err := services.SetRecoveryKeyService(c.Request(), s.Auth)
+	login := s.Auth.GetUserLogin(c.Request())
+	s.Audit.Record(newAuditEntry(c.Request(), login, audit.ActionSetRecoveryKey, httpStatus == http.StatusOK))
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+// RecoveryVaultKeyHandler returns vault key wrapped with the recovery key, it is the first step of password reset
+func (s *Server) RecoveryVaultKeyHandler(c echo.Context) error {
+	httpStatus, vaultKey, err := services.RecoveryVaultKeyService(c.Request(), s.Auth, s.Guard)
+	if httpStatus == http.StatusTooManyRequests {
+		setRetryAfter(c, err)
+	}
+	if httpStatus != http.StatusOK {
+		c.Response().Writer.WriteHeader(httpStatus)
+		return err
+	}
+	return writeJSON(c, httpStatus, vaultKey)
+}
+
+// ResetPasswordHandler sets new password with vault key re-wrapped on the client, it is the second step of password reset
+func (s *Server) ResetPasswordHandler(c echo.Context) error {
+	login := peekLogin(c.Request())
+	httpStatus, err := services.ResetPasswordService(c.Request(), s.Auth, s.Guard)
+	if httpStatus == http.StatusTooManyRequests {
+		setRetryAfter(c, err)
+	}
+	s.Audit.Record(newAuditEntry(c.Request(), login, audit.ActionResetPassword, httpStatus == http.StatusOK))
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+func (s *Server) GetVaultKeyHandler(c echo.Context) error {
+	httpStatus, vaultKey, err := services.GetVaultKeyService(c.Request(), s.Auth)
+	if httpStatus != http.StatusOK {
+		c.Response().Writer.WriteHeader(httpStatus)
+		return err
+	}
+	return writeJSON(c, httpStatus, vaultKey)
+}
+
+func (s *Server) SetVaultKeyHandler(c echo.Context) error {
+	httpStatus, err := services.SetVaultKeyService(c.Request(), s.Auth)
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+func (s *Server) GetKeyPairHandler(c echo.Context) error {
+	httpStatus, keyPair, err := services.GetKeyPairService(c.Request(), s.Auth)
+	if httpStatus != http.StatusOK {
+		c.Response().Writer.WriteHeader(httpStatus)
+		return err
+	}
+	return writeJSON(c, httpStatus, keyPair)
+}
+
+func (s *Server) SetKeyPairHandler(c echo.Context) error {
+	httpStatus, err := services.SetKeyPairService(c.Request(), s.Auth)
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+// GetPublicKeyHandler returns public key of the user from ?login= param
+func (s *Server) GetPublicKeyHandler(c echo.Context) error {
+	httpStatus, publicKey, err := services.GetPublicKeyService(c.Request(), s.Auth)
+	if httpStatus != http.StatusOK {
+		c.Response().Writer.WriteHeader(httpStatus)
+		return err
+	}
+	return writeJSON(c, httpStatus, publicKey)
+}
+
+func (s *Server) DeleteAccountHandler(c echo.Context) error {
+	httpStatus, err := services.DeleteAccountService(c.Request(), s.Auth)
+	if errors.Is(err, orgs.ErrLastOwner) {
+		http.Error(c.Response().Writer, err.Error(), httpStatus)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(httpStatus)
+	return err
+}
+
+// checkSession rejects tokens whose session was revoked
+func (s *Server) checkSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.Auth != nil && !s.Auth.CheckSession(c.Request()) {
+			return echo.ErrUnauthorized
+		}
+		return next(c)
+	}
+}
+
+func setRetryAfter(c echo.Context, err error) {
+	var blocked *bruteforce.BlockedError
+	if errors.As(err, &blocked) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(blocked.RetryAfterSeconds()))
+	}
+}
+
+func writeJSON(c echo.Context, httpStatus int, v any) error {
+	respBody, err := json.Marshal(v)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot marshal response body", http.StatusInternalServerError)
+		log.Println("error while marshalling response body:", err)
+		return nil
+	}
+	c.Response().Header().Set("Content-Type", contentTypeJSON)
+	c.Response().Writer.WriteHeader(httpStatus)
+	_, err = c.Response().Writer.Write(respBody)
+	if err != nil {
+		log.Println("error while writing response body:", err)
+	}
+	return nil
+}
+
 func (s *Server) PostSaveDataHandler(c echo.Context) error {
+	success := false
+	var meta storage.InfoMeta
+	defer func() { s.recordDataAudit(c.Request(), audit.ActionSave, meta, success) }()
+	meta, data, ok := s.readInfo(c)
+	if !ok || !s.authorizeData(c, meta, true) {
+		return nil
+	}
+	encData, ok := s.encryptInfo(c, meta, data, nil)
+	if !ok {
+		return nil
+	}
+	err := s.Storage.SaveData(encData, meta)
+	if errors.Is(err, storage.ErrInvalidData) {
+		http.Error(c.Response().Writer, "invalid data", http.StatusBadRequest)
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot save data to database", http.StatusInternalServerError)
+		log.Println("error while  saving data to database:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) UpdateDataHandler(c echo.Context) error {
+	success := false
+	var meta storage.InfoMeta
+	defer func() { s.recordDataAudit(c.Request(), audit.ActionUpdate, meta, success) }()
+	meta, data, ok := s.readInfo(c)
+	if !ok || !s.authorizeData(c, meta, true) {
+		return nil
+	}
+	stored, err := s.storedInfo(meta)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get data from database", http.StatusInternalServerError)
+		log.Println("error while getting stored data:", err)
+		return nil
+	}
+	encData, ok := s.encryptInfo(c, meta, data, stored)
+	if !ok {
+		return nil
+	}
+	err = s.Storage.UpdateData(encData, meta)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
+		return nil
+	}
+	if errors.Is(err, storage.ErrAccessDenied) {
+		http.Error(c.Response().Writer, "no write access", http.StatusForbidden)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot update data in database", http.StatusInternalServerError)
+		log.Println("error while updating data in database:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) DeleteDataHandler(c echo.Context) error {
+	success := false
+	var meta storage.InfoMeta
+	defer func() { s.recordDataAudit(c.Request(), audit.ActionDelete, meta, success) }()
 	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
 		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
 		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
 		return nil
 	}
 	defer c.Request().Body.Close()
+	err := json.NewDecoder(c.Request().Body).Decode(&meta)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return nil
+	}
+	meta.Login = s.Auth.GetUserLogin(c.Request())
+	if meta.Shared() {
+		http.Error(c.Response().Writer, "only owner can delete data", http.StatusForbidden)
+		return nil
+	}
+	if !s.authorizeData(c, meta, true) {
+		return nil
+	}
+	err = s.Storage.DeleteData(meta)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot delete data from database", http.StatusInternalServerError)
+		log.Println("error while deleting data from database:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// readInfo reads secret from request body and validates it, on failure the error response is already written
+func (s *Server) readInfo(c echo.Context) (storage.InfoMeta, storage.Info, bool) {
+	var meta storage.InfoMeta
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return meta, nil, false
+	}
+	defer c.Request().Body.Close()
 	body, err := io.ReadAll(c.Request().Body)
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot read request body", http.StatusInternalServerError)
 		log.Println("error while reading request body:", err)
-		return nil
+		return meta, nil, false
 	}
-	var meta storage.InfoMeta
 	err = json.Unmarshal(body, &meta)
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusInternalServerError)
 		log.Println("error while unmarshalling request body:", err)
-		return nil
+		return meta, nil, false
 	}
-	data := storage.NewInfo(meta.Type)
-	if data == nil {
+	if s.Auth != nil {
+		meta.Login = s.Auth.GetUserLogin(c.Request())
+	} else {
+		log.Println("no jwt auth")
+	}
+	spec, ok := storage.Lookup(meta.Type)
+	if !ok {
 		http.Error(c.Response().Writer, "wrong data type", http.StatusBadRequest)
 		log.Println("wrong data type")
-		return nil
+		return meta, nil, false
 	}
+	data := spec.New()
 	err = json.Unmarshal(body, &data)
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusInternalServerError)
 		log.Println("error while unmarshalling request body:", err)
-		return nil
+		return meta, nil, false
+	}
+	err = spec.Validate(data)
+	if err != nil {
+		log.Println("invalid data:", err)
+		var fieldErrs storage.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			writeJSON(c, http.StatusBadRequest, invalidDataResponse{Error: err.Error(), Fields: fieldErrs})
+			return meta, nil, false
+		}
+		http.Error(c.Response().Writer, err.Error(), http.StatusBadRequest)
+		return meta, nil, false
 	}
-	binData, err := data.MakeBinary()
+	return meta, data, true
+}
+
+// storedInfo returns the decrypted secret which is going to be updated
+func (s *Server) storedInfo(meta storage.InfoMeta) (storage.Info, error) {
+	encData, err := s.Storage.GetData(meta)
+	if err != nil {
+		return nil, err
+	}
+	return s.decryptInfo(meta.Type, encData)
+}
+
+// encryptInfo records the change of the secret's value against the stored secret, which is nil for new secrets,
+// and encrypts the secret, on failure the error response is already written
+func (s *Server) encryptInfo(c echo.Context, meta storage.InfoMeta, data, stored storage.Info) ([]byte, bool) {
+	spec, _ := storage.Lookup(meta.Type)
+	if err := storage.TouchChanged(spec, data, stored, time.Now()); err != nil {
+		http.Error(c.Response().Writer, "cannot compare data with stored one", http.StatusInternalServerError)
+		log.Println("error while comparing data with stored one:", err)
+		return nil, false
+	}
+	binData, err := storage.Encode(meta.Type, data)
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot make data binary", http.StatusInternalServerError)
 		log.Println("error while making data binary:", err)
-		return nil
+		return nil, false
 	}
 	encData, err := crypto.Encrypt(binData, s.SecretKey)
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot encrypt data", http.StatusInternalServerError)
 		log.Println("error while encrypting data:", err)
-		return nil
+		return nil, false
 	}
-	if s.Auth != nil {
-		meta.Login = s.Auth.GetUserLogin(c.Request())
-	} else {
-		log.Println("no jwt auth")
-	}
-	err = s.Storage.SaveData(encData, meta)
-	if errors.Is(err, storage.ErrInvalidData) {
-		http.Error(c.Response().Writer, "invalid data", http.StatusBadRequest)
-	}
-	if err != nil {
-		http.Error(c.Response().Writer, "cannot save data to database", http.StatusInternalServerError)
-		log.Println("error while  saving data to database:", err)
-		return nil
-	}
-	c.Response().Writer.WriteHeader(http.StatusOK)
-	return nil
+	return encData, true
 }
 
 // func (s *Server) GetDataByTypeHandler(c echo.Context) error {
 
 // }
 
-// func (s *Server) GetAllUsersDataHandler(c echo.Context) error {
+// invalidDataResponse tells the client which fields of the secret are invalid
+type invalidDataResponse struct {
+	Error  string              `json:"error"`
+	Fields storage.FieldErrors `json:"fields"`
+}
 
-// }
+type usersDataResponse struct {
+	Infos []storage.InfoMeta `json:"infos"`
+}
+
+// GetAllUsersDataHandler lists names and types of the user's own secrets, type query parameter filters them
+func (s *Server) GetAllUsersDataHandler(c echo.Context) error {
+	infos, err := s.Storage.ListData(s.Auth.GetUserLogin(c.Request()))
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get data", http.StatusInternalServerError)
+		log.Println("error while listing data:", err)
+		return nil
+	}
+	infoType := storage.InfoType(c.QueryParam("type"))
+	resp := usersDataResponse{Infos: []storage.InfoMeta{}}
+	for _, info := range infos {
+		if infoType == "" || info.Type == infoType {
+			resp.Infos = append(resp.Infos, info)
+		}
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
 
 func (s *Server) GetDataByNameHandler(c echo.Context) error {
+	success := false
+	var meta storage.InfoMeta
+	defer func() { s.recordDataAudit(c.Request(), audit.ActionRead, meta, success) }()
 	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
 		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
 		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
@@ -148,7 +523,6 @@ func (s *Server) GetDataByNameHandler(c echo.Context) error {
 		log.Println("error while reading request body:", err)
 		return nil
 	}
-	var meta storage.InfoMeta
 	err = json.Unmarshal(reqBody, &meta)
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusInternalServerError)
@@ -160,6 +534,9 @@ func (s *Server) GetDataByNameHandler(c echo.Context) error {
 	} else {
 		log.Println("no jwt auth")
 	}
+	if !s.authorizeData(c, meta, false) {
+		return nil
+	}
 	encData, err := s.Storage.GetData(meta)
 	if errors.Is(err, storage.ErrDataNotFound) {
 		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
@@ -174,33 +551,71 @@ func (s *Server) GetDataByNameHandler(c echo.Context) error {
 		log.Println("error while getting data from database:", err)
 		return nil
 	}
-	binData, err := crypto.Decrypt(encData, s.SecretKey)
-	if err != nil {
-		http.Error(c.Response().Writer, "cannot decrypt data", http.StatusInternalServerError)
-		log.Println("error while decrypting data:", err)
+	if !s.writeInfo(c, meta.Type, encData) {
 		return nil
 	}
-	data := storage.NewInfo(meta.Type)
-	err = data.DecodeBinary(binData)
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// writeInfo decrypts secret and writes it as response body, on failure the error response is already written
+func (s *Server) writeInfo(c echo.Context, infoType storage.InfoType, encData []byte) bool {
+	data, err := s.decryptInfo(infoType, encData)
 	if err != nil {
-		log.Println("error while decoding binary:", err)
-		http.Error(c.Response().Writer, "cannot decode data binary", http.StatusInternalServerError)
-		return nil
+		http.Error(c.Response().Writer, "cannot decrypt data", http.StatusInternalServerError)
+		log.Println(err)
+		return false
 	}
 	respBody, err := json.MarshalIndent(&data, "  ", "")
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot marshal response body", http.StatusInternalServerError)
 		log.Println("error while marshalling response body:", err)
-		return nil
+		return false
 	}
 	_, err = c.Response().Writer.Write(respBody)
 	if err != nil {
 		http.Error(c.Response().Writer, "cannot write response body", http.StatusInternalServerError)
 		log.Println("error while writing response body:", err)
-		return nil
+		return false
 	}
-	c.Response().Writer.WriteHeader(http.StatusOK)
-	return nil
+	return true
+}
+
+// decryptInfo decrypts and decodes stored secret
+func (s *Server) decryptInfo(infoType storage.InfoType, encData []byte) (storage.Info, error) {
+	binData, err := crypto.Decrypt(encData, s.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("error while decrypting data: %w", err)
+	}
+	data, err := storage.Decode(infoType, binData)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding binary: %w", err)
+	}
+	return data, nil
+}
+
+// userInfos returns the user's own secrets of the type or of all types if it is empty, they are decrypted,
+// secrets which cannot be decrypted are logged and skipped so one broken secret does not hide the others
+func (s *Server) userInfos(login string, infoType storage.InfoType) ([]storage.InfoMeta, []storage.Info, error) {
+	all, err := s.Storage.GetAllData(login, infoType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while getting data from database: %w", err)
+	}
+	var (
+		metas []storage.InfoMeta
+		infos []storage.Info
+	)
+	for _, own := range all {
+		info, err := s.decryptInfo(own.Meta.Type, own.Data)
+		if err != nil {
+			log.Printf("skipping %s %q of %s: %v", own.Meta.Type, own.Meta.Name, login, err)
+			continue
+		}
+		metas = append(metas, own.Meta)
+		infos = append(infos, info)
+	}
+	return metas, infos, nil
 }
 
 func (s *Server) Route() *echo.Echo {
@@ -208,12 +623,63 @@ func (s *Server) Route() *echo.Echo {
 
 	e.POST("/user/auth/register/", s.RegistHandler)
 	e.POST("/user/auth/login/", s.AuthHandler)
+	e.POST("/user/auth/login/totp/", s.TOTPAuthHandler)
+	e.POST("/user/auth/recovery/", s.RecoveryVaultKeyHandler)
+	e.POST("/user/auth/recovery/reset/", s.ResetPasswordHandler)
+	e.POST("/links/:token", s.OpenLinkHandler)
 
-	logged := e.Group("/user", echojwt.WithConfig(echojwt.Config{SigningKey: []byte(s.jwtSecret)}))
+	logged := e.Group("/user", echojwt.WithConfig(echojwt.Config{SigningKey: []byte(s.jwtSecret)}), s.checkSession)
 	logged.POST("/add-data/", s.PostSaveDataHandler)
 	//logged.POST("/get-data-by-type/", s.GetDataByTypeHandler)
-	//logged.GET("/get-users-data/", s.GetAllUsersDataHandler)
+	logged.GET("/get-users-data/", s.GetAllUsersDataHandler)
+	logged.GET("/logins/", s.MatchLoginsHandler)
+	logged.GET("/due/", s.GetDueHandler)
 	logged.POST("/get-data-by-name/", s.GetDataByNameHandler)
+	logged.POST("/update-data/", s.UpdateDataHandler)
+	logged.POST("/delete-data/", s.DeleteDataHandler)
+	logged.POST("/share/", s.ShareDataHandler)
+	logged.POST("/revoke-share/", s.RevokeShareHandler)
+	logged.POST("/get-shares/", s.GetSharesHandler)
+	logged.GET("/shared-with-me/", s.GetSharedWithMeHandler)
+	logged.POST("/share-sealed/", s.ShareSealedHandler)
+	logged.POST("/get-sealed-share/", s.GetSealedShareHandler)
+	logged.POST("/links/", s.CreateLinkHandler)
+	logged.GET("/emergency/contacts/", s.GetEmergencyContactsHandler)
+	logged.POST("/emergency/contacts/", s.AddEmergencyContactHandler)
+	logged.POST("/emergency/contacts/delete/", s.RemoveEmergencyContactHandler)
+	logged.POST("/emergency/reject/", s.RejectEmergencyHandler)
+	logged.GET("/emergency/trusted-by/", s.GetEmergencyGrantorsHandler)
+	logged.POST("/emergency/request/", s.RequestEmergencyHandler)
+	logged.GET("/emergency/vault/", s.GetEmergencyVaultHandler)
+	logged.POST("/emergency/get-data/", s.GetEmergencyDataHandler)
+	logged.GET("/orgs/", s.GetOrgsHandler)
+	logged.POST("/orgs/", s.CreateOrgHandler)
+	logged.POST("/orgs/delete/", s.DeleteOrgHandler)
+	logged.GET("/orgs/members/", s.GetMembersHandler)
+	logged.POST("/orgs/invite/", s.InviteMemberHandler)
+	logged.POST("/orgs/set-role/", s.SetMemberRoleHandler)
+	logged.POST("/orgs/remove-member/", s.RemoveMemberHandler)
+	logged.GET("/orgs/invitations/", s.GetInvitationsHandler)
+	logged.POST("/orgs/accept/", s.AcceptInvitationHandler)
+	logged.POST("/orgs/decline/", s.DeclineInvitationHandler)
+	logged.GET("/orgs/collections/", s.GetCollectionsHandler)
+	logged.POST("/orgs/collections/", s.CreateCollectionHandler)
+	logged.POST("/orgs/collections/delete/", s.DeleteCollectionHandler)
+	logged.GET("/orgs/collections/data/", s.GetCollectionDataHandler)
+	logged.GET("/templates/", s.GetTemplatesHandler)
+	logged.POST("/templates/", s.SaveTemplateHandler)
+	logged.POST("/templates/delete/", s.DeleteTemplateHandler)
+	logged.GET("/audit/", s.GetAuditHandler)
+	logged.POST("/totp/enroll/", s.TOTPEnrollHandler)
+	logged.POST("/totp/confirm/", s.TOTPConfirmHandler)
+	logged.POST("/change-password/", s.ChangePasswordHandler)
+	logged.GET("/vault-key/", s.GetVaultKeyHandler)
+	logged.POST("/vault-key/", s.SetVaultKeyHandler)
+	logged.POST("/recovery-key/", s.SetRecoveryKeyHandler)
+	logged.GET("/keys/", s.GetKeyPairHandler)
+	logged.POST("/keys/", s.SetKeyPairHandler)
+	logged.GET("/public-key/", s.GetPublicKeyHandler)
+	logged.POST("/delete-account/", s.DeleteAccountHandler)
 
 	return e
 }
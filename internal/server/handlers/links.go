@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/links"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// linkActor is recorded as actor of the owner's audit entries about opened links
+const linkActor = "anonymous link holder"
+
+type linkRequest struct {
+	Name string           `json:"name"`
+	Type storage.InfoType `json:"type"`
+	// Data is the secret encrypted on the client, the key is only in the link's fragment
+	Data []byte `json:"data"`
+	links.Params
+}
+
+type linkResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	MaxViews  int       `json:"max_views"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateLinkHandler stores encrypted secret behind a random token which can be opened without an account
+func (s *Server) CreateLinkHandler(c echo.Context) error {
+	var req linkRequest
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return nil
+	}
+	defer c.Request().Body.Close()
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return nil
+	}
+	meta := storage.InfoMeta{Name: req.Name, Type: req.Type, Login: s.Auth.GetUserLogin(c.Request())}
+	success := false
+	defer func() { s.recordDataAudit(c.Request(), audit.ActionCreateLink, meta, success) }()
+	if len(req.Data) == 0 {
+		http.Error(c.Response().Writer, "data is required", http.StatusBadRequest)
+		return nil
+	}
+	params, err := req.Params.Normalize()
+	if err != nil {
+		http.Error(c.Response().Writer, "wrong max views or expiry", http.StatusBadRequest)
+		return nil
+	}
+	token, tokenHash, err := links.NewToken()
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot create link", http.StatusInternalServerError)
+		log.Println("error while creating link token:", err)
+		return nil
+	}
+	link := storage.Link{
+		Owner:     meta.Login,
+		Name:      req.Name,
+		Type:      req.Type,
+		Data:      req.Data,
+		ViewsLeft: params.MaxViews,
+		ExpiresAt: time.Now().Add(params.TTL()).UTC().Truncate(time.Microsecond),
+	}
+	if err = s.Storage.CreateLink(tokenHash, link); err != nil {
+		http.Error(c.Response().Writer, "cannot create link", http.StatusInternalServerError)
+		log.Println("error while creating link:", err)
+		return nil
+	}
+	success = true
+	return writeJSON(c, http.StatusOK, linkResponse{
+		Token:     token,
+		URL:       c.Scheme() + "://" + c.Request().Host + "/links/" + token,
+		MaxViews:  params.MaxViews,
+		ExpiresAt: link.ExpiresAt,
+	})
+}
+
+// OpenLinkHandler returns encrypted secret behind the link and uses one of its views, no authentication is needed.
+// It is POST so that link previews and prefetching don't burn the views.
+func (s *Server) OpenLinkHandler(c echo.Context) error {
+	link, err := s.Storage.ConsumeLink(links.HashToken(c.Param("token")))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "link not found or expired", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot open link", http.StatusInternalServerError)
+		log.Println("error while opening link:", err)
+		return nil
+	}
+	entry := newAuditEntry(c.Request(), link.Owner, audit.ActionOpenLink, true)
+	entry.Actor = linkActor
+	entry.SecretType = string(link.Type)
+	entry.SecretName = link.Name
+	s.Audit.Record(entry)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return writeJSON(c, http.StatusOK, link)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// matchedLogin is the user's login entry used on the requested URL
+type matchedLogin struct {
+	Name string `json:"name"`
+	storage.InfoLoginPass
+}
+
+type loginsResponse struct {
+	Logins []matchedLogin `json:"logins"`
+}
+
+// MatchLoginsHandler returns the user's login entries with URIs matching ?url= param, it is used for autofill
+func (s *Server) MatchLoginsHandler(c echo.Context) error {
+	target, err := storage.ParseLoginURL(c.QueryParam("url"))
+	if err != nil {
+		http.Error(c.Response().Writer, "wrong url", http.StatusBadRequest)
+		return nil
+	}
+	metas, infos, err := s.userInfos(s.Auth.GetUserLogin(c.Request()), storage.LoginPassword)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get data", http.StatusInternalServerError)
+		log.Println("error while getting logins:", err)
+		return nil
+	}
+	resp := loginsResponse{Logins: []matchedLogin{}}
+	for i, data := range infos {
+		info, ok := data.(*storage.InfoLoginPass)
+		if !ok || !info.MatchesURL(target) {
+			continue
+		}
+		s.recordDataAudit(c.Request(), audit.ActionRead, metas[i], true)
+		resp.Logins = append(resp.Logins, matchedLogin{Name: metas[i].Name, InfoLoginPass: *info})
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+type orgRequest struct {
+	Org   string    `json:"org"`
+	Name  string    `json:"name,omitempty"`
+	Login string    `json:"login,omitempty"`
+	Role  orgs.Role `json:"role,omitempty"`
+}
+
+type orgsResponse struct {
+	Orgs []orgs.Membership `json:"orgs"`
+}
+
+type membersResponse struct {
+	Members []orgs.Member `json:"members"`
+}
+
+type invitationsResponse struct {
+	Invitations []orgs.Invitation `json:"invitations"`
+}
+
+type collectionsResponse struct {
+	Collections []string `json:"collections"`
+}
+
+type collectionDataResponse struct {
+	Infos []storage.InfoMeta `json:"infos"`
+}
+
+// authorizeData checks that the user may access secret of organization's collection,
+// personal and shared secrets are checked by storage. On failure the error response is already written
+func (s *Server) authorizeData(c echo.Context, meta storage.InfoMeta, write bool) bool {
+	if !meta.InCollection() {
+		return true
+	}
+	if meta.Owner != "" {
+		http.Error(c.Response().Writer, "secret can't be both shared and in collection", http.StatusBadRequest)
+		return false
+	}
+	role, ok := s.memberRole(c, meta.Org, meta.Login)
+	if !ok {
+		return false
+	}
+	if write && !role.CanWrite() {
+		http.Error(c.Response().Writer, "no write access", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// memberRole returns role of the user in organization, on failure the error response is already written
+func (s *Server) memberRole(c echo.Context, org string, login string) (orgs.Role, bool) {
+	role, err := s.Storage.GetMemberRole(org, login)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get member role", http.StatusInternalServerError)
+		log.Println("error while getting member role:", err)
+		return "", false
+	}
+	if !role.CanRead() {
+		http.Error(c.Response().Writer, "not a member of organization", http.StatusForbidden)
+		return "", false
+	}
+	return role, true
+}
+
+// CreateOrgHandler creates organization with the user as its owner
+func (s *Server) CreateOrgHandler(c echo.Context) error {
+	req, ok := readOrgRequest(c)
+	if !ok {
+		return nil
+	}
+	if req.Org == "" {
+		http.Error(c.Response().Writer, "organization name is empty", http.StatusBadRequest)
+		return nil
+	}
+	err := s.Storage.CreateOrg(req.Org, s.Auth.GetUserLogin(c.Request()))
+	if errors.Is(err, orgs.ErrOrgExists) {
+		http.Error(c.Response().Writer, "organization already exists", http.StatusConflict)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot create organization", http.StatusInternalServerError)
+		log.Println("error while creating organization:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// GetOrgsHandler returns organizations the user is a member of
+func (s *Server) GetOrgsHandler(c echo.Context) error {
+	memberships, err := s.Storage.GetUserOrgs(s.Auth.GetUserLogin(c.Request()))
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get organizations", http.StatusInternalServerError)
+		log.Println("error while getting organizations:", err)
+		return nil
+	}
+	resp := orgsResponse{Orgs: []orgs.Membership{}}
+	if memberships != nil {
+		resp.Orgs = memberships
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+func (s *Server) DeleteOrgHandler(c echo.Context) error {
+	req, ok := readOrgRequest(c)
+	if !ok {
+		return nil
+	}
+	role, ok := s.memberRole(c, req.Org, s.Auth.GetUserLogin(c.Request()))
+	if !ok {
+		return nil
+	}
+	if !role.CanDelete() {
+		http.Error(c.Response().Writer, "only owner can delete organization", http.StatusForbidden)
+		return nil
+	}
+	if err := s.Storage.DeleteOrg(req.Org); err != nil {
+		http.Error(c.Response().Writer, "cannot delete organization", http.StatusInternalServerError)
+		log.Println("error while deleting organization:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// GetMembersHandler returns members of organization from ?org= param
+func (s *Server) GetMembersHandler(c echo.Context) error {
+	org := c.QueryParam("org")
+	if _, ok := s.memberRole(c, org, s.Auth.GetUserLogin(c.Request())); !ok {
+		return nil
+	}
+	members, err := s.Storage.GetMembers(org)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get members", http.StatusInternalServerError)
+		log.Println("error while getting members:", err)
+		return nil
+	}
+	resp := membersResponse{Members: []orgs.Member{}}
+	if members != nil {
+		resp.Members = members
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+// InviteMemberHandler invites user to organization, admins can invite members and read-only members only
+func (s *Server) InviteMemberHandler(c echo.Context) error {
+	req, ok := readOrgRequest(c)
+	if !ok {
+		return nil
+	}
+	login := s.Auth.GetUserLogin(c.Request())
+	role, ok := s.memberRole(c, req.Org, login)
+	if !ok {
+		return nil
+	}
+	if !req.Role.Valid() {
+		http.Error(c.Response().Writer, "wrong role", http.StatusBadRequest)
+		return nil
+	}
+	if !role.CanAssign(req.Role) {
+		http.Error(c.Response().Writer, "cannot invite with this role", http.StatusForbidden)
+		return nil
+	}
+	err := s.Storage.InviteMember(req.Org, req.Login, req.Role, login)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		http.Error(c.Response().Writer, "no such user", http.StatusNotFound)
+		return nil
+	}
+	if errors.Is(err, orgs.ErrAlreadyMember) {
+		http.Error(c.Response().Writer, "user is already a member", http.StatusConflict)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot invite member", http.StatusInternalServerError)
+		log.Println("error while inviting member:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// GetInvitationsHandler returns the user's pending invitations
+func (s *Server) GetInvitationsHandler(c echo.Context) error {
+	invitations, err := s.Storage.GetInvitations(s.Auth.GetUserLogin(c.Request()))
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get invitations", http.StatusInternalServerError)
+		log.Println("error while getting invitations:", err)
+		return nil
+	}
+	resp := invitationsResponse{Invitations: []orgs.Invitation{}}
+	if invitations != nil {
+		resp.Invitations = invitations
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+func (s *Server) AcceptInvitationHandler(c echo.Context) error {
+	return s.answerInvitation(c, s.Storage.AcceptInvitation)
+}
+
+func (s *Server) DeclineInvitationHandler(c echo.Context) error {
+	return s.answerInvitation(c, s.Storage.DeclineInvitation)
+}
+
+func (s *Server) answerInvitation(c echo.Context, answer func(org string, login string) error) error {
+	req, ok := readOrgRequest(c)
+	if !ok {
+		return nil
+	}
+	err := answer(req.Org, s.Auth.GetUserLogin(c.Request()))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no invitation found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot answer invitation", http.StatusInternalServerError)
+		log.Println("error while answering invitation:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// SetMemberRoleHandler changes member's role, admins can't change roles of owners and other admins
+func (s *Server) SetMemberRoleHandler(c echo.Context) error {
+	req, ok := readOrgRequest(c)
+	if !ok {
+		return nil
+	}
+	role, ok := s.memberRole(c, req.Org, s.Auth.GetUserLogin(c.Request()))
+	if !ok {
+		return nil
+	}
+	if !req.Role.Valid() {
+		http.Error(c.Response().Writer, "wrong role", http.StatusBadRequest)
+		return nil
+	}
+	current, ok := s.targetRole(c, role, req)
+	if !ok {
+		return nil
+	}
+	if !role.CanAssign(req.Role) {
+		http.Error(c.Response().Writer, "cannot assign this role", http.StatusForbidden)
+		return nil
+	}
+	if current == orgs.RoleOwner && req.Role != orgs.RoleOwner && !s.hasOtherOwner(c, req.Org) {
+		return nil
+	}
+	if err := s.Storage.SetMemberRole(req.Org, req.Login, req.Role); err != nil {
+		http.Error(c.Response().Writer, "cannot set member role", http.StatusInternalServerError)
+		log.Println("error while setting member role:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// RemoveMemberHandler removes member from organization, every member can leave organization by removing themself
+func (s *Server) RemoveMemberHandler(c echo.Context) error {
+	req, ok := readOrgRequest(c)
+	if !ok {
+		return nil
+	}
+	login := s.Auth.GetUserLogin(c.Request())
+	role, ok := s.memberRole(c, req.Org, login)
+	if !ok {
+		return nil
+	}
+	current := role
+	if req.Login != login {
+		if current, ok = s.targetRole(c, role, req); !ok {
+			return nil
+		}
+	}
+	if current == orgs.RoleOwner && !s.hasOtherOwner(c, req.Org) {
+		return nil
+	}
+	if err := s.Storage.RemoveMember(req.Org, req.Login); err != nil {
+		http.Error(c.Response().Writer, "cannot remove member", http.StatusInternalServerError)
+		log.Println("error while removing member:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// targetRole returns role of the member the request is about if the user may manage the member,
+// on failure the error response is already written
+func (s *Server) targetRole(c echo.Context, role orgs.Role, req orgRequest) (orgs.Role, bool) {
+	current, err := s.Storage.GetMemberRole(req.Org, req.Login)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get member role", http.StatusInternalServerError)
+		log.Println("error while getting member role:", err)
+		return "", false
+	}
+	if current == "" {
+		http.Error(c.Response().Writer, "no such member", http.StatusNotFound)
+		return "", false
+	}
+	if !role.CanAssign(current) {
+		http.Error(c.Response().Writer, "cannot manage this member", http.StatusForbidden)
+		return "", false
+	}
+	return current, true
+}
+
+// hasOtherOwner reports whether organization has more than one owner, otherwise the error response is already written
+func (s *Server) hasOtherOwner(c echo.Context, org string) bool {
+	members, err := s.Storage.GetMembers(org)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get members", http.StatusInternalServerError)
+		log.Println("error while getting members:", err)
+		return false
+	}
+	owners := 0
+	for _, member := range members {
+		if member.Role == orgs.RoleOwner {
+			owners++
+		}
+	}
+	if owners < 2 {
+		http.Error(c.Response().Writer, orgs.ErrLastOwner.Error(), http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+func (s *Server) CreateCollectionHandler(c echo.Context) error {
+	return s.manageCollection(c, s.Storage.CreateCollection)
+}
+
+// DeleteCollectionHandler deletes collection with all of its secrets
+func (s *Server) DeleteCollectionHandler(c echo.Context) error {
+	return s.manageCollection(c, s.Storage.DeleteCollection)
+}
+
+func (s *Server) manageCollection(c echo.Context, manage func(org string, name string) error) error {
+	req, ok := readOrgRequest(c)
+	if !ok {
+		return nil
+	}
+	role, ok := s.memberRole(c, req.Org, s.Auth.GetUserLogin(c.Request()))
+	if !ok {
+		return nil
+	}
+	if !role.CanManage() {
+		http.Error(c.Response().Writer, "only admins can manage collections", http.StatusForbidden)
+		return nil
+	}
+	if req.Name == "" {
+		http.Error(c.Response().Writer, "collection name is empty", http.StatusBadRequest)
+		return nil
+	}
+	err := manage(req.Org, req.Name)
+	if errors.Is(err, storage.ErrInvalidData) {
+		http.Error(c.Response().Writer, "collection already exists", http.StatusConflict)
+		return nil
+	}
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no collection found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot manage collection", http.StatusInternalServerError)
+		log.Println("error while managing collection:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// GetCollectionsHandler returns collections of organization from ?org= param
+func (s *Server) GetCollectionsHandler(c echo.Context) error {
+	org := c.QueryParam("org")
+	if _, ok := s.memberRole(c, org, s.Auth.GetUserLogin(c.Request())); !ok {
+		return nil
+	}
+	collections, err := s.Storage.GetCollections(org)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get collections", http.StatusInternalServerError)
+		log.Println("error while getting collections:", err)
+		return nil
+	}
+	resp := collectionsResponse{Collections: []string{}}
+	if collections != nil {
+		resp.Collections = collections
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+// GetCollectionDataHandler returns names and types of secrets in collection from ?org= and ?collection= params
+func (s *Server) GetCollectionDataHandler(c echo.Context) error {
+	org := c.QueryParam("org")
+	if _, ok := s.memberRole(c, org, s.Auth.GetUserLogin(c.Request())); !ok {
+		return nil
+	}
+	infos, err := s.Storage.GetCollectionData(org, c.QueryParam("collection"))
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get collection data", http.StatusInternalServerError)
+		log.Println("error while getting collection data:", err)
+		return nil
+	}
+	resp := collectionDataResponse{Infos: []storage.InfoMeta{}}
+	if infos != nil {
+		resp.Infos = infos
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+// readOrgRequest reads organization request from request body, on failure the error response is already written
+func readOrgRequest(c echo.Context) (orgRequest, bool) {
+	var req orgRequest
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return req, false
+	}
+	defer c.Request().Body.Close()
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return req, false
+	}
+	return req, true
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+type shareRequest struct {
+	Name      string           `json:"name"`
+	Type      storage.InfoType `json:"type"`
+	Recipient string           `json:"recipient"`
+	Access    storage.Access   `json:"access,omitempty"`
+}
+
+type sharesResponse struct {
+	Shares []storage.Share `json:"shares"`
+}
+
+type sharedWithMeResponse struct {
+	Infos []storage.SharedInfo `json:"infos"`
+}
+
+// ShareDataHandler grants another user access to the user's secret, read access is granted by default
+func (s *Server) ShareDataHandler(c echo.Context) error {
+	req, ok := readShareRequest(c)
+	if !ok {
+		return nil
+	}
+	if req.Access == "" {
+		req.Access = storage.AccessRead
+	}
+	if !req.Access.Valid() {
+		http.Error(c.Response().Writer, "wrong access", http.StatusBadRequest)
+		return nil
+	}
+	meta := storage.InfoMeta{Name: req.Name, Type: req.Type, Login: s.Auth.GetUserLogin(c.Request())}
+	success := false
+	defer func() { s.recordShareAudit(c.Request(), audit.ActionShare, meta, req.Recipient, success) }()
+	if req.Recipient == meta.Login {
+		http.Error(c.Response().Writer, "cannot share secret with yourself", http.StatusBadRequest)
+		return nil
+	}
+	err := s.Storage.ShareData(meta, req.Recipient, req.Access)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		http.Error(c.Response().Writer, "no such user", http.StatusNotFound)
+		return nil
+	}
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot share data", http.StatusInternalServerError)
+		log.Println("error while sharing data:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) RevokeShareHandler(c echo.Context) error {
+	req, ok := readShareRequest(c)
+	if !ok {
+		return nil
+	}
+	meta := storage.InfoMeta{Name: req.Name, Type: req.Type, Login: s.Auth.GetUserLogin(c.Request())}
+	success := false
+	defer func() { s.recordShareAudit(c.Request(), audit.ActionRevokeShare, meta, req.Recipient, success) }()
+	err := s.Storage.RevokeShare(meta, req.Recipient)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no share found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot revoke share", http.StatusInternalServerError)
+		log.Println("error while revoking share:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// GetSharesHandler returns users the user's secret is shared with
+func (s *Server) GetSharesHandler(c echo.Context) error {
+	req, ok := readShareRequest(c)
+	if !ok {
+		return nil
+	}
+	meta := storage.InfoMeta{Name: req.Name, Type: req.Type, Login: s.Auth.GetUserLogin(c.Request())}
+	shares, err := s.Storage.GetShares(meta)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get shares", http.StatusInternalServerError)
+		log.Println("error while getting shares:", err)
+		return nil
+	}
+	resp := sharesResponse{Shares: []storage.Share{}}
+	if shares != nil {
+		resp.Shares = shares
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+// GetSharedWithMeHandler returns secrets of other users shared with the user
+func (s *Server) GetSharedWithMeHandler(c echo.Context) error {
+	infos, err := s.Storage.GetSharedWithMe(s.Auth.GetUserLogin(c.Request()))
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get shared data", http.StatusInternalServerError)
+		log.Println("error while getting shared data:", err)
+		return nil
+	}
+	resp := sharedWithMeResponse{Infos: []storage.SharedInfo{}}
+	if infos != nil {
+		resp.Infos = infos
+	}
+	return writeJSON(c, http.StatusOK, resp)
+}
+
+// ShareSealedHandler stores copy of the user's secret encrypted on the client for the recipient's public key
+func (s *Server) ShareSealedHandler(c echo.Context) error {
+	var share storage.SealedShare
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return nil
+	}
+	defer c.Request().Body.Close()
+	if err := json.NewDecoder(c.Request().Body).Decode(&share); err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return nil
+	}
+	share.Owner = s.Auth.GetUserLogin(c.Request())
+	meta := storage.InfoMeta{Name: share.Name, Type: share.Type, Login: share.Owner}
+	success := false
+	defer func() { s.recordShareAudit(c.Request(), audit.ActionShare, meta, share.Recipient, success) }()
+	if share.Recipient == share.Owner {
+		http.Error(c.Response().Writer, "cannot share secret with yourself", http.StatusBadRequest)
+		return nil
+	}
+	if len(share.SealedKey) == 0 || len(share.Data) == 0 {
+		http.Error(c.Response().Writer, "sealed key and data are required", http.StatusBadRequest)
+		return nil
+	}
+	err := s.Storage.SaveSealedShare(share)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		http.Error(c.Response().Writer, "no such user", http.StatusNotFound)
+		return nil
+	}
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot share data", http.StatusInternalServerError)
+		log.Println("error while saving sealed share:", err)
+		return nil
+	}
+	success = true
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// GetSealedShareHandler returns copy of another user's secret sealed for the user
+func (s *Server) GetSealedShareHandler(c echo.Context) error {
+	success := false
+	var meta storage.InfoMeta
+	defer func() { s.recordDataAudit(c.Request(), audit.ActionRead, meta, success) }()
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return nil
+	}
+	defer c.Request().Body.Close()
+	if err := json.NewDecoder(c.Request().Body).Decode(&meta); err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return nil
+	}
+	meta.Login = s.Auth.GetUserLogin(c.Request())
+	share, err := s.Storage.GetSealedShare(meta)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no data found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get shared data", http.StatusInternalServerError)
+		log.Println("error while getting sealed share:", err)
+		return nil
+	}
+	success = true
+	return writeJSON(c, http.StatusOK, share)
+}
+
+// readShareRequest reads share request from request body, on failure the error response is already written
+func readShareRequest(c echo.Context) (shareRequest, bool) {
+	var req shareRequest
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return req, false
+	}
+	defer c.Request().Body.Close()
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return req, false
+	}
+	return req, true
+}
+
+func (s *Server) recordShareAudit(r *http.Request, action audit.Action, meta storage.InfoMeta, recipient string, success bool) {
+	entry := newAuditEntry(r, meta.Login, action, success)
+	entry.SecretType = string(meta.Type)
+	entry.SecretName = meta.Name
+	entry.Target = recipient
+	s.Audit.Record(entry)
+}
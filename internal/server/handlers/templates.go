@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/labstack/echo/v4"
+)
+
+type templatesResponse struct {
+	Templates []storage.Template `json:"templates"`
+}
+
+// GetTemplatesHandler returns the user's templates of secrets
+func (s *Server) GetTemplatesHandler(c echo.Context) error {
+	templates, err := s.Storage.GetTemplates(s.Auth.GetUserLogin(c.Request()))
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot get templates", http.StatusInternalServerError)
+		log.Println("error while getting templates:", err)
+		return nil
+	}
+	if templates == nil {
+		templates = []storage.Template{}
+	}
+	return writeJSON(c, http.StatusOK, templatesResponse{Templates: templates})
+}
+
+// SaveTemplateHandler adds the user's template or replaces the one with the same name
+func (s *Server) SaveTemplateHandler(c echo.Context) error {
+	template, ok := readTemplate(c)
+	if !ok {
+		return nil
+	}
+	if err := template.Validate(); err != nil {
+		log.Println("invalid template:", err)
+		var fieldErrs storage.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			return writeJSON(c, http.StatusBadRequest, invalidDataResponse{Error: err.Error(), Fields: fieldErrs})
+		}
+		http.Error(c.Response().Writer, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+	err := s.Storage.SaveTemplate(s.Auth.GetUserLogin(c.Request()), template)
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot save template", http.StatusInternalServerError)
+		log.Println("error while saving template:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// DeleteTemplateHandler deletes the user's template by name, secrets created from it are kept
+func (s *Server) DeleteTemplateHandler(c echo.Context) error {
+	template, ok := readTemplate(c)
+	if !ok {
+		return nil
+	}
+	err := s.Storage.DeleteTemplate(s.Auth.GetUserLogin(c.Request()), template.Name)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		http.Error(c.Response().Writer, "no such template", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(c.Response().Writer, "cannot delete template", http.StatusInternalServerError)
+		log.Println("error while deleting template:", err)
+		return nil
+	}
+	c.Response().Writer.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func readTemplate(c echo.Context) (storage.Template, bool) {
+	var template storage.Template
+	if c.Request().Header.Get("Content-Type") != contentTypeJSON {
+		http.Error(c.Response().Writer, "wrong content type", http.StatusBadRequest)
+		log.Println("wrong content type:", c.Request().Header.Get("Content-Type"))
+		return template, false
+	}
+	defer c.Request().Body.Close()
+	if err := json.NewDecoder(c.Request().Body).Decode(&template); err != nil {
+		http.Error(c.Response().Writer, "cannot unmarshal request body", http.StatusBadRequest)
+		log.Println("error while unmarshalling request body:", err)
+		return template, false
+	}
+	return template, true
+}
@@ -4,23 +4,39 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/bruteforce"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/types"
 )
 
-func RegistService(r *http.Request, auth types.Authorization) (int, string, error) {
+func RegistService(r *http.Request, auth types.Authorization, guard *bruteforce.Guard) (int, string, error) {
 	var (
 		userData types.UserData
 		token    string
 	)
+	ipKey := bruteforce.IPKey(bruteforce.ClientIP(r))
+	if status, err := checkAttempts(guard, ipKey); err != nil {
+		return status, token, err
+	}
 	if err := json.NewDecoder(r.Body).Decode(&userData); err != nil {
 		return http.StatusBadRequest, token, fmt.Errorf("can't decode body %w", err)
 	}
 	if err := auth.CheckData(userData); err != nil {
 		return http.StatusBadRequest, token, fmt.Errorf("no data provided: %w", err)
 	}
+	if err := auth.CheckBreached(userData.Password); errors.Is(err, types.ErrBreached) {
+		return http.StatusBadRequest, token, err
+	} else if err != nil {
+		return http.StatusInternalServerError, token, fmt.Errorf("RegistHandler: %w", err)
+	}
 	user, err := auth.RegisterUser(userData)
+	if err != nil {
+		// failed registrations reveal existing logins, so they are limited per IP
+		failAttempt(guard, ipKey)
+	}
 	if err != nil && !errors.Is(err, types.ErrInvalidData) {
 		return http.StatusLoopDetected, token, fmt.Errorf("RegistHandler: %w", err)
 	}
@@ -35,7 +51,7 @@ func RegistService(r *http.Request, auth types.Authorization) (int, string, erro
 	return http.StatusOK, token, nil
 }
 
-func AuthService(r *http.Request, auth types.Authorization) (int, string, error) {
+func AuthService(r *http.Request, auth types.Authorization, guard *bruteforce.Guard) (int, string, error) {
 	var (
 		userData types.UserData
 		token    string
@@ -46,13 +62,30 @@ func AuthService(r *http.Request, auth types.Authorization) (int, string, error)
 	if err := auth.CheckData(userData); err != nil {
 		return http.StatusBadRequest, token, err
 	}
+	loginKey := bruteforce.LoginKey(userData.Login)
+	ipKey := bruteforce.IPKey(bruteforce.ClientIP(r))
+	if status, err := checkAttempts(guard, loginKey, ipKey); err != nil {
+		return status, token, err
+	}
 	user, err := auth.LoginUser(userData)
 	if err != nil && !errors.Is(err, types.ErrInvalidData) {
 		return http.StatusInternalServerError, token, err
 	}
 	if errors.Is(err, types.ErrInvalidData) {
+		failAttempt(guard, loginKey, ipKey)
 		return http.StatusUnauthorized, token, err
 	}
+	// failures of the account are forgotten only after the second factor is passed
+	if user.TOTPEnabled {
+		token, err = auth.GenerateChallengeToken(user)
+		if err != nil {
+			return http.StatusInternalServerError, token, err
+		}
+		return http.StatusAccepted, token, nil
+	}
+	if err = guard.Success(loginKey); err != nil {
+		log.Println(err)
+	}
 	token, err = auth.GenerateToken(user)
 	if err != nil {
 		return http.StatusInternalServerError, token, err
@@ -60,3 +93,302 @@ func AuthService(r *http.Request, auth types.Authorization) (int, string, error)
 
 	return http.StatusOK, token, err
 }
+
+// TOTPAuthService exchanges challenge token and TOTP or backup code for JWT
+func TOTPAuthService(r *http.Request, auth types.Authorization, guard *bruteforce.Guard) (int, string, error) {
+	var (
+		totpData types.TOTPLoginData
+		token    string
+	)
+	if err := json.NewDecoder(r.Body).Decode(&totpData); err != nil {
+		return http.StatusBadRequest, token, err
+	}
+	if totpData.ChallengeToken == "" || totpData.Code == "" {
+		return http.StatusBadRequest, token, errors.New("error: challenge token or code is empty")
+	}
+	// codes are guessed against the account, so its counter is checked whatever IP they come from
+	keys := []string{bruteforce.IPKey(bruteforce.ClientIP(r))}
+	loginKey := ""
+	if login := auth.GetChallengeLogin(totpData.ChallengeToken); login != "" {
+		loginKey = bruteforce.LoginKey(login)
+		keys = append(keys, loginKey)
+	}
+	if status, err := checkAttempts(guard, keys...); err != nil {
+		return status, token, err
+	}
+	user, err := auth.LoginTOTP(totpData)
+	if err != nil && !errors.Is(err, types.ErrInvalidData) {
+		return http.StatusInternalServerError, token, err
+	}
+	if errors.Is(err, types.ErrInvalidData) {
+		failAttempt(guard, keys...)
+		return http.StatusUnauthorized, token, err
+	}
+	if err = guard.Success(loginKey); err != nil {
+		log.Println(err)
+	}
+	token, err = auth.GenerateToken(user)
+	if err != nil {
+		return http.StatusInternalServerError, token, err
+	}
+
+	return http.StatusOK, token, nil
+}
+
+func checkAttempts(guard *bruteforce.Guard, keys ...string) (int, error) {
+	err := guard.Check(keys...)
+	var blocked *bruteforce.BlockedError
+	if errors.As(err, &blocked) {
+		return http.StatusTooManyRequests, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func failAttempt(guard *bruteforce.Guard, keys ...string) {
+	if err := guard.Fail(keys...); err != nil {
+		log.Println(err)
+	}
+}
+
+func TOTPEnrollService(r *http.Request, auth types.Authorization) (int, types.TOTPEnrollment, error) {
+	enrollment, err := auth.EnrollTOTP(auth.GetUserLogin(r))
+	if errors.Is(err, types.ErrTOTPEnabled) {
+		return http.StatusConflict, enrollment, err
+	}
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusUnauthorized, enrollment, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, enrollment, err
+	}
+
+	return http.StatusOK, enrollment, nil
+}
+
+func TOTPConfirmService(r *http.Request, auth types.Authorization) (int, error) {
+	var confirmData types.TOTPConfirmData
+	if err := json.NewDecoder(r.Body).Decode(&confirmData); err != nil {
+		return http.StatusBadRequest, err
+	}
+	err := auth.ConfirmTOTP(auth.GetUserLogin(r), confirmData.Code)
+	if errors.Is(err, types.ErrTOTPEnabled) {
+		return http.StatusConflict, err
+	}
+	if errors.Is(err, types.ErrTOTPNotSet) {
+		return http.StatusBadRequest, err
+	}
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusUnauthorized, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// ChangePasswordService changes password of the request's user and revokes the user's other sessions
+func ChangePasswordService(r *http.Request, auth types.Authorization) (int, error) {
+	var passwordData types.ChangePasswordData
+	if err := json.NewDecoder(r.Body).Decode(&passwordData); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if passwordData.OldPassword == "" || passwordData.NewPassword == "" {
+		return http.StatusBadRequest, errors.New("error: password is empty")
+	}
+	err := auth.ChangePassword(auth.GetUserLogin(r), auth.GetSessionID(r), passwordData)
+	if errors.Is(err, types.ErrVaultKeyReq) {
+		return http.StatusBadRequest, err
+	}
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusUnauthorized, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func GetVaultKeyService(r *http.Request, auth types.Authorization) (int, types.VaultKeyData, error) {
+	vaultKey, err := auth.GetVaultKey(auth.GetUserLogin(r))
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusUnauthorized, types.VaultKeyData{}, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, types.VaultKeyData{}, err
+	}
+	if len(vaultKey) == 0 {
+		return http.StatusNoContent, types.VaultKeyData{}, nil
+	}
+
+	return http.StatusOK, types.VaultKeyData{VaultKey: vaultKey}, nil
+}
+
+func SetVaultKeyService(r *http.Request, auth types.Authorization) (int, error) {
+	var vaultKeyData types.VaultKeyData
+	if err := json.NewDecoder(r.Body).Decode(&vaultKeyData); err != nil {
+		return http.StatusBadRequest, err
+	}
+	err := auth.SetVaultKey(auth.GetUserLogin(r), vaultKeyData.VaultKey)
+	if errors.Is(err, types.ErrVaultKeySet) {
+		return http.StatusConflict, err
+	}
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusBadRequest, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func GetKeyPairService(r *http.Request, auth types.Authorization) (int, types.KeyPairData, error) {
+	keyPair, err := auth.GetKeyPair(auth.GetUserLogin(r))
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusUnauthorized, types.KeyPairData{}, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, types.KeyPairData{}, err
+	}
+	if len(keyPair.PublicKey) == 0 {
+		return http.StatusNoContent, types.KeyPairData{}, nil
+	}
+
+	return http.StatusOK, keyPair, nil
+}
+
+func SetKeyPairService(r *http.Request, auth types.Authorization) (int, error) {
+	var keyPair types.KeyPairData
+	if err := json.NewDecoder(r.Body).Decode(&keyPair); err != nil {
+		return http.StatusBadRequest, err
+	}
+	err := auth.SetKeyPair(auth.GetUserLogin(r), keyPair)
+	if errors.Is(err, types.ErrKeyPairSet) {
+		return http.StatusConflict, err
+	}
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusBadRequest, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func GetPublicKeyService(r *http.Request, auth types.Authorization) (int, types.KeyPairData, error) {
+	login := r.URL.Query().Get("login")
+	if login == "" {
+		return http.StatusBadRequest, types.KeyPairData{}, errors.New("error: login is empty")
+	}
+	publicKey, err := auth.GetPublicKey(login)
+	if errors.Is(err, types.ErrNoPublicKey) {
+		return http.StatusNotFound, types.KeyPairData{}, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, types.KeyPairData{}, err
+	}
+
+	return http.StatusOK, types.KeyPairData{PublicKey: publicKey}, nil
+}
+
+func DeleteAccountService(r *http.Request, auth types.Authorization) (int, error) {
+	var deleteData types.DeleteAccountData
+	if err := json.NewDecoder(r.Body).Decode(&deleteData); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if deleteData.Password == "" {
+		return http.StatusBadRequest, errors.New("error: password is empty")
+	}
+	err := auth.DeleteAccount(auth.GetUserLogin(r), deleteData.Password)
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusUnauthorized, err
+	}
+	if errors.Is(err, orgs.ErrLastOwner) {
+		return http.StatusConflict, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func SetRecoveryKeyService(r *http.Request, auth types.Authorization) (int, error) {
+	var recovery types.RecoveryKeyData
+	if err := json.NewDecoder(r.Body).Decode(&recovery); err != nil {
+		return http.StatusBadRequest, err
+	}
+	err := auth.SetRecoveryKey(auth.GetUserLogin(r), recovery)
+	if errors.Is(err, types.ErrInvalidData) {
+		return http.StatusBadRequest, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// RecoveryVaultKeyService returns vault key wrapped with the recovery key, failed attempts are limited like logins
+func RecoveryVaultKeyService(r *http.Request, auth types.Authorization, guard *bruteforce.Guard) (int, types.VaultKeyData, error) {
+	var recoveryData types.RecoveryData
+	if err := json.NewDecoder(r.Body).Decode(&recoveryData); err != nil {
+		return http.StatusBadRequest, types.VaultKeyData{}, err
+	}
+	if recoveryData.Login == "" || recoveryData.Verifier == "" {
+		return http.StatusBadRequest, types.VaultKeyData{}, errors.New("error: login or recovery key is empty")
+	}
+	loginKey := bruteforce.LoginKey(recoveryData.Login)
+	ipKey := bruteforce.IPKey(bruteforce.ClientIP(r))
+	if status, err := checkAttempts(guard, loginKey, ipKey); err != nil {
+		return status, types.VaultKeyData{}, err
+	}
+	vaultKey, err := auth.GetRecoveryVaultKey(recoveryData)
+	if errors.Is(err, types.ErrInvalidData) {
+		failAttempt(guard, loginKey, ipKey)
+		return http.StatusUnauthorized, types.VaultKeyData{}, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, types.VaultKeyData{}, err
+	}
+
+	return http.StatusOK, types.VaultKeyData{VaultKey: vaultKey}, nil
+}
+
+// ResetPasswordService sets new password of the user who proved knowledge of the recovery key and revokes all the user's sessions
+func ResetPasswordService(r *http.Request, auth types.Authorization, guard *bruteforce.Guard) (int, error) {
+	var recoveryData types.RecoveryData
+	if err := json.NewDecoder(r.Body).Decode(&recoveryData); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := auth.CheckData(types.UserData{Login: recoveryData.Login, Password: recoveryData.NewPassword}); err != nil {
+		return http.StatusBadRequest, err
+	}
+	loginKey := bruteforce.LoginKey(recoveryData.Login)
+	ipKey := bruteforce.IPKey(bruteforce.ClientIP(r))
+	if status, err := checkAttempts(guard, loginKey, ipKey); err != nil {
+		return status, err
+	}
+	err := auth.ResetPassword(recoveryData)
+	if errors.Is(err, types.ErrInvalidData) {
+		failAttempt(guard, loginKey, ipKey)
+		return http.StatusUnauthorized, err
+	}
+	if errors.Is(err, types.ErrVaultKeyReq) {
+		return http.StatusBadRequest, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if err = guard.Success(loginKey); err != nil {
+		log.Println(err)
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ActionRegister       Action = "register"
+	ActionLogin          Action = "login"
+	ActionLoginTOTP      Action = "login_totp"
+	ActionEnrollTOTP     Action = "enroll_totp"
+	ActionChangePassword Action = "change_password"
+	ActionSetRecoveryKey Action = "set_recovery_key"
+	ActionResetPassword  Action = "reset_password"
+	ActionSave           Action = "save"
+	ActionRead           Action = "read"
+	ActionUpdate         Action = "update"
+	ActionDelete         Action = "delete"
+	ActionShare          Action = "share"
+	ActionRevokeShare    Action = "revoke_share"
+	ActionCreateLink     Action = "create_link"
+	ActionOpenLink       Action = "open_link"
+	// emergency actions are recorded in logs of both the owner and the trusted contact
+	ActionEmergencyAdd     Action = "emergency_add"
+	ActionEmergencyRemove  Action = "emergency_remove"
+	ActionEmergencyRequest Action = "emergency_request"
+	ActionEmergencyReject  Action = "emergency_reject"
+	ActionEmergencyRead    Action = "emergency_read"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+var ErrChainBroken = errors.New("error audit log hash chain is broken")
+
+type Action string
+
+// Entry is one record of user's activity, entries of a user are chained with hashes
+type Entry struct {
+	ID         int64     `json:"id"`
+	Login      string    `json:"login"`
+	Actor      string    `json:"actor"`
+	Action     Action    `json:"action"`
+	Success    bool      `json:"success"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	SecretType string    `json:"secret_type,omitempty"`
+	SecretName string    `json:"secret_name,omitempty"`
+	Target     string    `json:"target,omitempty"` // another user the action is about
+	CreatedAt  time.Time `json:"created_at"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+}
+
+// ComputeHash returns hash of the entry's fields and previous entry's hash
+func (e Entry) ComputeHash() string {
+	fields := []string{
+		e.Login,
+		e.Actor,
+		string(e.Action),
+		strconv.FormatBool(e.Success),
+		e.IP,
+		e.UserAgent,
+		e.SecretType,
+		e.SecretName,
+		e.Target,
+		e.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		e.PrevHash,
+	}
+	var b strings.Builder
+	for _, field := range fields {
+		// length prefix keeps field boundaries unambiguous
+		b.WriteString(strconv.Itoa(len(field)))
+		b.WriteByte(':')
+		b.WriteString(field)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChain checks entries ordered from the newest to the oldest
+func VerifyChain(entries []Entry) error {
+	for i, e := range entries {
+		if e.Hash != e.ComputeHash() {
+			return fmt.Errorf("%w: entry %d was modified", ErrChainBroken, e.ID)
+		}
+		if i+1 < len(entries) && e.PrevHash != entries[i+1].Hash {
+			return fmt.Errorf("%w: entry before %d is missing or modified", ErrChainBroken, e.ID)
+		}
+	}
+	return nil
+}
+
+// Store appends entries and keeps the chain, Append must set PrevHash and Hash atomically
+type Store interface {
+	AppendAudit(e Entry) (Entry, error)
+	// GetAudit returns login's entries with ID less than beforeID from the newest, zero beforeID means from the last one
+	GetAudit(login string, beforeID int64, limit int) ([]Entry, error)
+}
+
+type Logger struct {
+	store Store
+	now   func() time.Time
+}
+
+func NewLogger(store Store) *Logger {
+	return &Logger{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Record appends entry, errors are only logged so auditing never breaks a request
+func (l *Logger) Record(e Entry) {
+	if l == nil || e.Login == "" {
+		return
+	}
+	if e.Actor == "" {
+		e.Actor = e.Login
+	}
+	e.CreatedAt = l.now().UTC().Truncate(time.Microsecond)
+	if _, err := l.store.AppendAudit(e); err != nil {
+		log.Println("error while appending audit entry:", err)
+	}
+}
+
+func (l *Logger) Get(login string, beforeID int64, limit int) ([]Entry, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return l.store.GetAudit(login, beforeID, limit)
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVerifyChain tests modified, removed and reordered entries are detected
+func TestVerifyChain(t *testing.T) {
+	var entries []Entry
+	prevHash := ""
+	for i, action := range []Action{ActionRegister, ActionSave, ActionRead} {
+		e := Entry{
+			ID:        int64(i + 1),
+			Login:     "user",
+			Actor:     "user",
+			Action:    action,
+			Success:   true,
+			IP:        "127.0.0.1",
+			CreatedAt: time.Unix(1700000000+int64(i), 123456789),
+			PrevHash:  prevHash,
+		}
+		if action == ActionSave {
+			e.Target = "friend"
+		}
+		e.Hash = e.ComputeHash()
+		prevHash = e.Hash
+		// newest entries go first
+		entries = append([]Entry{e}, entries...)
+	}
+	assert.NoError(t, VerifyChain(entries))
+
+	modified := append([]Entry{}, entries...)
+	modified[1].Success = false
+	assert.ErrorIs(t, VerifyChain(modified), ErrChainBroken)
+
+	blanked := append([]Entry{}, entries...)
+	blanked[1].Target = ""
+	assert.ErrorIs(t, VerifyChain(blanked), ErrChainBroken)
+
+	removed := []Entry{entries[0], entries[2]}
+	assert.ErrorIs(t, VerifyChain(removed), ErrChainBroken)
+
+	reordered := []Entry{entries[1], entries[0], entries[2]}
+	assert.ErrorIs(t, VerifyChain(reordered), ErrChainBroken)
+
+	// database keeps microseconds only, the hash must survive the round trip
+	stored := entries[0]
+	stored.CreatedAt = stored.CreatedAt.Truncate(time.Microsecond).In(time.FixedZone("UTC+3", 3*60*60))
+	assert.Equal(t, entries[0].Hash, stored.ComputeHash())
+}
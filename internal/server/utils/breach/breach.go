@@ -0,0 +1,151 @@
+// Package breach looks up passwords in offline copy of the Have I Been Pwned password ranges,
+// so passwords are never sent anywhere to be checked.
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PrefixLength is number of hex characters of SHA-1 hash by which the ranges are partitioned
+const PrefixLength = 5
+
+var (
+	ErrInvalidPrefix = errors.New("error range prefix must be 5 hex characters")
+	ErrNoRange       = errors.New("error range file is missing")
+	ErrCorruptRange  = errors.New("error range file is corrupt")
+)
+
+// Entry is hash of a breached password without its prefix and how many times it was seen in breaches
+type Entry struct {
+	Suffix string
+	Count  int
+}
+
+// Ranges is directory of range files in HIBP format, every file is named by the prefix, e.g. "21BD1" or "21BD1.txt",
+// and has lines of the rest of the hash and the count, e.g. "0018A45C4D1DEF81644B54AB7F969B88D65:21"
+type Ranges struct {
+	dir string
+}
+
+// Open returns ranges stored in the directory, the directory must have range files and the first of them
+// is read through, so a missing or corrupt dataset is reported before passwords are checked against it
+func Open(dir string) (*Ranges, error) {
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening ranges: %w", err)
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("error while opening ranges: %s is not a directory", dir)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening ranges: %w", err)
+	}
+	r := &Ranges{dir: dir}
+	for _, file := range files {
+		prefix := strings.TrimSuffix(file.Name(), ".txt")
+		if file.IsDir() || !validPrefix(strings.ToUpper(prefix)) {
+			continue
+		}
+		if _, err = r.Range(prefix); err != nil {
+			return nil, fmt.Errorf("error while opening ranges: %w", err)
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("error while opening ranges: %s has no range files", dir)
+}
+
+// Hash returns prefix and suffix of the password's SHA-1 hash in upper case like in the range files
+func Hash(password string) (string, string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:PrefixLength], hash[PrefixLength:]
+}
+
+// Range returns all entries of the prefix
+func (r *Ranges) Range(prefix string) ([]Entry, error) {
+	var entries []Entry
+	err := r.scan(prefix, func(entry Entry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+	return entries, err
+}
+
+// Count returns how many times the password was seen in breaches, it is zero for passwords which are not in the ranges
+func (r *Ranges) Count(password string) (int, error) {
+	prefix, suffix := Hash(password)
+	count := 0
+	err := r.scan(prefix, func(entry Entry) bool {
+		if entry.Suffix != suffix {
+			return true
+		}
+		count = entry.Count
+		return false
+	})
+	return count, err
+}
+
+// scan calls fn for entries of the prefix until it returns false, padding entries with zero count are skipped
+func (r *Ranges) scan(prefix string, fn func(entry Entry) bool) error {
+	prefix = strings.ToUpper(prefix)
+	if !validPrefix(prefix) {
+		return ErrInvalidPrefix
+	}
+	file, err := r.open(prefix)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		suffix, count, ok := strings.Cut(line, ":")
+		if !ok || len(suffix) != 40-PrefixLength {
+			return fmt.Errorf("%w: %s has line %q", ErrCorruptRange, prefix, line)
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return fmt.Errorf("%w: %s has wrong count: %v", ErrCorruptRange, prefix, err)
+		}
+		if n == 0 {
+			continue
+		}
+		if !fn(Entry{Suffix: strings.ToUpper(suffix), Count: n}) {
+			return nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("error while reading range %s: %w", prefix, err)
+	}
+	return nil
+}
+
+func validPrefix(prefix string) bool {
+	return len(prefix) == PrefixLength && strings.Trim(prefix, "0123456789ABCDEF") == ""
+}
+
+func (r *Ranges) open(prefix string) (*os.File, error) {
+	for _, name := range []string{prefix, prefix + ".txt", strings.ToLower(prefix), strings.ToLower(prefix) + ".txt"} {
+		file, err := os.Open(filepath.Join(r.dir, name))
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("error while opening range %s: %w", prefix, err)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoRange, prefix)
+}
@@ -0,0 +1,58 @@
+package breach
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRanges(t *testing.T) {
+	dir := t.TempDir()
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	prefix, suffix := Hash("password")
+	require.Equal(t, "5BAA6", prefix)
+	require.Equal(t, "1E4C9B93F3F0682250B6CF8331B7EE68FD8", suffix)
+	data := "003D68EB55068C33ACE09247EE4C639306B:3\r\n" + suffix + ":9545824\r\n0123456789ABCDEF0123456789ABCDEF012:0\r\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(data), 0600))
+	secure, _ := Hash("correct-battery-staple-mango-pebble")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, secure), []byte("003D68EB55068C33ACE09247EE4C639306B:3\n"), 0600))
+
+	ranges, err := Open(dir)
+	require.NoError(t, err)
+
+	count, err := ranges.Count("password")
+	require.NoError(t, err)
+	assert.Equal(t, 9545824, count)
+
+	count, err = ranges.Count("correct-battery-staple-mango-pebble")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	entries, err := ranges.Range("5baa6")
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Suffix: "003D68EB55068C33ACE09247EE4C639306B", Count: 3}, {Suffix: suffix, Count: 9545824}}, entries)
+
+	_, err = ranges.Count("qwerty")
+	assert.ErrorIs(t, err, ErrNoRange)
+	_, err = ranges.Range("XYZ12")
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+
+	_, err = Open(filepath.Join(dir, prefix+".txt"))
+	assert.Error(t, err)
+
+	_, err = Open(t.TempDir())
+	assert.Error(t, err)
+
+	corrupt, _ := Hash("qwerty")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, corrupt), []byte("not a range file\n"), 0600))
+	_, err = ranges.Count("qwerty")
+	assert.ErrorIs(t, err, ErrCorruptRange)
+
+	corruptDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(corruptDir, prefix), []byte(suffix+":many\n"), 0600))
+	_, err = Open(corruptDir)
+	assert.ErrorIs(t, err, ErrCorruptRange)
+}
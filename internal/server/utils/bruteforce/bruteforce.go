@@ -0,0 +1,163 @@
+package bruteforce
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultPolicy allows a few typos, then slows down guessing and locks out after 10 failures
+var DefaultPolicy = Policy{
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	MaxDelay:        5 * time.Minute,
+	LockoutAttempts: 10,
+	LockoutDuration: 15 * time.Minute,
+	ResetAfter:      time.Hour,
+}
+
+// Policy describes how long a key is blocked after failed attempts
+type Policy struct {
+	// FreeAttempts is number of failures which don't block the key
+	FreeAttempts int
+	// BaseDelay is doubled for every failure after FreeAttempts up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutAttempts is number of failures after which the key is locked for LockoutDuration
+	LockoutAttempts int
+	LockoutDuration time.Duration
+	// ResetAfter is period without failures after which the counter starts over
+	ResetAfter time.Duration
+}
+
+// BlockFor returns how long the key is blocked after failures in a row
+func (p Policy) BlockFor(failures int) time.Duration {
+	if p.LockoutAttempts > 0 && failures >= p.LockoutAttempts {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Store keeps attempt counters, it is shared by all server instances
+type Store interface {
+	// IncrementFailures increments key's counter, counter is restarted if the last failure was before since
+	IncrementFailures(key string, now time.Time, since time.Time) (int, error)
+	SetBlockedUntil(key string, until time.Time) error
+	GetBlockedUntil(key string) (time.Time, error)
+	ResetFailures(key string) error
+}
+
+// BlockedError is returned while key is blocked
+type BlockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("error too many failed attempts, retry after %s", e.RetryAfter)
+}
+
+// RetryAfterSeconds returns value for Retry-After header
+func (e *BlockedError) RetryAfterSeconds() int {
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+type Guard struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+func NewGuard(store Store, policy Policy) *Guard {
+	return &Guard{
+		store:  store,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Check returns *BlockedError if any of the keys is blocked
+func (g *Guard) Check(keys ...string) error {
+	if g == nil {
+		return nil
+	}
+	now := g.now()
+	var retryAfter time.Duration
+	for _, key := range keys {
+		until, err := g.store.GetBlockedUntil(key)
+		if err != nil {
+			return fmt.Errorf("error while checking attempts: %w", err)
+		}
+		if wait := until.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return &BlockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Fail registers failed attempt for every key and blocks keys according to the policy
+func (g *Guard) Fail(keys ...string) error {
+	if g == nil {
+		return nil
+	}
+	now := g.now()
+	for _, key := range keys {
+		failures, err := g.store.IncrementFailures(key, now, now.Add(-g.policy.ResetAfter))
+		if err != nil {
+			return fmt.Errorf("error while registering failed attempt: %w", err)
+		}
+		if blockFor := g.policy.BlockFor(failures); blockFor > 0 {
+			if err = g.store.SetBlockedUntil(key, now.Add(blockFor)); err != nil {
+				return fmt.Errorf("error while blocking attempts: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Success resets counters of the keys
+func (g *Guard) Success(keys ...string) error {
+	if g == nil {
+		return nil
+	}
+	for _, key := range keys {
+		if err := g.store.ResetFailures(key); err != nil {
+			return fmt.Errorf("error while resetting attempts: %w", err)
+		}
+	}
+	return nil
+}
+
+func LoginKey(login string) string {
+	return "login:" + login
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// ClientIP returns address the request came from, forwarding headers are ignored as they can be forged
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
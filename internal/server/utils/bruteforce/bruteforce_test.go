@@ -0,0 +1,66 @@
+package bruteforce
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage/mockstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBlockFor tests exponential backoff and lockout
+func TestBlockFor(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 7, want: 8 * time.Second},
+		{failures: 9, want: 32 * time.Second},
+		{failures: 10, want: 15 * time.Minute},
+		{failures: 50, want: 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DefaultPolicy.BlockFor(tt.failures), "failures: %d", tt.failures)
+	}
+	policy := DefaultPolicy
+	policy.LockoutAttempts = 0
+	assert.Equal(t, policy.MaxDelay, policy.BlockFor(50))
+}
+
+// TestGuard tests keys are blocked after failures and unblocked after success or reset period
+func TestGuard(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	guard := NewGuard(mockstorage.NewMockStorage(), DefaultPolicy)
+	guard.now = func() time.Time { return now }
+
+	for i := 0; i < DefaultPolicy.FreeAttempts; i++ {
+		require.NoError(t, guard.Fail("login:user", "ip:127.0.0.1"))
+	}
+	assert.NoError(t, guard.Check("login:user", "ip:127.0.0.1"))
+	require.NoError(t, guard.Fail("login:user", "ip:127.0.0.1"))
+
+	var blocked *BlockedError
+	require.True(t, errors.As(guard.Check("login:other", "ip:127.0.0.1"), &blocked))
+	assert.Equal(t, time.Second, blocked.RetryAfter)
+	assert.Equal(t, 1, blocked.RetryAfterSeconds())
+	now = now.Add(time.Second)
+	assert.NoError(t, guard.Check("login:user", "ip:127.0.0.1"))
+
+	require.NoError(t, guard.Fail("login:user"))
+	require.Error(t, guard.Check("login:user"))
+	require.NoError(t, guard.Success("login:user"))
+	assert.NoError(t, guard.Check("login:user"))
+
+	now = now.Add(DefaultPolicy.ResetAfter + time.Second)
+	require.NoError(t, guard.Fail("ip:127.0.0.1"))
+	assert.NoError(t, guard.Check("ip:127.0.0.1"))
+
+	var nilGuard *Guard
+	assert.NoError(t, nilGuard.Check("login:user"))
+}
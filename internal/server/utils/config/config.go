@@ -7,6 +7,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -15,9 +17,18 @@ type Config struct {
 	Database        *sql.DB
 	JWTSecret       string `json:"jwt_secret"`
 	SecretKey       string
+	// LockoutAttempts is number of failed logins after which account or IP is locked out
+	LockoutAttempts int           `json:"lockout_attempts"`
+	LockoutDuration time.Duration `json:"lockout_duration"`
+	// BreachedPasswords is directory of HIBP range files, passwords found there are rejected at registration
+	BreachedPasswords string `json:"breached_passwords"`
 }
 
-const defaultAddress = "localhost:8080"
+const (
+	defaultAddress         = "localhost:8080"
+	defaultLockoutAttempts = 10
+	defaultLockoutDuration = 15 * time.Minute
+)
 
 // SetServerParams sets server config
 func SetServerParams() (cfg Config) {
@@ -27,6 +38,9 @@ func SetServerParams() (cfg Config) {
 		flagConfigFile string
 		flagJWTSecret  string
 		flagSecretKey  string
+		flagAttempts   int
+		flagLockout    time.Duration
+		flagBreached   string
 		cfgFile        string
 	)
 	flag.StringVar(&flagAddress, "a", defaultAddress, "server_address")
@@ -34,6 +48,9 @@ func SetServerParams() (cfg Config) {
 	flag.StringVar(&flagConfigFile, "c", "", "config_as_json")
 	flag.StringVar(&flagJWTSecret, "js", "", "jwt_secret_key")
 	flag.StringVar(&flagSecretKey, "k", "", "secret_key_to_enc")
+	flag.IntVar(&flagAttempts, "la", defaultLockoutAttempts, "lockout_attempts")
+	flag.DurationVar(&flagLockout, "ld", defaultLockoutDuration, "lockout_duration")
+	flag.StringVar(&flagBreached, "bp", "", "breached_passwords_dir")
 	flag.Parse()
 	var exists bool
 	if cfgFile, exists = os.LookupEnv("CONFIG"); !exists {
@@ -69,6 +86,29 @@ func SetServerParams() (cfg Config) {
 	if !exists {
 		cfg.DatabaseAddress = flagDataBase
 	}
+	cfg.LockoutAttempts = flagAttempts
+	if envAttempts, exists := os.LookupEnv("LOCKOUT_ATTEMPTS"); exists {
+		attempts, err := strconv.Atoi(envAttempts)
+		if err != nil {
+			log.Println("error while parsing LOCKOUT_ATTEMPTS:", err)
+		} else {
+			cfg.LockoutAttempts = attempts
+		}
+	}
+	cfg.LockoutDuration = flagLockout
+	if envLockout, exists := os.LookupEnv("LOCKOUT_DURATION"); exists {
+		lockout, err := time.ParseDuration(envLockout)
+		if err != nil {
+			log.Println("error while parsing LOCKOUT_DURATION:", err)
+		} else {
+			cfg.LockoutDuration = lockout
+		}
+	}
+	if envBreached, exists := os.LookupEnv("BREACHED_PASSWORDS"); exists {
+		cfg.BreachedPasswords = envBreached
+	} else if flagBreached != "" {
+		cfg.BreachedPasswords = flagBreached
+	}
 	log.Println(cfg.JWTSecret, flagJWTSecret)
 	return cfg
 }
@@ -0,0 +1,78 @@
+package emergency
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// StatusIdle means the contact never requested access or the owner removed the request
+	StatusIdle      Status = "idle"
+	StatusRequested Status = "requested"
+	StatusRejected  Status = "rejected"
+	// StatusGranted is never stored, requested access becomes granted when the waiting period is over
+	StatusGranted Status = "granted"
+
+	MinWaitHours = 1
+	MaxWaitHours = 90 * 24
+)
+
+var (
+	ErrInvalidWait      = errors.New("error waiting period is invalid")
+	ErrAlreadyRequested = errors.New("error emergency access is already requested")
+	ErrNotRequested     = errors.New("error emergency access is not requested")
+)
+
+// Status is state of the trusted contact's access to the owner's vault
+type Status string
+
+// Contact is a user the owner trusts to access the owner's vault in emergency
+type Contact struct {
+	Owner     string `json:"owner"`
+	Contact   string `json:"contact"`
+	WaitHours int    `json:"wait_hours"`
+	Status    Status `json:"status"`
+	// RequestedAt is time of the last request, it is zero if access was never requested
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+func ValidWait(hours int) bool {
+	return hours >= MinWaitHours && hours <= MaxWaitHours
+}
+
+// GrantsAt returns time when requested access is granted unless the owner rejects it
+func (c Contact) GrantsAt() time.Time {
+	return c.RequestedAt.Add(time.Duration(c.WaitHours) * time.Hour)
+}
+
+// Resolve returns the contact with status at the moment
+func (c Contact) Resolve(now time.Time) Contact {
+	if c.Status == StatusRequested && !now.Before(c.GrantsAt()) {
+		c.Status = StatusGranted
+	}
+	return c
+}
+
+func (c Contact) Granted(now time.Time) bool {
+	return c.Resolve(now).Status == StatusGranted
+}
+
+// Request returns the contact with access requested at the moment
+func (c Contact) Request(now time.Time) (Contact, error) {
+	switch c.Resolve(now).Status {
+	case StatusRequested, StatusGranted:
+		return c, ErrAlreadyRequested
+	}
+	c.Status = StatusRequested
+	c.RequestedAt = now
+	return c, nil
+}
+
+// Reject returns the contact with pending or granted access rejected by the owner
+func (c Contact) Reject() (Contact, error) {
+	if c.Status != StatusRequested {
+		return c, ErrNotRequested
+	}
+	c.Status = StatusRejected
+	return c, nil
+}
@@ -0,0 +1,56 @@
+package emergency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactFlow(t *testing.T) {
+	start := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	c := Contact{Owner: "owner", Contact: "friend", WaitHours: 48, Status: StatusIdle}
+
+	_, err := c.Reject()
+	assert.ErrorIs(t, err, ErrNotRequested)
+
+	c, err = c.Request(start)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(48*time.Hour), c.GrantsAt())
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want Status
+	}{
+		{name: "just requested", now: start, want: StatusRequested},
+		{name: "waiting", now: start.Add(47 * time.Hour), want: StatusRequested},
+		{name: "waiting period is over", now: start.Add(48 * time.Hour), want: StatusGranted},
+		{name: "long after", now: start.Add(480 * time.Hour), want: StatusGranted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Resolve(tt.now).Status)
+			assert.Equal(t, tt.want == StatusGranted, c.Granted(tt.now))
+			_, err := c.Request(tt.now)
+			assert.ErrorIs(t, err, ErrAlreadyRequested)
+		})
+	}
+
+	// owner can reject access even after it was granted
+	c, err = c.Reject()
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, c.Resolve(start.Add(480*time.Hour)).Status)
+
+	c, err = c.Request(start.Add(500 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, StatusRequested, c.Resolve(start.Add(501*time.Hour)).Status)
+}
+
+func TestValidWait(t *testing.T) {
+	assert.False(t, ValidWait(0))
+	assert.True(t, ValidWait(MinWaitHours))
+	assert.True(t, ValidWait(MaxWaitHours))
+	assert.False(t, ValidWait(MaxWaitHours+1))
+}
@@ -0,0 +1,61 @@
+package links
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	tokenSize = 32
+
+	DefaultMaxViews = 1
+	MaxViews        = 100
+	DefaultTTL      = 24 * time.Hour
+	MaxTTL          = 30 * 24 * time.Hour
+)
+
+var ErrInvalidParams = errors.New("error link params are invalid")
+
+// Params are limits of a one-time link, zero values mean defaults
+type Params struct {
+	MaxViews int `json:"max_views,omitempty"`
+	// ExpiresIn is link's lifetime in seconds
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+}
+
+// Normalize fills defaults and checks the limits
+func (p Params) Normalize() (Params, error) {
+	if p.MaxViews == 0 {
+		p.MaxViews = DefaultMaxViews
+	}
+	if p.ExpiresIn == 0 {
+		p.ExpiresIn = int64(DefaultTTL / time.Second)
+	}
+	if p.MaxViews < 0 || p.MaxViews > MaxViews || p.ExpiresIn < 0 || p.TTL() > MaxTTL {
+		return p, ErrInvalidParams
+	}
+	return p, nil
+}
+
+func (p Params) TTL() time.Duration {
+	return time.Duration(p.ExpiresIn) * time.Second
+}
+
+// NewToken generates random URL-safe token, only its hash is stored
+func NewToken() (token string, hash []byte, err error) {
+	b := make([]byte, tokenSize)
+	if _, err = rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("error while generating link token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
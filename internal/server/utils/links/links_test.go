@@ -0,0 +1,64 @@
+package links
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		want    Params
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			params: Params{},
+			want:   Params{MaxViews: DefaultMaxViews, ExpiresIn: int64(DefaultTTL / time.Second)},
+		},
+		{
+			name:   "custom",
+			params: Params{MaxViews: 3, ExpiresIn: 600},
+			want:   Params{MaxViews: 3, ExpiresIn: 600},
+		},
+		{
+			name:    "too many views",
+			params:  Params{MaxViews: MaxViews + 1},
+			wantErr: true,
+		},
+		{
+			name:    "negative views",
+			params:  Params{MaxViews: -1},
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			params:  Params{ExpiresIn: int64(MaxTTL/time.Second) + 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.Normalize()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidParams)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := NewToken()
+	require.NoError(t, err)
+	assert.Equal(t, HashToken(token), hash)
+	other, _, err := NewToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
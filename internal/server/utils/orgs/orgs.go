@@ -0,0 +1,85 @@
+package orgs
+
+import "errors"
+
+const (
+	RoleOwner    Role = "owner"
+	RoleAdmin    Role = "admin"
+	RoleMember   Role = "member"
+	RoleReadOnly Role = "read-only"
+)
+
+var (
+	ErrOrgExists     = errors.New("error organization already exists")
+	ErrAlreadyMember = errors.New("error user is already a member or invited")
+	ErrLastOwner     = errors.New("error organization must have at least one owner")
+)
+
+// Role is user's role in an organization
+type Role string
+
+// rank orders roles from read-only to owner, unknown roles have zero rank
+func (r Role) rank() int {
+	switch r {
+	case RoleReadOnly:
+		return 1
+	case RoleMember:
+		return 2
+	case RoleAdmin:
+		return 3
+	case RoleOwner:
+		return 4
+	}
+	return 0
+}
+
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// CanRead reports whether the role can read organization's secrets, every member can
+func (r Role) CanRead() bool {
+	return r.rank() >= RoleReadOnly.rank()
+}
+
+// CanWrite reports whether the role can add, update and delete organization's secrets
+func (r Role) CanWrite() bool {
+	return r.rank() >= RoleMember.rank()
+}
+
+// CanManage reports whether the role can invite members and manage collections
+func (r Role) CanManage() bool {
+	return r.rank() >= RoleAdmin.rank()
+}
+
+// CanDelete reports whether the role can delete the organization
+func (r Role) CanDelete() bool {
+	return r == RoleOwner
+}
+
+// CanAssign reports whether the role can give other role to a member, admins can't make other admins or owners
+func (r Role) CanAssign(role Role) bool {
+	if !r.CanManage() || !role.Valid() {
+		return false
+	}
+	return r == RoleOwner || role.rank() < RoleAdmin.rank()
+}
+
+// Member is a user with the user's role in an organization
+type Member struct {
+	Login string `json:"login"`
+	Role  Role   `json:"role"`
+}
+
+// Membership is an organization with the user's role in it
+type Membership struct {
+	Org  string `json:"org"`
+	Role Role   `json:"role"`
+}
+
+// Invitation is pending invitation of the user to an organization
+type Invitation struct {
+	Org       string `json:"org"`
+	Role      Role   `json:"role"`
+	InvitedBy string `json:"invited_by"`
+}
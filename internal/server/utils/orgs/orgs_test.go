@@ -0,0 +1,53 @@
+package orgs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		role   Role
+		read   bool
+		write  bool
+		manage bool
+		delete bool
+	}{
+		{role: RoleOwner, read: true, write: true, manage: true, delete: true},
+		{role: RoleAdmin, read: true, write: true, manage: true},
+		{role: RoleMember, read: true, write: true},
+		{role: RoleReadOnly, read: true},
+		{role: ""},
+		{role: "superuser"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			assert.Equal(t, tt.read, tt.role.CanRead())
+			assert.Equal(t, tt.write, tt.role.CanWrite())
+			assert.Equal(t, tt.manage, tt.role.CanManage())
+			assert.Equal(t, tt.delete, tt.role.CanDelete())
+		})
+	}
+}
+
+func TestRoleCanAssign(t *testing.T) {
+	tests := []struct {
+		role   Role
+		assign Role
+		want   bool
+	}{
+		{role: RoleOwner, assign: RoleOwner, want: true},
+		{role: RoleOwner, assign: RoleAdmin, want: true},
+		{role: RoleAdmin, assign: RoleAdmin, want: false},
+		{role: RoleAdmin, assign: RoleMember, want: true},
+		{role: RoleAdmin, assign: RoleReadOnly, want: true},
+		{role: RoleMember, assign: RoleReadOnly, want: false},
+		{role: RoleOwner, assign: "superuser", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"->"+string(tt.assign), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.role.CanAssign(tt.assign))
+		})
+	}
+}
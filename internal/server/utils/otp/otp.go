@@ -0,0 +1,236 @@
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+const secretSize = 20
+
+var (
+	ErrInvalidSecret    = errors.New("error totp secret is invalid")
+	ErrInvalidAlgorithm = errors.New("error totp algorithm is not supported")
+	ErrInvalidParams    = errors.New("error totp params are invalid")
+)
+
+// DefaultParams are the parameters understood by every authenticator app
+var DefaultParams = Params{
+	Algorithm: SHA1,
+	Digits:    6,
+	Period:    30,
+}
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type Algorithm string
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case SHA1, "":
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+	return nil, ErrInvalidAlgorithm
+}
+
+// Params describes how codes are generated from a secret (RFC 6238)
+type Params struct {
+	Algorithm Algorithm
+	Digits    int
+	Period    int
+}
+
+// Validate checks the parameters are supported
+func (p Params) Validate() error {
+	if _, err := p.Algorithm.hash(); err != nil {
+		return err
+	}
+	if p.Digits < 6 || p.Digits > 8 || p.Period <= 0 {
+		return ErrInvalidParams
+	}
+	return nil
+}
+
+// NewSecret generates random base32 encoded secret
+func NewSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error while generating totp secret: %w", err)
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := encoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+// Counter returns number of the time step t belongs to
+func Counter(t time.Time, p Params) uint64 {
+	return uint64(t.Unix()) / uint64(p.Period)
+}
+
+// Remaining returns time left until the code for t expires
+func Remaining(t time.Time, p Params) time.Duration {
+	period := int64(p.Period)
+	return time.Duration(period-t.Unix()%period) * time.Second
+}
+
+// GenerateCode returns code for the moment t
+func GenerateCode(secret string, t time.Time, p Params) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, Counter(t, p), p), nil
+}
+
+func hotp(key []byte, counter uint64, p Params) string {
+	h, _ := p.Algorithm.hash()
+	mac := hmac.New(h, key)
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < p.Digits; i++ {
+		mod *= 10
+	}
+	code := strconv.FormatUint(uint64(value%mod), 10)
+	return strings.Repeat("0", p.Digits-len(code)) + code
+}
+
+// Validate checks code for the moment t allowing skew steps of clock drift
+func Validate(code, secret string, t time.Time, p Params, skew int) bool {
+	_, ok := Match(code, secret, t, p, skew)
+	return ok
+}
+
+// Match is Validate which also returns time step of the code, so used codes can be told from new ones
+func Match(code, secret string, t time.Time, p Params, skew int) (uint64, bool) {
+	if p.Validate() != nil {
+		return 0, false
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+	code = strings.ReplaceAll(code, " ", "")
+	counter := Counter(t, p)
+	for i := -skew; i <= skew; i++ {
+		step := uint64(int64(counter) + int64(i))
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step, p)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// ProvisioningURI builds otpauth:// URI that authenticator apps can import
+func ProvisioningURI(secret, issuer, account string, p Params) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	if p.Algorithm != "" {
+		query.Set("algorithm", string(p.Algorithm))
+	}
+	query.Set("digits", strconv.Itoa(p.Digits))
+	query.Set("period", strconv.Itoa(p.Period))
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Key is a TOTP secret with the parameters imported from otpauth:// URI
+type Key struct {
+	Secret  string
+	Issuer  string
+	Account string
+	Params  Params
+}
+
+// ParseURI parses otpauth://totp/ URI the way authenticator apps do, missing parameters are defaults
+func ParseURI(uri string) (Key, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Scheme != "otpauth" {
+		return Key{}, ErrInvalidParams
+	}
+	if !strings.EqualFold(u.Host, "totp") {
+		return Key{}, ErrInvalidParams
+	}
+	query := u.Query()
+	key := Key{
+		Secret: strings.ToUpper(query.Get("secret")),
+		Issuer: query.Get("issuer"),
+		Params: DefaultParams,
+	}
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		if key.Issuer == "" {
+			key.Issuer = issuer
+		}
+		label = account
+	}
+	key.Account = strings.TrimSpace(label)
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Params.Algorithm = Algorithm(strings.ToUpper(algorithm))
+	}
+	if digits := query.Get("digits"); digits != "" {
+		if key.Params.Digits, err = strconv.Atoi(digits); err != nil {
+			return Key{}, ErrInvalidParams
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		if key.Params.Period, err = strconv.Atoi(period); err != nil {
+			return Key{}, ErrInvalidParams
+		}
+	}
+	if err = ValidateSecret(key.Secret); err != nil {
+		return Key{}, err
+	}
+	if err = key.Params.Validate(); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+// ValidateSecret checks the secret is base32 encoded
+func ValidateSecret(secret string) error {
+	_, err := decodeSecret(secret)
+	return err
+}
@@ -0,0 +1,92 @@
+package otp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateCode tests codes against RFC 6238 test vectors
+func TestGenerateCode(t *testing.T) {
+	secrets := map[Algorithm]string{
+		SHA1:   base32.StdEncoding.EncodeToString([]byte("12345678901234567890")),
+		SHA256: base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012")),
+		SHA512: base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234")),
+	}
+	tests := []struct {
+		name      string
+		time      int64
+		algorithm Algorithm
+		want      string
+	}{
+		{name: "SHA1 59", time: 59, algorithm: SHA1, want: "94287082"},
+		{name: "SHA256 59", time: 59, algorithm: SHA256, want: "46119246"},
+		{name: "SHA512 59", time: 59, algorithm: SHA512, want: "90693936"},
+		{name: "SHA1 1111111109", time: 1111111109, algorithm: SHA1, want: "07081804"},
+		{name: "SHA256 1111111109", time: 1111111109, algorithm: SHA256, want: "68084774"},
+		{name: "SHA512 1111111109", time: 1111111109, algorithm: SHA512, want: "25091201"},
+		{name: "SHA1 1234567890", time: 1234567890, algorithm: SHA1, want: "89005924"},
+		{name: "SHA256 2000000000", time: 2000000000, algorithm: SHA256, want: "90698825"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Params{Algorithm: tt.algorithm, Digits: 8, Period: 30}
+			code, err := GenerateCode(secrets[tt.algorithm], time.Unix(tt.time, 0), p)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
+
+// TestValidate tests code validation with clock skew
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	code, err := GenerateCode(secret, now, DefaultParams)
+	require.NoError(t, err)
+
+	assert.True(t, Validate(code, secret, now, DefaultParams, 0))
+	assert.True(t, Validate(code, secret, now.Add(30*time.Second), DefaultParams, 1))
+	assert.False(t, Validate(code, secret, now.Add(90*time.Second), DefaultParams, 1))
+	assert.False(t, Validate("000000", "not base32!", now, DefaultParams, 1))
+
+	step, ok := Match(code, secret, now.Add(30*time.Second), DefaultParams, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Counter(now, DefaultParams), step)
+	_, err = GenerateCode(secret, now, Params{Algorithm: "MD5", Digits: 6, Period: 30})
+	assert.ErrorIs(t, err, ErrInvalidAlgorithm)
+}
+
+// TestParseURI tests otpauth:// URIs are parsed back into keys
+func TestParseURI(t *testing.T) {
+	p := Params{Algorithm: SHA256, Digits: 8, Period: 60}
+	key, err := ParseURI(ProvisioningURI("JBSWY3DPEHPK3PXP", "Example Corp", "alice@example.com", p))
+	require.NoError(t, err)
+	assert.Equal(t, Key{Secret: "JBSWY3DPEHPK3PXP", Issuer: "Example Corp", Account: "alice@example.com", Params: p}, key)
+
+	key, err = ParseURI("otpauth://totp/GitHub:bob?secret=jbswy3dpehpk3pxp")
+	require.NoError(t, err)
+	assert.Equal(t, Key{Secret: "JBSWY3DPEHPK3PXP", Issuer: "GitHub", Account: "bob", Params: DefaultParams}, key)
+
+	tests := []struct {
+		name string
+		uri  string
+		err  error
+	}{
+		{name: "wrong scheme", uri: "https://totp/bob?secret=JBSWY3DPEHPK3PXP", err: ErrInvalidParams},
+		{name: "hotp", uri: "otpauth://hotp/bob?secret=JBSWY3DPEHPK3PXP&counter=1", err: ErrInvalidParams},
+		{name: "no secret", uri: "otpauth://totp/bob", err: ErrInvalidSecret},
+		{name: "wrong algorithm", uri: "otpauth://totp/bob?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", err: ErrInvalidAlgorithm},
+		{name: "wrong digits", uri: "otpauth://totp/bob?secret=JBSWY3DPEHPK3PXP&digits=4", err: ErrInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseURI(tt.uri)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const APIKey InfoType = "api-key"
+
+// InfoAPIKey is API token or cloud credential, KeyID is empty for providers which issue single token
+type InfoAPIKey struct {
+	CustomFields
+	Rotation
+	Provider string `json:"provider"`
+	Host     string `json:"host"`
+	KeyID    string `json:"key_id"`
+	Secret   string `json:"secret"`
+	// Scopes are separated with commas or spaces
+	Scopes    string `json:"scopes"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// ScopeList returns the key's scopes one by one
+func (k *InfoAPIKey) ScopeList() []string {
+	return strings.FieldsFunc(k.Scopes, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+func (k *InfoAPIKey) ExpiresOn() (time.Time, bool) {
+	return parseDate(k.ExpiresAt)
+}
+
+func init() {
+	Register(TypeSpec{
+		Type: APIKey,
+		Name: "API Key",
+		New:  func() Info { return &InfoAPIKey{} },
+		Fields: []Field{
+			{Name: "provider", Label: "Provider", Required: true},
+			{Name: "host", Label: "API host", Validate: validateHost},
+			{Name: "key_id", Label: "Key ID"},
+			{Name: "secret", Label: "Secret", Masked: true, Required: true},
+			{Name: "scopes", Label: "Scopes"},
+			{Name: "expires_at", Label: "Expiry date (YYYY-MM-DD)", Validate: validateDate},
+		},
+	})
+}
+
+// validateHost accepts host with optional port, URLs are not allowed because .netrc matches hosts only
+func validateHost(value string) error {
+	u, err := url.Parse("//" + value)
+	if err != nil || u.Host != value || strings.ContainsAny(value, " /") {
+		return errors.New("is not a host name")
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CardExpiryLayout is layout of expiration dates of cards
+const CardExpiryLayout = "01/06"
+
+// cardBrand is a payment system recognized by prefix of the card number
+type cardBrand struct {
+	name string
+	// prefixes are inclusive ranges of the number's first digits, bounds of a range have equal length
+	prefixes  [][2]string
+	lengths   []int
+	cvcLength int
+}
+
+// cardBrands are checked in order, so brands with narrower prefixes go first
+var cardBrands = []cardBrand{
+	{name: "Visa", prefixes: [][2]string{{"4", "4"}}, lengths: []int{13, 16, 19}, cvcLength: 3},
+	{name: "Mir", prefixes: [][2]string{{"2200", "2204"}}, lengths: []int{16, 17, 18, 19}, cvcLength: 3},
+	{name: "Mastercard", prefixes: [][2]string{{"51", "55"}, {"2221", "2720"}}, lengths: []int{16}, cvcLength: 3},
+	{name: "American Express", prefixes: [][2]string{{"34", "34"}, {"37", "37"}}, lengths: []int{15}, cvcLength: 4},
+	{name: "Discover", prefixes: [][2]string{{"6011", "6011"}, {"644", "649"}, {"65", "65"}}, lengths: []int{16, 17, 18, 19}, cvcLength: 3},
+	{name: "JCB", prefixes: [][2]string{{"3528", "3589"}}, lengths: []int{16, 17, 18, 19}, cvcLength: 3},
+	{name: "Diners Club", prefixes: [][2]string{{"300", "305"}, {"36", "36"}, {"38", "39"}}, lengths: []int{14, 15, 16, 17, 18, 19}, cvcLength: 3},
+	{name: "UnionPay", prefixes: [][2]string{{"62", "62"}}, lengths: []int{16, 17, 18, 19}, cvcLength: 3},
+	{name: "Maestro", prefixes: [][2]string{{"50", "50"}, {"56", "58"}, {"63", "63"}, {"67", "67"}}, lengths: []int{12, 13, 14, 15, 16, 17, 18, 19}, cvcLength: 3},
+}
+
+func (b cardBrand) matches(digits string) bool {
+	for _, prefix := range b.prefixes {
+		if len(digits) < len(prefix[0]) {
+			continue
+		}
+		head := digits[:len(prefix[0])]
+		if head >= prefix[0] && head <= prefix[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (b cardBrand) validLength(digits string) bool {
+	for _, length := range b.lengths {
+		if len(digits) == length {
+			return true
+		}
+	}
+	return false
+}
+
+// CardDigits returns digits of the card number without spaces and dashes, it is false if there are other symbols
+func CardDigits(number string) (string, bool) {
+	var b strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// LuhnValid reports whether the digits pass the Luhn checksum
+func LuhnValid(digits string) bool {
+	if digits == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// CardBrand returns the payment system of the card number or empty string if it is unknown
+func CardBrand(number string) string {
+	digits, ok := CardDigits(number)
+	if !ok {
+		return ""
+	}
+	if brand, ok := lookupCardBrand(digits); ok {
+		return brand.name
+	}
+	return ""
+}
+
+func lookupCardBrand(digits string) (cardBrand, bool) {
+	for _, brand := range cardBrands {
+		if brand.matches(digits) {
+			return brand, true
+		}
+	}
+	return cardBrand{}, false
+}
+
+// ParseCardExpiry parses MM/YY date and returns the first day of the next month,
+// the card is valid until the end of the month and expires at the start of the next one
+func ParseCardExpiry(value string) (time.Time, error) {
+	date, err := time.Parse(CardExpiryLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, errors.New("is not a date in MM/YY format")
+	}
+	return date.AddDate(0, 1, 0), nil
+}
+
+// MaskCardNumber hides all digits of the number except the last four
+func MaskCardNumber(number string) string {
+	digits, ok := CardDigits(number)
+	if !ok || len(digits) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	masked := strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+	return groupCardDigits(masked)
+}
+
+// FormatCardNumber returns the number split into groups of four digits
+func FormatCardNumber(number string) string {
+	digits, ok := CardDigits(number)
+	if !ok {
+		return number
+	}
+	return groupCardDigits(digits)
+}
+
+func groupCardDigits(digits string) string {
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (c *InfoCard) ExpiresOn() (time.Time, bool) {
+	date, err := ParseCardExpiry(c.Date)
+	return date, err == nil
+}
+
+func validateCardNumber(value string) error {
+	digits, ok := CardDigits(value)
+	if !ok {
+		return errors.New("must contain only digits")
+	}
+	if len(digits) < 12 || len(digits) > 19 {
+		return errors.New("must have from 12 to 19 digits")
+	}
+	if brand, ok := lookupCardBrand(digits); ok && !brand.validLength(digits) {
+		return fmt.Errorf("has wrong length for %s card", brand.name)
+	}
+	if !LuhnValid(digits) {
+		return errors.New("has wrong check digit")
+	}
+	return nil
+}
+
+func validateCardExpiry(value string) error {
+	_, err := ParseCardExpiry(value)
+	return err
+}
+
+func validateCVC(value string) error {
+	if _, err := strconv.ParseUint(value, 10, 16); err != nil || len(value) < 3 || len(value) > 4 {
+		return errors.New("must be 3 or 4 digits")
+	}
+	return nil
+}
+
+// checkCard checks length of the CVC by the card's brand
+func checkCard(info Info) FieldErrors {
+	card, ok := info.(*InfoCard)
+	if !ok || card.CVCcode == "" {
+		return nil
+	}
+	digits, _ := CardDigits(card.CardNumber)
+	brand, ok := lookupCardBrand(digits)
+	if ok && len(card.CVCcode) != brand.cvcLength {
+		return FieldErrors{{Field: "cvc", Message: fmt.Sprintf("must be %d digits for %s card", brand.cvcLength, brand.name)}}
+	}
+	return nil
+}
+
+// renderCard shows the card with masked number and CVC, the client reveals them on request
+func renderCard(info Info) string {
+	card, ok := info.(*InfoCard)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Card number: %s", MaskCardNumber(card.CardNumber))
+	if brand := CardBrand(card.CardNumber); brand != "" {
+		fmt.Fprintf(&b, " (%s)", brand)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Cardholder name: %s\n", card.Holder)
+	fmt.Fprintf(&b, "Expiration date: %s\n", card.Date)
+	fmt.Fprintf(&b, "CVC code: %s\n", strings.Repeat("*", len(card.CVCcode)))
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardBrand(t *testing.T) {
+	tests := []struct {
+		number string
+		brand  string
+		valid  bool
+	}{
+		{number: "4242 4242 4242 4242", brand: "Visa", valid: true},
+		{number: "5555-5555-5555-4444", brand: "Mastercard", valid: true},
+		{number: "2223003122003222", brand: "Mastercard", valid: true},
+		{number: "378282246310005", brand: "American Express", valid: true},
+		{number: "6011111111111117", brand: "Discover", valid: true},
+		{number: "3530111333300000", brand: "JCB", valid: true},
+		{number: "2200000000000004", brand: "Mir", valid: true},
+		{number: "4242424242424241", brand: "Visa", valid: false},
+		{number: "37828224631000", brand: "American Express", valid: false},
+		{number: "9999999999999995", brand: "", valid: true},
+		{number: "4242x4242", brand: "", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			assert.Equal(t, tt.brand, CardBrand(tt.number))
+			assert.Equal(t, tt.valid, validateCardNumber(tt.number) == nil)
+		})
+	}
+}
+
+func TestCardSpec(t *testing.T) {
+	spec, ok := Lookup(Card)
+	require.True(t, ok)
+
+	card := &InfoCard{CardNumber: "4242 4242 4242 4242", Holder: "IVAN IVANOV", Date: "02/28", CVCcode: "123"}
+	assert.NoError(t, spec.Validate(card))
+	expires, ok := card.ExpiresOn()
+	require.True(t, ok)
+	assert.Equal(t, "2028-03-01", expires.Format(DateLayout))
+
+	text, err := spec.Format(card)
+	require.NoError(t, err)
+	assert.Equal(t, "Card number: **** **** **** 4242 (Visa)\nCardholder name: IVAN IVANOV\nExpiration date: 02/28\nCVC code: ***\n", text)
+	assert.Equal(t, "4242 4242 4242 4242", FormatCardNumber("4242-4242-4242-4242"))
+
+	err = spec.Validate(&InfoCard{CardNumber: "378282246310005", Date: "02/28", CVCcode: "123"})
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, FieldErrors{{Field: "cvc", Message: "must be 4 digits for American Express card"}}, fieldErrs)
+
+	err = spec.Validate(&InfoCard{CardNumber: "4242424242424241", Date: "13/28", CVCcode: "12"})
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Len(t, fieldErrs, 3)
+}
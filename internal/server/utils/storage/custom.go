@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Custom secrets have no fields of their own, all their fields are custom, e.g. secrets created from templates
+const Custom InfoType = "custom"
+
+// MaxCustomFields limits custom fields of a secret and fields of a template
+const MaxCustomFields = 50
+
+// CustomFieldKinds are kinds the user can choose for custom fields
+var CustomFieldKinds = []FieldKind{KindText, KindHidden, KindBoolean, KindURL, KindDate}
+
+// CustomField is a field the user adds to a secret, its value is checked according to its kind
+type CustomField struct {
+	Name  string    `json:"name"`
+	Kind  FieldKind `json:"kind,omitempty"`
+	Value string    `json:"value"`
+}
+
+// CustomFields is embedded into secrets of all types to keep fields the user adds to them
+type CustomFields struct {
+	Custom []CustomField `json:"custom_fields,omitempty"`
+}
+
+// CustomFieldSet returns the custom fields, it lets generic code read and change them
+func (c *CustomFields) CustomFieldSet() *CustomFields {
+	return c
+}
+
+// Customizable is implemented by secrets which embed CustomFields
+type Customizable interface {
+	CustomFieldSet() *CustomFields
+}
+
+// InfoCustom is a secret made only of custom fields
+type InfoCustom struct {
+	CustomFields
+	Rotation
+	// Template is name of the template the secret was created from
+	Template string `json:"template,omitempty"`
+}
+
+func init() {
+	Register(TypeSpec{
+		Type:  Custom,
+		Name:  "Custom Fields",
+		New:   func() Info { return &InfoCustom{} },
+		Check: checkCustom,
+	})
+}
+
+func checkCustom(info Info) FieldErrors {
+	custom, ok := info.(*InfoCustom)
+	if ok && len(custom.Custom) == 0 {
+		return FieldErrors{{Field: "custom_fields", Message: "are required"}}
+	}
+	return nil
+}
+
+// validKind reports whether the user can choose the kind for custom fields
+func validKind(kind FieldKind) bool {
+	for _, k := range CustomFieldKinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reports invalid custom fields, their names are prefixed with custom_fields.
+func (c *CustomFields) Check() FieldErrors {
+	var fieldErrs FieldErrors
+	if len(c.Custom) > MaxCustomFields {
+		return FieldErrors{{Field: "custom_fields", Message: fmt.Sprintf("are more than %d", MaxCustomFields)}}
+	}
+	names := make(map[string]bool, len(c.Custom))
+	for _, custom := range c.Custom {
+		name := "custom_fields." + custom.Name
+		switch {
+		case strings.TrimSpace(custom.Name) == "":
+			fieldErrs = append(fieldErrs, FieldError{Field: "custom_fields", Message: "must have names"})
+		case names[custom.Name]:
+			fieldErrs = append(fieldErrs, FieldError{Field: name, Message: "is duplicated"})
+		case !validKind(custom.Kind):
+			fieldErrs = append(fieldErrs, FieldError{Field: name, Message: "has unknown kind"})
+		default:
+			if err := custom.Field().Check(custom.Value); err != nil {
+				fieldErrs = append(fieldErrs, FieldError{Field: name, Message: err.Error()})
+			}
+		}
+		names[custom.Name] = true
+	}
+	return fieldErrs
+}
+
+// Field describes the custom field as a field of a secret type
+func (f CustomField) Field() Field {
+	return Field{Name: f.Name, Label: f.Name, Kind: f.Kind, Masked: f.Kind == KindHidden}
+}
+
+// Format returns the custom fields as text, values of hidden fields are masked
+func (c *CustomFields) Format() string {
+	var b strings.Builder
+	for _, custom := range c.Custom {
+		value := custom.Value
+		if custom.Kind == KindHidden {
+			value = strings.Repeat("*", 8)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", custom.Name, value)
+	}
+	return b.String()
+}
@@ -6,7 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/bruteforce"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/types"
 	"github.com/jackc/pgconn"
@@ -22,8 +26,10 @@ var (
 	ErrScanData           = errors.New("error while scan user ID")
 	ErrInvalidUser        = errors.New("error user is invalid")
 	ErrKeyNotFound        = errors.New("error user ID not found")
-	selectDataStmt string = `SELECT data FROM keeper WHERE type=$1 AND login=$2 AND name=$3`
-	selectUserStmt string = `SELECT id, login, password_hash FROM users WHERE login=$1`
+	selectDataStmt string = `SELECT k.data FROM keeper k WHERE k.type=$1 AND k.login=$2 AND k.name=$3 AND (k.login=$4 OR EXISTS (
+		SELECT 1 FROM shares s JOIN users u ON u.id=s.user_id WHERE s.keeper_id=k.id AND u.login=$4))`
+	selectUserStmt string = `SELECT id, login, password_hash, totp_secret, totp_enabled, totp_counter, vault_key, public_key, private_key,
+		recovery_vault_key, recovery_hash FROM users WHERE login=$1`
 )
 
 type DataBase struct {
@@ -70,7 +76,64 @@ func (d *DataBase) Migrate() error {
 	return nil
 }
 
+// UpdateData updates secret of its owner or of another user who shared it with read-write access
+func (d *DataBase) UpdateData(encryptedData []byte, metadata storage.InfoMeta) error {
+	if metadata.InCollection() {
+		return d.updateCollectionData(encryptedData, metadata)
+	}
+	query := `UPDATE keeper k SET data=$1 WHERE k.login=$2 AND k.type=$3 AND k.name=$4 AND (k.login=$5 OR EXISTS (
+		SELECT 1 FROM shares s JOIN users u ON u.id=s.user_id WHERE s.keeper_id=k.id AND u.login=$5 AND s.access=$6))`
+	res, err := d.db.ExecContext(d.ctx, query, encryptedData, metadata.OwnerLogin(), metadata.Type, metadata.Name,
+		metadata.Login, storage.AccessReadWrite)
+	if err != nil {
+		return fmt.Errorf("error while updating row in database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		if !metadata.Shared() {
+			return storage.ErrDataNotFound
+		}
+		// read-only grant exists if the secret is still visible to the user
+		if _, err = d.GetData(metadata); err == nil {
+			return storage.ErrAccessDenied
+		}
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// DeleteData deletes the owner's secret with copies sealed for other users
+func (d *DataBase) DeleteData(metadata storage.InfoMeta) error {
+	if metadata.InCollection() {
+		return d.deleteCollectionData(metadata)
+	}
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return types.ErrAlarm
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(d.ctx, `DELETE FROM keeper WHERE login=$1 AND type=$2 AND name=$3`,
+		metadata.Login, metadata.Type, metadata.Name)
+	if err != nil {
+		return fmt.Errorf("error while deleting row from database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+	_, err = tx.ExecContext(d.ctx, `DELETE FROM sealed_shares s USING users u
+		WHERE s.owner_id=u.id AND u.login=$1 AND s.type=$2 AND s.name=$3`, metadata.Login, metadata.Type, metadata.Name)
+	if err != nil {
+		return fmt.Errorf("error while deleting sealed shares from database: %w", err)
+	}
+
+	return tx.Commit()
+}
+
 func (d *DataBase) SaveData(encryptedData []byte, metadata storage.InfoMeta) error {
+	if metadata.InCollection() {
+		return d.saveCollectionData(encryptedData, metadata)
+	}
 	_, err := d.db.ExecContext(d.ctx, `INSERT INTO keeper (data, login, type, name) VALUES ($1, $2, $3, $4)`,
 		encryptedData, metadata.Login, metadata.Type, metadata.Name)
 	if err != nil {
@@ -81,6 +144,9 @@ func (d *DataBase) SaveData(encryptedData []byte, metadata storage.InfoMeta) err
 }
 
 func (d *DataBase) GetData(metadata storage.InfoMeta) ([]byte, error) {
+	if metadata.InCollection() {
+		return d.getCollectionData(metadata)
+	}
 	var data []byte
 	tx, err := d.db.BeginTx(d.ctx, nil)
 	if err != nil {
@@ -94,7 +160,7 @@ func (d *DataBase) GetData(metadata storage.InfoMeta) ([]byte, error) {
 	}
 	defer selectData.Close()
 
-	row := selectData.QueryRowContext(d.ctx, metadata.Type, metadata.Login, metadata.Name)
+	row := selectData.QueryRowContext(d.ctx, metadata.Type, metadata.OwnerLogin(), metadata.Name, metadata.Login)
 	err = row.Scan(&data)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, storage.ErrDataNotFound
@@ -105,6 +171,197 @@ func (d *DataBase) GetData(metadata storage.InfoMeta) ([]byte, error) {
 	return data, nil
 }
 
+// ShareData grants recipient access to the owner's secret, access of already shared secret is replaced
+func (d *DataBase) ShareData(metadata storage.InfoMeta, recipient string, access storage.Access) error {
+	user, err := d.GetUserData(recipient)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	query := `INSERT INTO shares (keeper_id, user_id, access)
+		SELECT id, $1, $2 FROM keeper WHERE login=$3 AND type=$4 AND name=$5
+		ON CONFLICT (keeper_id, user_id) DO UPDATE SET access=EXCLUDED.access`
+	res, err := d.db.ExecContext(d.ctx, query, user.ID, access, metadata.Login, metadata.Type, metadata.Name)
+	if err != nil {
+		return fmt.Errorf("error while inserting share into database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// RevokeShare revokes recipient's access to the owner's secret and deletes copy sealed for the recipient
+func (d *DataBase) RevokeShare(metadata storage.InfoMeta, recipient string) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return types.ErrAlarm
+	}
+	defer tx.Rollback()
+
+	var revoked int64
+	query := `DELETE FROM shares s USING keeper k, users u
+		WHERE s.keeper_id=k.id AND s.user_id=u.id AND k.login=$1 AND k.type=$2 AND k.name=$3 AND u.login=$4`
+	res, err := tx.ExecContext(d.ctx, query, metadata.Login, metadata.Type, metadata.Name, recipient)
+	if err != nil {
+		return fmt.Errorf("error while deleting share from database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil {
+		revoked += rows
+	}
+	query = `DELETE FROM sealed_shares s USING users o, users r
+		WHERE s.owner_id=o.id AND s.recipient_id=r.id AND o.login=$1 AND s.type=$2 AND s.name=$3 AND r.login=$4`
+	res, err = tx.ExecContext(d.ctx, query, metadata.Login, metadata.Type, metadata.Name, recipient)
+	if err != nil {
+		return fmt.Errorf("error while deleting sealed share from database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil {
+		revoked += rows
+	}
+	if revoked == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return tx.Commit()
+}
+
+// GetShares returns users the owner's secret is shared with
+func (d *DataBase) GetShares(metadata storage.InfoMeta) ([]storage.Share, error) {
+	query := `SELECT u.login, s.access FROM shares s JOIN keeper k ON k.id=s.keeper_id JOIN users u ON u.id=s.user_id
+		WHERE k.login=$1 AND k.type=$2 AND k.name=$3
+		UNION ALL
+		SELECT r.login, $4::VARCHAR FROM sealed_shares s JOIN users o ON o.id=s.owner_id JOIN users r ON r.id=s.recipient_id
+		WHERE o.login=$1 AND s.type=$2 AND s.name=$3
+		ORDER BY 1`
+	rows, err := d.db.QueryContext(d.ctx, query, metadata.Login, metadata.Type, metadata.Name, storage.AccessSealed)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting shares: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []storage.Share
+	for rows.Next() {
+		var share storage.Share
+		if err = rows.Scan(&share.Login, &share.Access); err != nil {
+			return nil, ErrScanData
+		}
+		shares = append(shares, share)
+	}
+	return shares, rows.Err()
+}
+
+// ListData returns names and types of the user's own secrets
+func (d *DataBase) ListData(login string) ([]storage.InfoMeta, error) {
+	rows, err := d.db.QueryContext(d.ctx, `SELECT type, name FROM keeper WHERE login=$1 ORDER BY type, name`, login)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting data: %w", err)
+	}
+	defer rows.Close()
+
+	var metas []storage.InfoMeta
+	for rows.Next() {
+		meta := storage.InfoMeta{Login: login}
+		if err = rows.Scan(&meta.Type, &meta.Name); err != nil {
+			return nil, ErrScanData
+		}
+		metas = append(metas, meta)
+	}
+	return metas, rows.Err()
+}
+
+// GetAllData returns the user's own secrets of the type with their data, secrets of all types if it is empty
+func (d *DataBase) GetAllData(login string, infoType storage.InfoType) ([]storage.OwnData, error) {
+	query := `SELECT type, name, data FROM keeper WHERE login=$1 AND ($2='' OR type=$2) ORDER BY type, name`
+	rows, err := d.db.QueryContext(d.ctx, query, login, infoType)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting data: %w", err)
+	}
+	defer rows.Close()
+
+	var all []storage.OwnData
+	for rows.Next() {
+		own := storage.OwnData{Meta: storage.InfoMeta{Login: login}}
+		if err = rows.Scan(&own.Meta.Type, &own.Meta.Name, &own.Data); err != nil {
+			return nil, ErrScanData
+		}
+		all = append(all, own)
+	}
+	return all, rows.Err()
+}
+
+// GetSharedWithMe returns secrets of other users shared with the user
+func (d *DataBase) GetSharedWithMe(login string) ([]storage.SharedInfo, error) {
+	query := `SELECT k.name, k.type, k.login, s.access FROM shares s JOIN keeper k ON k.id=s.keeper_id JOIN users u ON u.id=s.user_id
+		WHERE u.login=$1
+		UNION ALL
+		SELECT s.name, s.type, o.login, $2::VARCHAR FROM sealed_shares s JOIN users o ON o.id=s.owner_id JOIN users r ON r.id=s.recipient_id
+		WHERE r.login=$1
+		ORDER BY 3, 2, 1`
+	rows, err := d.db.QueryContext(d.ctx, query, login, storage.AccessSealed)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting shared secrets: %w", err)
+	}
+	defer rows.Close()
+
+	var infos []storage.SharedInfo
+	for rows.Next() {
+		var info storage.SharedInfo
+		if err = rows.Scan(&info.Name, &info.Type, &info.Owner, &info.Access); err != nil {
+			return nil, ErrScanData
+		}
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+// SaveSealedShare stores copy of the owner's secret sealed for the recipient, the previous copy is replaced
+func (d *DataBase) SaveSealedShare(share storage.SealedShare) error {
+	user, err := d.GetUserData(share.Recipient)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	query := `INSERT INTO sealed_shares (owner_id, recipient_id, type, name, sealed_key, data)
+		SELECT u.id, $1, k.type, k.name, $2, $3 FROM keeper k JOIN users u ON u.login=k.login
+		WHERE k.login=$4 AND k.type=$5 AND k.name=$6
+		ON CONFLICT (owner_id, recipient_id, type, name) DO UPDATE SET sealed_key=EXCLUDED.sealed_key, data=EXCLUDED.data, created_at=NOW()`
+	res, err := d.db.ExecContext(d.ctx, query, user.ID, share.SealedKey, share.Data, share.Owner, share.Type, share.Name)
+	if err != nil {
+		return fmt.Errorf("error while inserting sealed share into database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// GetSealedShare returns copy of the owner's secret sealed for the user
+func (d *DataBase) GetSealedShare(metadata storage.InfoMeta) (storage.SealedShare, error) {
+	share := storage.SealedShare{
+		Name:      metadata.Name,
+		Type:      metadata.Type,
+		Owner:     metadata.OwnerLogin(),
+		Recipient: metadata.Login,
+	}
+	query := `SELECT s.sealed_key, s.data FROM sealed_shares s JOIN users o ON o.id=s.owner_id JOIN users r ON r.id=s.recipient_id
+		WHERE o.login=$1 AND r.login=$2 AND s.type=$3 AND s.name=$4`
+	row := d.db.QueryRowContext(d.ctx, query, share.Owner, share.Recipient, share.Type, share.Name)
+	err := row.Scan(&share.SealedKey, &share.Data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return share, storage.ErrDataNotFound
+	}
+	if err != nil {
+		return share, fmt.Errorf("error while selecting sealed share: %w", err)
+	}
+	return share, nil
+}
+
 func (d *DataBase) Close() {
 	d.db.Close()
 }
@@ -124,7 +381,8 @@ func (d *DataBase) FindUser(login string) (*types.User, error) {
 	defer selectUser.Close()
 
 	row := selectUser.QueryRowContext(d.ctx, login)
-	err = row.Scan(&user.ID, &user.Login, &user.HashPassword)
+	err = row.Scan(&user.ID, &user.Login, &user.HashPassword, &user.TOTPSecret, &user.TOTPEnabled, &user.TOTPCounter, &user.VaultKey, &user.PublicKey, &user.PrivateKey,
+		&user.RecoveryVaultKey, &user.RecoveryHash)
 	if err != nil {
 		return nil, ErrInvalidUser
 	}
@@ -132,13 +390,12 @@ func (d *DataBase) FindUser(login string) (*types.User, error) {
 	return &user, nil
 }
 
-func (d *DataBase) RegisterNewUser(login string, password string) (types.User, error) {
-	user := types.User{
-		Login:        login,
-		HashPassword: password,
-	}
-	query := `INSERT INTO users (login, password_hash) VALUES ($1, $2) returning id`
-	row := d.db.QueryRowContext(context.Background(), query, login, password)
+// RegisterNewUser inserts the user with the vault key and the recovery key, if it is set, in one statement
+func (d *DataBase) RegisterNewUser(user types.User) (types.User, error) {
+	query := `INSERT INTO users (login, password_hash, vault_key, recovery_vault_key, recovery_hash)
+		VALUES ($1, $2, $3, $4, $5) returning id`
+	row := d.db.QueryRowContext(context.Background(), query,
+		user.Login, user.HashPassword, user.VaultKey, user.RecoveryVaultKey, user.RecoveryHash)
 	if err := row.Scan(&user.ID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return types.User{}, ErrKeyNotFound
@@ -177,9 +434,346 @@ func (d *DataBase) GetUserData(login string) (types.User, error) {
 	}()
 
 	row := selectUserStmt.QueryRow(login)
-	err = row.Scan(&user.ID, &user.Login, &user.HashPassword)
+	err = row.Scan(&user.ID, &user.Login, &user.HashPassword, &user.TOTPSecret, &user.TOTPEnabled, &user.TOTPCounter, &user.VaultKey, &user.PublicKey, &user.PrivateKey,
+		&user.RecoveryVaultKey, &user.RecoveryHash)
 	if errors.Is(err, sql.ErrNoRows) {
 		return types.User{}, nil
 	}
 	return user, err
 }
+
+// SetTOTPSecret stores pending TOTP secret and replaces user's backup codes
+func (d *DataBase) SetTOTPSecret(userID int, secret string, backupCodeHashes []string) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return types.ErrAlarm
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(d.ctx, `UPDATE users SET totp_secret=$1 WHERE id=$2 AND NOT totp_enabled`, secret, userID)
+	if err != nil {
+		return fmt.Errorf("error while updating totp secret: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return types.ErrTOTPEnabled
+	}
+	_, err = tx.ExecContext(d.ctx, `DELETE FROM totp_backup_codes WHERE user_id=$1`, userID)
+	if err != nil {
+		return fmt.Errorf("error while deleting backup codes: %w", err)
+	}
+	insertCode, err := tx.PrepareContext(d.ctx, `INSERT INTO totp_backup_codes (user_id, code_hash) VALUES ($1, $2)`)
+	if err != nil {
+		return types.ErrAlarm2
+	}
+	defer insertCode.Close()
+	for _, hash := range backupCodeHashes {
+		if _, err = insertCode.ExecContext(d.ctx, userID, hash); err != nil {
+			return fmt.Errorf("error while inserting backup code: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (d *DataBase) EnableTOTP(userID int) error {
+	_, err := d.db.ExecContext(d.ctx, `UPDATE users SET totp_enabled=TRUE WHERE id=$1 AND totp_secret<>''`, userID)
+	if err != nil {
+		return fmt.Errorf("error while enabling totp: %w", err)
+	}
+
+	return nil
+}
+
+// UseBackupCode deletes backup code and reports whether it existed
+func (d *DataBase) UseBackupCode(userID int, codeHash string) (bool, error) {
+	res, err := d.db.ExecContext(d.ctx, `DELETE FROM totp_backup_codes WHERE user_id=$1 AND code_hash=$2`, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("error while using backup code: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error while using backup code: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// SetTOTPCounter stores time step of accepted TOTP code only if it is after the last one, so concurrent replays fail
+func (d *DataBase) SetTOTPCounter(userID int, counter int64) (bool, error) {
+	res, err := d.db.ExecContext(d.ctx, `UPDATE users SET totp_counter=$1 WHERE id=$2 AND totp_counter<$1`, counter, userID)
+	if err != nil {
+		return false, fmt.Errorf("error while setting totp counter: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error while setting totp counter: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (d *DataBase) CreateChallenge(userID int, challengeID string, expiresAt time.Time) error {
+	_, err := d.db.ExecContext(d.ctx, `INSERT INTO totp_challenges (id, user_id, expires_at) VALUES ($1, $2, $3)`,
+		challengeID, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("error while inserting challenge: %w", err)
+	}
+
+	return nil
+}
+
+// UseChallenge counts attempt in the same statement which checks the limit, so parallel attempts can't exceed it
+func (d *DataBase) UseChallenge(challengeID string, maxAttempts int) (bool, error) {
+	res, err := d.db.ExecContext(d.ctx, `UPDATE totp_challenges SET attempts=attempts+1
+		WHERE id=$1 AND attempts<$2 AND expires_at > NOW()`, challengeID, maxAttempts)
+	if err != nil {
+		return false, fmt.Errorf("error while using challenge: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error while using challenge: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (d *DataBase) DeleteChallenge(challengeID string) error {
+	_, err := d.db.ExecContext(d.ctx, `DELETE FROM totp_challenges WHERE id=$1 OR expires_at < NOW()`, challengeID)
+	if err != nil {
+		return fmt.Errorf("error while deleting challenge: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DataBase) CreateSession(userID int, sessionID string, expiresAt time.Time) error {
+	_, err := d.db.ExecContext(d.ctx, `INSERT INTO sessions (id, user_id, expires_at) VALUES ($1, $2, $3)`,
+		sessionID, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("error while inserting session: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DataBase) SessionActive(sessionID string) (bool, error) {
+	var active bool
+	row := d.db.QueryRowContext(d.ctx, `SELECT NOT revoked AND expires_at > NOW() FROM sessions WHERE id=$1`, sessionID)
+	err := row.Scan(&active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error while selecting session: %w", err)
+	}
+
+	return active, nil
+}
+
+// ChangePassword updates password hash and wrapped vault key and revokes user's sessions except keepSessionID in one transaction
+func (d *DataBase) ChangePassword(userID int, hash string, vaultKey []byte, keepSessionID string) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return types.ErrAlarm
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(d.ctx, `UPDATE users SET password_hash=$1, vault_key=COALESCE($2, vault_key) WHERE id=$3`,
+		hash, vaultKey, userID)
+	if err != nil {
+		return fmt.Errorf("error while updating password: %w", err)
+	}
+	_, err = tx.ExecContext(d.ctx, `UPDATE sessions SET revoked=TRUE WHERE user_id=$1 AND id<>$2`, userID, keepSessionID)
+	if err != nil {
+		return fmt.Errorf("error while revoking sessions: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetVaultKey stores wrapped vault key if the user has none yet
+func (d *DataBase) SetVaultKey(userID int, vaultKey []byte) error {
+	res, err := d.db.ExecContext(d.ctx, `UPDATE users SET vault_key=$1 WHERE id=$2 AND vault_key IS NULL`, vaultKey, userID)
+	if err != nil {
+		return fmt.Errorf("error while setting vault key: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return types.ErrVaultKeySet
+	}
+
+	return nil
+}
+
+// SetKeyPair stores user's key pair, key pair can't be replaced as secrets sealed for the public key would be lost
+func (d *DataBase) SetKeyPair(userID int, publicKey []byte, privateKey []byte) error {
+	res, err := d.db.ExecContext(d.ctx, `UPDATE users SET public_key=$1, private_key=$2 WHERE id=$3 AND public_key IS NULL`,
+		publicKey, privateKey, userID)
+	if err != nil {
+		return fmt.Errorf("error while setting key pair: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return types.ErrKeyPairSet
+	}
+
+	return nil
+}
+
+// SetRecoveryKey stores vault key wrapped with the recovery key and hash of its verifier, the previous recovery key stops working
+func (d *DataBase) SetRecoveryKey(userID int, vaultKey []byte, verifierHash string) error {
+	_, err := d.db.ExecContext(d.ctx, `UPDATE users SET recovery_vault_key=$1, recovery_hash=$2 WHERE id=$3`,
+		vaultKey, verifierHash, userID)
+	if err != nil {
+		return fmt.Errorf("error while setting recovery key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUser removes the user with user's secrets, sessions, backup codes, challenges, failed attempts and audit log in one transaction.
+// Organizations where the user is the only member are deleted too; if the user is the last owner of
+// an organization that has other members, nothing is removed and orgs.ErrLastOwner is returned
+func (d *DataBase) DeleteUser(userID int, login string) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return types.ErrAlarm
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(d.ctx, `DELETE FROM organizations o
+		WHERE EXISTS (SELECT 1 FROM org_members m WHERE m.org_id=o.id AND m.user_id=$1)
+		AND NOT EXISTS (SELECT 1 FROM org_members m WHERE m.org_id=o.id AND m.user_id<>$1)`, userID)
+	if err != nil {
+		return fmt.Errorf("error while deleting user's organizations: %w", err)
+	}
+	var lastOwner int
+	row := tx.QueryRowContext(d.ctx, `SELECT COUNT(*) FROM org_members m
+		WHERE m.user_id=$1 AND m.role=$2
+		AND NOT EXISTS (SELECT 1 FROM org_members o WHERE o.org_id=m.org_id AND o.user_id<>$1 AND o.role=$2)`,
+		userID, orgs.RoleOwner)
+	if err = row.Scan(&lastOwner); err != nil {
+		return fmt.Errorf("error while checking user's organizations: %w", err)
+	}
+	if lastOwner > 0 {
+		return orgs.ErrLastOwner
+	}
+
+	// keeper rows are tied to the user by login only, so they are not removed by cascade
+	queries := []struct {
+		query string
+		arg   any
+	}{
+		{query: `DELETE FROM keeper WHERE login=$1`, arg: login},
+		{query: `DELETE FROM sessions WHERE user_id=$1`, arg: userID},
+		{query: `DELETE FROM totp_backup_codes WHERE user_id=$1`, arg: userID},
+		{query: `DELETE FROM totp_challenges WHERE user_id=$1`, arg: userID},
+		{query: `DELETE FROM audit_log WHERE login=$1`, arg: login},
+		{query: `DELETE FROM auth_attempts WHERE key=$1`, arg: bruteforce.LoginKey(login)},
+		{query: `DELETE FROM users WHERE id=$1`, arg: userID},
+	}
+	for _, q := range queries {
+		if _, err = tx.ExecContext(d.ctx, q.query, q.arg); err != nil {
+			return fmt.Errorf("error while deleting user data: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (d *DataBase) IncrementFailures(key string, now time.Time, since time.Time) (int, error) {
+	var failures int
+	query := `INSERT INTO auth_attempts (key, failures, last_failure) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN auth_attempts.last_failure < $3 THEN 1 ELSE auth_attempts.failures + 1 END,
+			last_failure = $2
+		RETURNING failures`
+	row := d.db.QueryRowContext(d.ctx, query, key, now, since)
+	if err := row.Scan(&failures); err != nil {
+		return 0, fmt.Errorf("error while incrementing failures: %w", err)
+	}
+
+	return failures, nil
+}
+
+func (d *DataBase) SetBlockedUntil(key string, until time.Time) error {
+	_, err := d.db.ExecContext(d.ctx, `UPDATE auth_attempts SET blocked_until=$1 WHERE key=$2`, until, key)
+	if err != nil {
+		return fmt.Errorf("error while updating blocked until: %w", err)
+	}
+
+	return nil
+}
+
+// GetBlockedUntil returns zero time if the key is not blocked
+func (d *DataBase) GetBlockedUntil(key string) (time.Time, error) {
+	var until sql.NullTime
+	row := d.db.QueryRowContext(d.ctx, `SELECT blocked_until FROM auth_attempts WHERE key=$1`, key)
+	err := row.Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error while selecting blocked until: %w", err)
+	}
+
+	return until.Time, nil
+}
+
+func (d *DataBase) ResetFailures(key string) error {
+	_, err := d.db.ExecContext(d.ctx, `DELETE FROM auth_attempts WHERE key=$1`, key)
+	if err != nil {
+		return fmt.Errorf("error while resetting failures: %w", err)
+	}
+
+	return nil
+}
+
+// AppendAudit links entry to the login's last entry, concurrent appends are serialized by advisory lock
+func (d *DataBase) AppendAudit(e audit.Entry) (audit.Entry, error) {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return e, types.ErrAlarm
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(d.ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, e.Login); err != nil {
+		return e, fmt.Errorf("error while locking audit log: %w", err)
+	}
+	row := tx.QueryRowContext(d.ctx, `SELECT hash FROM audit_log WHERE login=$1 ORDER BY id DESC LIMIT 1`, e.Login)
+	err = row.Scan(&e.PrevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e, fmt.Errorf("error while selecting last audit entry: %w", err)
+	}
+	e.Hash = e.ComputeHash()
+	query := `INSERT INTO audit_log (login, actor, action, success, ip, user_agent, secret_type, secret_name, target, created_at, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
+	row = tx.QueryRowContext(d.ctx, query, e.Login, e.Actor, e.Action, e.Success, e.IP, e.UserAgent,
+		e.SecretType, e.SecretName, e.Target, e.CreatedAt, e.PrevHash, e.Hash)
+	if err = row.Scan(&e.ID); err != nil {
+		return e, fmt.Errorf("error while inserting audit entry: %w", err)
+	}
+
+	return e, tx.Commit()
+}
+
+func (d *DataBase) GetAudit(login string, beforeID int64, limit int) ([]audit.Entry, error) {
+	query := `SELECT id, login, actor, action, success, ip, user_agent, secret_type, secret_name, target, created_at, prev_hash, hash
+		FROM audit_log WHERE login=$1 AND ($2=0 OR id<$2) ORDER BY id DESC LIMIT $3`
+	rows, err := d.db.QueryContext(d.ctx, query, login, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]audit.Entry, 0, limit)
+	for rows.Next() {
+		var e audit.Entry
+		err = rows.Scan(&e.ID, &e.Login, &e.Actor, &e.Action, &e.Success, &e.IP, &e.UserAgent,
+			&e.SecretType, &e.SecretName, &e.Target, &e.CreatedAt, &e.PrevHash, &e.Hash)
+		if err != nil {
+			return nil, ErrScanData
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+const selectEmergencyContactsStmt = `SELECT o.login, c.login, e.wait_hours, e.status, e.requested_at FROM emergency_contacts e
+	JOIN users o ON o.id=e.owner_id JOIN users c ON c.id=e.contact_id`
+
+// AddEmergencyContact adds trusted contact or changes waiting period of the existing one
+func (d *DataBase) AddEmergencyContact(owner string, contact string, waitHours int) error {
+	query := `INSERT INTO emergency_contacts (owner_id, contact_id, wait_hours, status)
+		SELECT o.id, c.id, $3, $4 FROM users o, users c WHERE o.login=$1 AND c.login=$2
+		ON CONFLICT (owner_id, contact_id) DO UPDATE SET wait_hours=EXCLUDED.wait_hours`
+	res, err := d.db.ExecContext(d.ctx, query, owner, contact, waitHours, emergency.StatusIdle)
+	if err != nil {
+		return fmt.Errorf("error while inserting emergency contact: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) RemoveEmergencyContact(owner string, contact string) error {
+	query := `DELETE FROM emergency_contacts e USING users o, users c
+		WHERE e.owner_id=o.id AND e.contact_id=c.id AND o.login=$1 AND c.login=$2`
+	res, err := d.db.ExecContext(d.ctx, query, owner, contact)
+	if err != nil {
+		return fmt.Errorf("error while deleting emergency contact: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) GetEmergencyContact(owner string, contact string) (emergency.Contact, error) {
+	contacts, err := d.selectEmergencyContacts(` WHERE o.login=$1 AND c.login=$2`, owner, contact)
+	if err != nil {
+		return emergency.Contact{}, err
+	}
+	if len(contacts) == 0 {
+		return emergency.Contact{}, storage.ErrDataNotFound
+	}
+	return contacts[0], nil
+}
+
+func (d *DataBase) GetEmergencyContacts(owner string) ([]emergency.Contact, error) {
+	return d.selectEmergencyContacts(` WHERE o.login=$1 ORDER BY c.login`, owner)
+}
+
+func (d *DataBase) GetEmergencyGrantors(contact string) ([]emergency.Contact, error) {
+	return d.selectEmergencyContacts(` WHERE c.login=$1 ORDER BY o.login`, contact)
+}
+
+func (d *DataBase) SetEmergencyStatus(contact emergency.Contact) error {
+	var requestedAt sql.NullTime
+	if !contact.RequestedAt.IsZero() {
+		requestedAt = sql.NullTime{Time: contact.RequestedAt, Valid: true}
+	}
+	query := `UPDATE emergency_contacts e SET status=$3, requested_at=$4 FROM users o, users c
+		WHERE e.owner_id=o.id AND e.contact_id=c.id AND o.login=$1 AND c.login=$2`
+	res, err := d.db.ExecContext(d.ctx, query, contact.Owner, contact.Contact, contact.Status, requestedAt)
+	if err != nil {
+		return fmt.Errorf("error while updating emergency contact: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) selectEmergencyContacts(where string, args ...any) ([]emergency.Contact, error) {
+	rows, err := d.db.QueryContext(d.ctx, selectEmergencyContactsStmt+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting emergency contacts: %w", err)
+	}
+	defer rows.Close()
+
+	var contacts []emergency.Contact
+	for rows.Next() {
+		var (
+			contact     emergency.Contact
+			requestedAt sql.NullTime
+		)
+		if err = rows.Scan(&contact.Owner, &contact.Contact, &contact.WaitHours, &contact.Status, &requestedAt); err != nil {
+			return nil, ErrScanData
+		}
+		if requestedAt.Valid {
+			contact.RequestedAt = requestedAt.Time.UTC()
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, rows.Err()
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/types"
+)
+
+func (d *DataBase) CreateLink(tokenHash []byte, link storage.Link) error {
+	query := `INSERT INTO links (token_hash, owner_id, type, name, data, views_left, expires_at)
+		SELECT $1, id, $2, $3, $4, $5, $6 FROM users WHERE login=$7`
+	res, err := d.db.ExecContext(d.ctx, query, tokenHash, link.Type, link.Name, link.Data, link.ViewsLeft, link.ExpiresAt, link.Owner)
+	if err != nil {
+		return fmt.Errorf("error while inserting link into database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ConsumeLink uses one view of the link, expired links are purged on the way
+func (d *DataBase) ConsumeLink(tokenHash []byte) (storage.Link, error) {
+	var link storage.Link
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return link, types.ErrAlarm
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(d.ctx, `DELETE FROM links WHERE expires_at <= NOW()`); err != nil {
+		return link, fmt.Errorf("error while deleting expired links: %w", err)
+	}
+	query := `UPDATE links l SET views_left=l.views_left-1 FROM users u WHERE l.token_hash=$1 AND u.id=l.owner_id
+		RETURNING u.login, l.type, l.name, l.data, l.views_left, l.expires_at`
+	err = tx.QueryRowContext(d.ctx, query, tokenHash).Scan(&link.Owner, &link.Type, &link.Name, &link.Data, &link.ViewsLeft, &link.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return link, storage.ErrDataNotFound
+	}
+	if err != nil {
+		return link, fmt.Errorf("error while updating link: %w", err)
+	}
+	if link.ViewsLeft <= 0 {
+		if _, err = tx.ExecContext(d.ctx, `DELETE FROM links WHERE token_hash=$1`, tokenHash); err != nil {
+			return link, fmt.Errorf("error while deleting used link: %w", err)
+		}
+	}
+
+	return link, tx.Commit()
+}
@@ -0,0 +1,3 @@
+DROP TABLE totp_backup_codes;
+ALTER TABLE users DROP COLUMN totp_enabled;
+ALTER TABLE users DROP COLUMN totp_secret
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE sessions;
+ALTER TABLE users DROP COLUMN vault_key
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS vault_key BYTEA;
+CREATE TABLE IF NOT EXISTS sessions(
+		id VARCHAR PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		expires_at TIMESTAMP NOT NULL,
+		revoked BOOLEAN NOT NULL DEFAULT FALSE
+)
\ No newline at end of file
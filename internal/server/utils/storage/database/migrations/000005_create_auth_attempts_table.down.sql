@@ -0,0 +1 @@
+DROP TABLE auth_attempts
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS auth_attempts(
+		key VARCHAR PRIMARY KEY,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_failure TIMESTAMPTZ NOT NULL,
+		blocked_until TIMESTAMPTZ
+)
\ No newline at end of file
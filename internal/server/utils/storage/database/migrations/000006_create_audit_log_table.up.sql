@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS audit_log(
+		id BIGSERIAL PRIMARY KEY,
+		login VARCHAR NOT NULL,
+		actor VARCHAR NOT NULL,
+		action VARCHAR(32) NOT NULL,
+		success BOOLEAN NOT NULL,
+		ip VARCHAR NOT NULL,
+		user_agent VARCHAR NOT NULL,
+		secret_type VARCHAR(16) NOT NULL DEFAULT '',
+		secret_name VARCHAR NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		prev_hash VARCHAR NOT NULL,
+		hash VARCHAR NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_log_login_idx ON audit_log(login, id);
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+		FOR EACH ROW EXECUTE FUNCTION audit_log_append_only()
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE audit_log DROP COLUMN target;
+DROP TABLE shares;
+ALTER TABLE keeper DROP COLUMN id
\ No newline at end of file
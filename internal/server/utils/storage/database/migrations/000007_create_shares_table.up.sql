@@ -0,0 +1,10 @@
+ALTER TABLE keeper ADD COLUMN IF NOT EXISTS id BIGSERIAL PRIMARY KEY;
+CREATE TABLE IF NOT EXISTS shares(
+		keeper_id BIGINT NOT NULL REFERENCES keeper(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		access VARCHAR(16) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY(keeper_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS shares_user_id_idx ON shares(user_id);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS target VARCHAR NOT NULL DEFAULT ''
\ No newline at end of file
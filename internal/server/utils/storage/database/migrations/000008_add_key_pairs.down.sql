@@ -0,0 +1,3 @@
+DROP TABLE sealed_shares;
+ALTER TABLE users DROP COLUMN private_key;
+ALTER TABLE users DROP COLUMN public_key
\ No newline at end of file
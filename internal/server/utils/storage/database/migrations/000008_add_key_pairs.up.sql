@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS public_key BYTEA;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS private_key BYTEA;
+CREATE TABLE IF NOT EXISTS sealed_shares(
+		owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		recipient_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		type VARCHAR(16) NOT NULL,
+		name VARCHAR NOT NULL,
+		sealed_key BYTEA NOT NULL,
+		data BYTEA NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY(owner_id, recipient_id, type, name)
+);
+CREATE INDEX IF NOT EXISTS sealed_shares_recipient_id_idx ON sealed_shares(recipient_id)
\ No newline at end of file
@@ -0,0 +1,9 @@
+DELETE FROM keeper WHERE collection_id IS NOT NULL;
+DROP INDEX keeper_collection_idx;
+ALTER TABLE keeper DROP CONSTRAINT keeper_owner_check;
+ALTER TABLE keeper DROP COLUMN collection_id;
+ALTER TABLE keeper ALTER COLUMN login SET NOT NULL;
+DROP TABLE collections;
+DROP TABLE org_invitations;
+DROP TABLE org_members;
+DROP TABLE organizations
\ No newline at end of file
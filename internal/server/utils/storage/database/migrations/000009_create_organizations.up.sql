@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS organizations(
+		id SERIAL PRIMARY KEY,
+		name VARCHAR UNIQUE NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS org_members(
+		org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role VARCHAR(16) NOT NULL,
+		PRIMARY KEY(org_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS org_invitations(
+		org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role VARCHAR(16) NOT NULL,
+		invited_by VARCHAR NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY(org_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS collections(
+		id SERIAL PRIMARY KEY,
+		org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		name VARCHAR NOT NULL,
+		UNIQUE(org_id, name)
+);
+ALTER TABLE keeper ALTER COLUMN login DROP NOT NULL;
+ALTER TABLE keeper ADD COLUMN IF NOT EXISTS collection_id INTEGER REFERENCES collections(id) ON DELETE CASCADE;
+ALTER TABLE keeper ADD CONSTRAINT keeper_owner_check CHECK ((login IS NULL) <> (collection_id IS NULL));
+CREATE UNIQUE INDEX IF NOT EXISTS keeper_collection_idx ON keeper(collection_id, type, name) WHERE collection_id IS NOT NULL
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS links(
+		token_hash BYTEA PRIMARY KEY,
+		owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		type VARCHAR(16) NOT NULL,
+		name VARCHAR NOT NULL,
+		data BYTEA NOT NULL,
+		views_left INTEGER NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS links_expires_at_idx ON links(expires_at)
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS emergency_contacts(
+		owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		contact_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		wait_hours INTEGER NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		requested_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY(owner_id, contact_id)
+);
+CREATE INDEX IF NOT EXISTS emergency_contacts_contact_id_idx ON emergency_contacts(contact_id)
\ No newline at end of file
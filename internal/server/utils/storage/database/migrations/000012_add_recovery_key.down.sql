@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN recovery_hash;
+ALTER TABLE users DROP COLUMN recovery_vault_key
\ No newline at end of file
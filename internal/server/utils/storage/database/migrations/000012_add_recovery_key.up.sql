@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS recovery_vault_key BYTEA;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS recovery_hash VARCHAR NOT NULL DEFAULT ''
\ No newline at end of file
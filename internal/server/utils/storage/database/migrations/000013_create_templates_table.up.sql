@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS templates(
+		owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name VARCHAR NOT NULL,
+		type VARCHAR(16) NOT NULL,
+		fields JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY(owner_id, name)
+)
\ No newline at end of file
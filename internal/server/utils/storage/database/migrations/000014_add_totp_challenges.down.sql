@@ -0,0 +1,2 @@
+DROP TABLE totp_challenges;
+ALTER TABLE users DROP COLUMN totp_counter
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_counter BIGINT NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS totp_challenges(
+		id VARCHAR PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		expires_at TIMESTAMP NOT NULL
+)
\ No newline at end of file
@@ -0,0 +1,354 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+)
+
+// selectCollectionIDStmt selects id of collection $2 of organization $1
+const selectCollectionIDStmt = `SELECT c.id FROM collections c JOIN organizations o ON o.id=c.org_id WHERE o.name=$1 AND c.name=$2`
+
+func (d *DataBase) saveCollectionData(encryptedData []byte, metadata storage.InfoMeta) error {
+	query := `INSERT INTO keeper (data, collection_id, type, name) SELECT $3, c.id, $4, $5
+		FROM collections c JOIN organizations o ON o.id=c.org_id WHERE o.name=$1 AND c.name=$2`
+	res, err := d.db.ExecContext(d.ctx, query, metadata.Org, metadata.Collection, encryptedData, metadata.Type, metadata.Name)
+	if err != nil {
+		return fmt.Errorf("error while inserting row into database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) getCollectionData(metadata storage.InfoMeta) ([]byte, error) {
+	var data []byte
+	query := `SELECT data FROM keeper WHERE collection_id=(` + selectCollectionIDStmt + `) AND type=$3 AND name=$4`
+	row := d.db.QueryRowContext(d.ctx, query, metadata.Org, metadata.Collection, metadata.Type, metadata.Name)
+	err := row.Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrDataNotFound
+	}
+	if err != nil {
+		return nil, storage.ErrInvalidData
+	}
+	return data, nil
+}
+
+func (d *DataBase) updateCollectionData(encryptedData []byte, metadata storage.InfoMeta) error {
+	query := `UPDATE keeper SET data=$5 WHERE collection_id=(` + selectCollectionIDStmt + `) AND type=$3 AND name=$4`
+	res, err := d.db.ExecContext(d.ctx, query, metadata.Org, metadata.Collection, metadata.Type, metadata.Name, encryptedData)
+	if err != nil {
+		return fmt.Errorf("error while updating row in database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) deleteCollectionData(metadata storage.InfoMeta) error {
+	query := `DELETE FROM keeper WHERE collection_id=(` + selectCollectionIDStmt + `) AND type=$3 AND name=$4`
+	res, err := d.db.ExecContext(d.ctx, query, metadata.Org, metadata.Collection, metadata.Type, metadata.Name)
+	if err != nil {
+		return fmt.Errorf("error while deleting row from database: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// CreateOrg creates organization with the user as its owner
+func (d *DataBase) CreateOrg(name string, ownerLogin string) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error while beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var orgID int
+	row := tx.QueryRowContext(d.ctx, `INSERT INTO organizations (name) VALUES ($1) RETURNING id`, name)
+	if err = row.Scan(&orgID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return orgs.ErrOrgExists
+		}
+		return fmt.Errorf("error while inserting organization: %w", err)
+	}
+	res, err := tx.ExecContext(d.ctx, `INSERT INTO org_members (org_id, user_id, role) SELECT $1, id, $2 FROM users WHERE login=$3`,
+		orgID, orgs.RoleOwner, ownerLogin)
+	if err != nil {
+		return fmt.Errorf("error while inserting organization owner: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return tx.Commit()
+}
+
+// DeleteOrg deletes organization with its members, invitations, collections and secrets
+func (d *DataBase) DeleteOrg(name string) error {
+	res, err := d.db.ExecContext(d.ctx, `DELETE FROM organizations WHERE name=$1`, name)
+	if err != nil {
+		return fmt.Errorf("error while deleting organization: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) GetUserOrgs(login string) ([]orgs.Membership, error) {
+	query := `SELECT o.name, m.role FROM org_members m JOIN organizations o ON o.id=m.org_id JOIN users u ON u.id=m.user_id
+		WHERE u.login=$1 ORDER BY o.name`
+	rows, err := d.db.QueryContext(d.ctx, query, login)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var memberships []orgs.Membership
+	for rows.Next() {
+		var membership orgs.Membership
+		if err = rows.Scan(&membership.Org, &membership.Role); err != nil {
+			return nil, ErrScanData
+		}
+		memberships = append(memberships, membership)
+	}
+	return memberships, rows.Err()
+}
+
+func (d *DataBase) GetMemberRole(org string, login string) (orgs.Role, error) {
+	var role orgs.Role
+	query := `SELECT m.role FROM org_members m JOIN organizations o ON o.id=m.org_id JOIN users u ON u.id=m.user_id
+		WHERE o.name=$1 AND u.login=$2`
+	err := d.db.QueryRowContext(d.ctx, query, org, login).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error while selecting member role: %w", err)
+	}
+	return role, nil
+}
+
+func (d *DataBase) GetMembers(org string) ([]orgs.Member, error) {
+	query := `SELECT u.login, m.role FROM org_members m JOIN organizations o ON o.id=m.org_id JOIN users u ON u.id=m.user_id
+		WHERE o.name=$1 ORDER BY u.login`
+	rows, err := d.db.QueryContext(d.ctx, query, org)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []orgs.Member
+	for rows.Next() {
+		var member orgs.Member
+		if err = rows.Scan(&member.Login, &member.Role); err != nil {
+			return nil, ErrScanData
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// InviteMember invites the user to join organization with the role, the invitation is replaced if it already exists
+func (d *DataBase) InviteMember(org string, login string, role orgs.Role, invitedBy string) error {
+	user, err := d.GetUserData(login)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	memberRole, err := d.GetMemberRole(org, login)
+	if err != nil {
+		return err
+	}
+	if memberRole != "" {
+		return orgs.ErrAlreadyMember
+	}
+	query := `INSERT INTO org_invitations (org_id, user_id, role, invited_by) SELECT id, $1, $2, $3 FROM organizations WHERE name=$4
+		ON CONFLICT (org_id, user_id) DO UPDATE SET role=EXCLUDED.role, invited_by=EXCLUDED.invited_by, created_at=NOW()`
+	res, err := d.db.ExecContext(d.ctx, query, user.ID, role, invitedBy, org)
+	if err != nil {
+		return fmt.Errorf("error while inserting invitation: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) GetInvitations(login string) ([]orgs.Invitation, error) {
+	query := `SELECT o.name, i.role, i.invited_by FROM org_invitations i JOIN organizations o ON o.id=i.org_id JOIN users u ON u.id=i.user_id
+		WHERE u.login=$1 ORDER BY i.created_at`
+	rows, err := d.db.QueryContext(d.ctx, query, login)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []orgs.Invitation
+	for rows.Next() {
+		var invitation orgs.Invitation
+		if err = rows.Scan(&invitation.Org, &invitation.Role, &invitation.InvitedBy); err != nil {
+			return nil, ErrScanData
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// AcceptInvitation makes the user a member with the role from the invitation
+func (d *DataBase) AcceptInvitation(org string, login string) error {
+	tx, err := d.db.BeginTx(d.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error while beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM org_invitations i USING organizations o, users u
+		WHERE i.org_id=o.id AND i.user_id=u.id AND o.name=$1 AND u.login=$2 RETURNING i.org_id, i.user_id, i.role`
+	var (
+		orgID, userID int
+		role          orgs.Role
+	)
+	err = tx.QueryRowContext(d.ctx, query, org, login).Scan(&orgID, &userID, &role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrDataNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error while deleting invitation: %w", err)
+	}
+	_, err = tx.ExecContext(d.ctx, `INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (org_id, user_id) DO NOTHING`, orgID, userID, role)
+	if err != nil {
+		return fmt.Errorf("error while inserting member: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (d *DataBase) DeclineInvitation(org string, login string) error {
+	query := `DELETE FROM org_invitations i USING organizations o, users u
+		WHERE i.org_id=o.id AND i.user_id=u.id AND o.name=$1 AND u.login=$2`
+	res, err := d.db.ExecContext(d.ctx, query, org, login)
+	if err != nil {
+		return fmt.Errorf("error while deleting invitation: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) SetMemberRole(org string, login string, role orgs.Role) error {
+	query := `UPDATE org_members m SET role=$1 FROM organizations o, users u
+		WHERE m.org_id=o.id AND m.user_id=u.id AND o.name=$2 AND u.login=$3`
+	res, err := d.db.ExecContext(d.ctx, query, role, org, login)
+	if err != nil {
+		return fmt.Errorf("error while updating member role: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) RemoveMember(org string, login string) error {
+	query := `DELETE FROM org_members m USING organizations o, users u
+		WHERE m.org_id=o.id AND m.user_id=u.id AND o.name=$1 AND u.login=$2`
+	res, err := d.db.ExecContext(d.ctx, query, org, login)
+	if err != nil {
+		return fmt.Errorf("error while deleting member: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) CreateCollection(org string, name string) error {
+	res, err := d.db.ExecContext(d.ctx, `INSERT INTO collections (org_id, name) SELECT id, $1 FROM organizations WHERE name=$2`, name, org)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return storage.ErrInvalidData
+		}
+		return fmt.Errorf("error while inserting collection: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// DeleteCollection deletes collection with all of its secrets
+func (d *DataBase) DeleteCollection(org string, name string) error {
+	res, err := d.db.ExecContext(d.ctx, `DELETE FROM collections WHERE id=(`+selectCollectionIDStmt+`)`, org, name)
+	if err != nil {
+		return fmt.Errorf("error while deleting collection: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) GetCollections(org string) ([]string, error) {
+	query := `SELECT c.name FROM collections c JOIN organizations o ON o.id=c.org_id WHERE o.name=$1 ORDER BY c.name`
+	rows, err := d.db.QueryContext(d.ctx, query, org)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, ErrScanData
+		}
+		collections = append(collections, name)
+	}
+	return collections, rows.Err()
+}
+
+// GetCollectionData returns names and types of secrets in the collection
+func (d *DataBase) GetCollectionData(org string, collection string) ([]storage.InfoMeta, error) {
+	query := `SELECT type, name FROM keeper WHERE collection_id=(` + selectCollectionIDStmt + `) ORDER BY type, name`
+	rows, err := d.db.QueryContext(d.ctx, query, org, collection)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting collection data: %w", err)
+	}
+	defer rows.Close()
+
+	var metas []storage.InfoMeta
+	for rows.Next() {
+		meta := storage.InfoMeta{Org: org, Collection: collection}
+		if err = rows.Scan(&meta.Type, &meta.Name); err != nil {
+			return nil, ErrScanData
+		}
+		metas = append(metas, meta)
+	}
+	return metas, rows.Err()
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+func (d *DataBase) SaveTemplate(owner string, template storage.Template) error {
+	fields, err := json.Marshal(template.Fields)
+	if err != nil {
+		return fmt.Errorf("error while marshalling template fields: %w", err)
+	}
+	query := `INSERT INTO templates (owner_id, name, type, fields) SELECT id, $2, $3, $4 FROM users WHERE login=$1
+		ON CONFLICT (owner_id, name) DO UPDATE SET type=EXCLUDED.type, fields=EXCLUDED.fields`
+	res, err := d.db.ExecContext(d.ctx, query, owner, template.Name, template.Type, fields)
+	if err != nil {
+		return fmt.Errorf("error while inserting template: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (d *DataBase) GetTemplates(owner string) ([]storage.Template, error) {
+	query := `SELECT t.name, t.type, t.fields FROM templates t JOIN users u ON u.id=t.owner_id WHERE u.login=$1 ORDER BY t.name`
+	rows, err := d.db.QueryContext(d.ctx, query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting templates: %w", err)
+	}
+	defer rows.Close()
+	var templates []storage.Template
+	for rows.Next() {
+		var (
+			template storage.Template
+			fields   []byte
+		)
+		if err = rows.Scan(&template.Name, &template.Type, &fields); err != nil {
+			return nil, fmt.Errorf("error while scanning template: %w", err)
+		}
+		if err = json.Unmarshal(fields, &template.Fields); err != nil {
+			return nil, fmt.Errorf("error while unmarshalling template fields: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+func (d *DataBase) DeleteTemplate(owner string, name string) error {
+	query := `DELETE FROM templates t USING users u WHERE t.owner_id=u.id AND u.login=$1 AND t.name=$2`
+	res, err := d.db.ExecContext(d.ctx, query, owner, name)
+	if err != nil {
+		return fmt.Errorf("error while deleting template: %w", err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+func (d *DataBase) GetDataBatch(afterID int64, limit int) ([]storage.StoredData, error) {
+	rows, err := d.db.QueryContext(d.ctx, `SELECT id, type, data FROM keeper WHERE id>$1 ORDER BY id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while selecting data from database: %w", err)
+	}
+	defer rows.Close()
+	var batch []storage.StoredData
+	for rows.Next() {
+		var data storage.StoredData
+		if err = rows.Scan(&data.ID, &data.Type, &data.Data); err != nil {
+			return nil, fmt.Errorf("error while scanning data: %w", err)
+		}
+		batch = append(batch, data)
+	}
+
+	return batch, rows.Err()
+}
+
+// ReplaceData compares data in the same statement, so updates made by users in the meantime are kept
+func (d *DataBase) ReplaceData(id int64, oldData []byte, newData []byte) (bool, error) {
+	res, err := d.db.ExecContext(d.ctx, `UPDATE keeper SET data=$1 WHERE id=$2 AND data=$3`, newData, id, oldData)
+	if err != nil {
+		return false, fmt.Errorf("error while updating row in database: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error while getting affected rows: %w", err)
+	}
+
+	return rows != 0, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const Database InfoType = "database"
+
+// DatabaseDrivers are drivers DSNs can be rendered for
+var DatabaseDrivers = []string{"postgres", "mysql", "sqlserver", "mongodb", "redis"}
+
+// InfoDatabase is database connection credential, TLSMode and Params are in terms of the driver
+type InfoDatabase struct {
+	CustomFields
+	Rotation
+	Driver   string `json:"driver"`
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Database string `json:"database"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	// TLSMode is value of sslmode for postgres, tls for mysql, mongodb and redis, encrypt for sqlserver
+	TLSMode string `json:"tls_mode"`
+	// Params are extra connection parameters as URL query
+	Params string `json:"params"`
+}
+
+func init() {
+	Register(TypeSpec{
+		Type: Database,
+		Name: "Database",
+		New:  func() Info { return &InfoDatabase{} },
+		Fields: []Field{
+			{Name: "driver", Label: "Driver (" + strings.Join(DatabaseDrivers, ", ") + ")", Required: true, Validate: validateDriver},
+			{Name: "host", Label: "Host", Required: true},
+			{Name: "port", Label: "Port", Kind: KindNumber, Validate: validatePort},
+			{Name: "database", Label: "Database"},
+			{Name: "user", Label: "User"},
+			{Name: "password", Label: "Password", Masked: true, Generate: true},
+			{Name: "tls_mode", Label: "TLS mode"},
+			{Name: "params", Label: "Extra params (key=value&...)", Validate: validateParams},
+		},
+	})
+}
+
+func validateDriver(value string) error {
+	for _, driver := range DatabaseDrivers {
+		if value == driver {
+			return nil
+		}
+	}
+	return errors.New("is not one of " + strings.Join(DatabaseDrivers, ", "))
+}
+
+func validatePort(value string) error {
+	if port, _ := strconv.Atoi(value); port <= 0 || port > 65535 {
+		return errors.New("is not a port number")
+	}
+	return nil
+}
+
+func validateParams(value string) error {
+	if _, err := url.ParseQuery(value); err != nil {
+		return errors.New("is not a URL query")
+	}
+	return nil
+}
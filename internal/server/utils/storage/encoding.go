@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Encoded secret starts with encodingMagic, format version, length of the type and the type,
+// then schema version of the type as uvarint and the secret as JSON.
+// Legacy gob streams never start with zero byte, so they are told apart by the magic.
+const (
+	encodingMagic   = "\x00gk"
+	encodingVersion = 1
+	maxTypeLength   = 255
+)
+
+var ErrUnsupportedVersion = errors.New("error secret is encoded with unsupported version")
+
+// Encode encodes the secret with the type and its schema version
+func Encode(infoType InfoType, info Info) ([]byte, error) {
+	return registry.encode(infoType, info)
+}
+
+// Decode decodes the secret encoded by Encode or by gob before the versioned encoding
+func Decode(infoType InfoType, bin []byte) (Info, error) {
+	return registry.decode(infoType, bin)
+}
+
+// NeedsUpgrade reports whether the secret is stored in legacy encoding or with old schema version of its type
+func NeedsUpgrade(infoType InfoType, bin []byte) bool {
+	return registry.needsUpgrade(infoType, bin)
+}
+
+func (r *typeRegistry) encode(infoType InfoType, info Info) ([]byte, error) {
+	spec, ok := r.lookup(infoType)
+	if !ok || len(infoType) > maxTypeLength {
+		return nil, ErrInvalidData
+	}
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling secret: %w", err)
+	}
+	buf := bytes.NewBufferString(encodingMagic)
+	buf.WriteByte(encodingVersion)
+	buf.WriteByte(byte(len(infoType)))
+	buf.WriteString(string(infoType))
+	buf.Write(binary.AppendUvarint(nil, uint64(spec.version())))
+	buf.Write(payload)
+	return buf.Bytes(), nil
+}
+
+func (r *typeRegistry) decode(infoType InfoType, bin []byte) (Info, error) {
+	spec, ok := r.lookup(infoType)
+	if !ok {
+		return nil, ErrInvalidData
+	}
+	if IsLegacy(bin) {
+		return decodeGob(spec, bin)
+	}
+	rest := bin[len(encodingMagic):]
+	if len(rest) < 2 || rest[0] != encodingVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	typeLength := int(rest[1])
+	rest = rest[2:]
+	if len(rest) < typeLength || InfoType(rest[:typeLength]) != infoType {
+		return nil, ErrInvalidData
+	}
+	rest = rest[typeLength:]
+	version, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return nil, ErrInvalidData
+	}
+	payload := rest[n:]
+	if version > uint64(spec.version()) {
+		return nil, ErrUnsupportedVersion
+	}
+	if version < uint64(spec.version()) {
+		if spec.Migrate == nil {
+			return nil, ErrUnsupportedVersion
+		}
+		var err error
+		payload, err = spec.Migrate(int(version), payload)
+		if err != nil {
+			return nil, fmt.Errorf("error while migrating secret from version %d: %w", version, err)
+		}
+	}
+	info := spec.New()
+	if err := json.Unmarshal(payload, info); err != nil {
+		return nil, fmt.Errorf("error while unmarshalling secret: %w", err)
+	}
+	return info, nil
+}
+
+// IsLegacy reports whether the secret is encoded by gob and should be re-encoded
+func IsLegacy(bin []byte) bool {
+	return !bytes.HasPrefix(bin, []byte(encodingMagic))
+}
+
+func (r *typeRegistry) needsUpgrade(infoType InfoType, bin []byte) bool {
+	if IsLegacy(bin) {
+		return true
+	}
+	spec, ok := r.lookup(infoType)
+	if !ok {
+		return false
+	}
+	rest := bin[len(encodingMagic):]
+	if len(rest) < 2 || len(rest[2:]) < int(rest[1]) {
+		return false
+	}
+	version, n := binary.Uvarint(rest[2+int(rest[1]):])
+	return n > 0 && version < uint64(spec.version())
+}
+
+func decodeGob(spec TypeSpec, bin []byte) (Info, error) {
+	info := spec.New()
+	err := gob.NewDecoder(bytes.NewReader(bin)).Decode(info)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding binary: %w", err)
+	}
+	return info, nil
+}
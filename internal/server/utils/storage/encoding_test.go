@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	info := &InfoCard{CardNumber: "4111111111111111", Holder: "user", Date: "10/30", CVCcode: "123"}
+	bin, err := Encode(Card, info)
+	require.NoError(t, err)
+	assert.False(t, IsLegacy(bin))
+	assert.False(t, NeedsUpgrade(Card, bin))
+	assert.Contains(t, string(bin), `"card_number":"4111111111111111"`)
+
+	decoded, err := Decode(Card, bin)
+	require.NoError(t, err)
+	assert.Equal(t, info, decoded)
+
+	_, err = Decode(Text, bin)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = Encode("wrong-type", info)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	unsupported := append([]byte(nil), bin...)
+	unsupported[len(encodingMagic)] = encodingVersion + 1
+	_, err = Decode(Card, unsupported)
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	// structs of the secrets as they were encoded by gob before the versioned encoding,
+	// gob matches fields by their names, so names of the types don't matter
+	type legacyLoginPass struct {
+		Login    string
+		Password string
+	}
+	type legacyCard struct {
+		CardNumber string
+		Holder     string
+		Date       string
+		CVCcode    string
+	}
+	type legacyText struct {
+		Text string
+	}
+
+	tests := []struct {
+		name     string
+		infoType InfoType
+		legacy   any
+		want     Info
+	}{
+		{
+			name:     "login and password",
+			infoType: LoginPassword,
+			legacy:   &legacyLoginPass{Login: "user", Password: "secret"},
+			want:     &InfoLoginPass{Login: "user", Password: "secret"},
+		},
+		{
+			name:     "card",
+			infoType: Card,
+			legacy:   &legacyCard{CardNumber: "4111111111111111", Holder: "user", Date: "10/30", CVCcode: "123"},
+			want:     &InfoCard{CardNumber: "4111111111111111", Holder: "user", Date: "10/30", CVCcode: "123"},
+		},
+		{
+			name:     "text",
+			infoType: Text,
+			legacy:   &legacyText{Text: "some text"},
+			want:     &InfoText{Text: "some text"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, gob.NewEncoder(&buf).Encode(tt.legacy))
+			bin := buf.Bytes()
+			assert.True(t, IsLegacy(bin))
+			assert.True(t, NeedsUpgrade(tt.infoType, bin))
+
+			decoded, err := Decode(tt.infoType, bin)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, decoded)
+		})
+	}
+}
+
+func TestDecodeMigrate(t *testing.T) {
+	spec := TypeSpec{
+		Type:   "test-migrate",
+		New:    func() Info { return &InfoText{} },
+		Fields: []Field{{Name: "text", Label: "Text"}},
+	}
+	old, err := newTypeRegistry([]TypeSpec{spec}).encode(spec.Type, &InfoText{Text: "old text"})
+	require.NoError(t, err)
+
+	spec.Version = 2
+	spec.Migrate = func(version int, payload []byte) ([]byte, error) {
+		return []byte(strings.Replace(string(payload), "old", "new", 1)), nil
+	}
+	r := newTypeRegistry([]TypeSpec{spec})
+
+	assert.True(t, r.needsUpgrade(spec.Type, old))
+	decoded, err := r.decode(spec.Type, old)
+	require.NoError(t, err)
+	assert.Equal(t, &InfoText{Text: "new text"}, decoded)
+
+	current, err := r.encode(spec.Type, decoded)
+	require.NoError(t, err)
+	assert.False(t, r.needsUpgrade(spec.Type, current))
+	_, ok := Lookup(spec.Type)
+	assert.False(t, ok)
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const Identity InfoType = "identity"
+
+const (
+	MaxAttachments    = 5
+	MaxAttachmentSize = 5 << 20
+)
+
+// IdentityDocuments are kinds of documents the identity secret keeps
+var IdentityDocuments = []string{"passport", "id-card", "drivers-licence"}
+
+// Attachment is a file kept with the secret, e.g. scan of a document
+type Attachment struct {
+	Name      string `json:"name"`
+	MediaType string `json:"media_type"`
+	Data      []byte `json:"data"`
+}
+
+// InfoIdentity is a personal document, dates are in DateLayout
+type InfoIdentity struct {
+	CustomFields
+	Rotation
+	Document    string       `json:"document"`
+	FirstName   string       `json:"first_name"`
+	LastName    string       `json:"last_name"`
+	Number      string       `json:"number"`
+	Country     string       `json:"country"`
+	IssuedAt    string       `json:"issued_at"`
+	ExpiresAt   string       `json:"expires_at"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+func (i *InfoIdentity) ExpiresOn() (time.Time, bool) {
+	return parseDate(i.ExpiresAt)
+}
+
+func init() {
+	Register(TypeSpec{
+		Type: Identity,
+		Name: "Identity Document",
+		New:  func() Info { return &InfoIdentity{} },
+		Fields: []Field{
+			{Name: "document", Label: "Document (" + strings.Join(IdentityDocuments, ", ") + ")", Required: true, Validate: validateDocument},
+			{Name: "first_name", Label: "First name", Required: true},
+			{Name: "last_name", Label: "Last name", Required: true},
+			{Name: "number", Label: "Document number", Masked: true, Required: true},
+			{Name: "country", Label: "Issuing country (ISO code, e.g. DE)", Validate: validateCountry},
+			{Name: "issued_at", Label: "Issue date (YYYY-MM-DD)", Validate: validateDate},
+			{Name: "expires_at", Label: "Expiry date (YYYY-MM-DD)", Validate: validateDate},
+		},
+		Check: checkIdentity,
+	})
+}
+
+func validateDocument(value string) error {
+	for _, document := range IdentityDocuments {
+		if value == document {
+			return nil
+		}
+	}
+	return errors.New("is not one of " + strings.Join(IdentityDocuments, ", "))
+}
+
+func validateCountry(value string) error {
+	if len(value) != 2 || strings.ToUpper(value) != value || strings.ToLower(value) == value {
+		return errors.New("is not two letter country code")
+	}
+	return nil
+}
+
+func checkIdentity(info Info) FieldErrors {
+	identity, ok := info.(*InfoIdentity)
+	if !ok {
+		return nil
+	}
+	var fieldErrs FieldErrors
+	issued, issuedOK := parseDate(identity.IssuedAt)
+	expires, expiresOK := parseDate(identity.ExpiresAt)
+	if issuedOK && expiresOK && !expires.After(issued) {
+		fieldErrs = append(fieldErrs, FieldError{Field: "expires_at", Message: "is not after issue date"})
+	}
+	if len(identity.Attachments) > MaxAttachments {
+		fieldErrs = append(fieldErrs, FieldError{Field: "attachments", Message: fmt.Sprintf("are more than %d", MaxAttachments)})
+	}
+	for _, attachment := range identity.Attachments {
+		if attachment.Name == "" || len(attachment.Data) > MaxAttachmentSize {
+			fieldErrs = append(fieldErrs, FieldError{
+				Field:   "attachments",
+				Message: fmt.Sprintf("must have names and be at most %d MiB", MaxAttachmentSize>>20),
+			})
+			break
+		}
+	}
+	return fieldErrs
+}
@@ -1,9 +1,8 @@
 package storage
 
 import (
-	"bytes"
-	"encoding/gob"
-	"fmt"
+	"errors"
+	"time"
 )
 
 const (
@@ -13,125 +12,124 @@ const (
 	Binary        InfoType = "binary"
 )
 
+// DateLayout is layout of dates in secrets' fields
+const DateLayout = "2006-01-02"
+
 type InfoType string
 
 func (i InfoType) String() string {
-	switch i {
-	case Card:
-		return "Bank Card"
-	case LoginPassword:
-		return "Login/Password"
-	case Text:
-		return "Text"
+	if spec, ok := Lookup(i); ok {
+		return spec.Name
 	}
 	return ""
 }
 
-type Info interface {
-	MakeBinary() ([]byte, error)
-	DecodeBinary(bin []byte) error
+// builtinTypes are registered before types of the other files, so they come first in Types
+var builtinTypes = []TypeSpec{
+	{
+		Type: LoginPassword,
+		Name: "Login/Password",
+		New:  func() Info { return &InfoLoginPass{} },
+		Fields: []Field{
+			{Name: "login", Label: "Login", Required: true},
+			{Name: "password", Label: "Password", Masked: true, Required: true, Generate: true},
+		},
+		Check:  checkLoginPass,
+		Render: renderLoginPass,
+	},
+	{
+		Type: Card,
+		Name: "Bank Card",
+		New:  func() Info { return &InfoCard{} },
+		Fields: []Field{
+			{Name: "card_number", Label: "Card number", Required: true, Validate: validateCardNumber},
+			{Name: "holder", Label: "Cardholder name"},
+			{Name: "exp_date", Label: "Expiration date (MM/YY)", Required: true, Validate: validateCardExpiry},
+			{Name: "cvc", Label: "CVC code", Masked: true, Validate: validateCVC},
+		},
+		Check:  checkCard,
+		Render: renderCard,
+	},
+	{
+		Type: Text,
+		Name: "Text",
+		New:  func() Info { return &InfoText{} },
+		Fields: []Field{
+			{Name: "text", Label: "Text", Required: true},
+		},
+	},
+}
+
+// Info is a secret of a registered type, it is stored encoded by Encode
+type Info interface{}
+
+// Expiring is implemented by secrets which stop being valid on some date
+type Expiring interface {
+	// ExpiresOn returns the date the secret expires on, it is false if the date is not set
+	ExpiresOn() (time.Time, bool)
 }
 
 type InfoMeta struct {
 	Name  string   `json:"name"`
 	Type  InfoType `json:"type"`
 	Login string   `json:"user_login"`
+	// Owner is login of the user the secret belongs to if it differs from Login
+	Owner string `json:"owner,omitempty"`
+	// Org and Collection are set for secrets owned by an organization rather than by a user
+	Org        string `json:"org,omitempty"`
+	Collection string `json:"collection,omitempty"`
 }
 
-type InfoLoginPass struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
+// OwnerLogin returns login of the user the secret belongs to
+func (m InfoMeta) OwnerLogin() string {
+	if m.Owner == "" {
+		return m.Login
+	}
+	return m.Owner
 }
 
-func (p *InfoLoginPass) MakeBinary() ([]byte, error) {
-	var buff bytes.Buffer
-
-	enc := gob.NewEncoder(&buff)
-	err := enc.Encode(p)
+// InCollection reports whether the secret belongs to an organization's collection
+func (m InfoMeta) InCollection() bool {
+	return m.Collection != ""
+}
 
-	return buff.Bytes(), err
+// Shared reports whether the secret is requested by a user it is shared with
+func (m InfoMeta) Shared() bool {
+	return m.OwnerLogin() != m.Login
 }
 
-func (p *InfoLoginPass) DecodeBinary(bin []byte) error {
-	var buff bytes.Buffer
-	_, err := buff.Write(bin)
-	if err != nil {
-		return fmt.Errorf("error while writing binary into buffer: %w", err)
-	}
-	dec := gob.NewDecoder(&buff)
-	err = dec.Decode(p)
-	if err != nil {
-		return fmt.Errorf("error while decoding binary: %w", err)
-	}
-	return err
+type InfoLoginPass struct {
+	CustomFields
+	Rotation
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	// URIs are sites the login is used on, they are matched by MatchesURL
+	URIs []LoginURI `json:"uris,omitempty"`
 }
 
 type InfoCard struct {
+	CustomFields
+	Rotation
 	CardNumber string `json:"card_number"`
 	Holder     string `json:"holder"`
 	Date       string `json:"exp_date"`
 	CVCcode    string `json:"cvc"`
 }
 
-func (c *InfoCard) MakeBinary() ([]byte, error) {
-	var buff bytes.Buffer
-
-	enc := gob.NewEncoder(&buff)
-	err := enc.Encode(c)
-
-	return buff.Bytes(), err
-}
-
-func (c *InfoCard) DecodeBinary(bin []byte) error {
-	var buff bytes.Buffer
-	_, err := buff.Write(bin)
-	if err != nil {
-		return fmt.Errorf("error while writing binary into buffer: %w", err)
-	}
-	dec := gob.NewDecoder(&buff)
-	err = dec.Decode(c)
-	if err != nil {
-		return fmt.Errorf("error while decoding binary: %w", err)
-	}
-	return err
-}
-
 type InfoText struct {
+	CustomFields
+	Rotation
 	Text string `json:"text"`
 }
 
-func (t *InfoText) MakeBinary() ([]byte, error) {
-	var buff bytes.Buffer
-
-	enc := gob.NewEncoder(&buff)
-	err := enc.Encode(t)
-
-	return buff.Bytes(), err
-}
-
-func (t *InfoText) DecodeBinary(bin []byte) error {
-	var buff bytes.Buffer
-	_, err := buff.Write(bin)
-	if err != nil {
-		return fmt.Errorf("error while writing binary into buffer: %w", err)
-	}
-	dec := gob.NewDecoder(&buff)
-	err = dec.Decode(t)
-	if err != nil {
-		return fmt.Errorf("error while decoding binary: %w", err)
+func validateDate(value string) error {
+	if _, err := time.Parse(DateLayout, value); err != nil {
+		return errors.New("is not a date in YYYY-MM-DD format")
 	}
-	return err
+	return nil
 }
 
-func NewInfo(infoType InfoType) Info {
-	switch infoType {
-	case LoginPassword:
-		return &InfoLoginPass{}
-	case Card:
-		return &InfoCard{}
-	case Text:
-		return &InfoText{}
-	}
-
-	return nil
+func parseDate(value string) (time.Time, bool) {
+	date, err := time.Parse(DateLayout, value)
+	return date, err == nil
 }
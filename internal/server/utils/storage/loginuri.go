@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// MatchBaseDomain matches URLs of the same registrable domain, e.g. login.example.co.uk and example.co.uk
+	MatchBaseDomain MatchRule = "base-domain"
+	// MatchHost matches URLs with the same host and port
+	MatchHost MatchRule = "host"
+	// MatchExact matches the URL only
+	MatchExact MatchRule = "exact"
+	// MatchRegex matches URLs by regular expression, it must match the whole URL
+	MatchRegex MatchRule = "regex"
+)
+
+// MatchRules are the rules in order they are offered to the user
+var MatchRules = []MatchRule{MatchBaseDomain, MatchHost, MatchExact, MatchRegex}
+
+// MatchRule tells which URLs a login is used on, empty rule is MatchBaseDomain
+type MatchRule string
+
+// LoginURI is a site or an app the login is used on
+type LoginURI struct {
+	URI   string    `json:"uri"`
+	Match MatchRule `json:"match,omitempty"`
+}
+
+// ParseLoginURL parses URL typed by the user, https is assumed when there is no scheme
+func ParseLoginURL(value string) (*url.URL, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Hostname() == "" {
+		return nil, errors.New("is not a URL")
+	}
+	return u, nil
+}
+
+// Validate checks the URI can be matched with its rule
+func (u LoginURI) Validate() error {
+	switch u.Match {
+	case "", MatchBaseDomain, MatchHost, MatchExact:
+		_, err := ParseLoginURL(u.URI)
+		return err
+	case MatchRegex:
+		if _, err := regexp.Compile(u.URI); err != nil {
+			return errors.New("is not a regular expression")
+		}
+		return nil
+	}
+	return errors.New("has unknown match rule")
+}
+
+// Matches reports whether the login is used on the URL, invalid URIs match nothing
+func (u LoginURI) Matches(target *url.URL) bool {
+	if u.Match == MatchRegex {
+		// unanchored expression would match the host put into the path or the query of another site
+		re, err := regexp.Compile(`^(?:` + u.URI + `)$`)
+		return err == nil && re.MatchString(target.String())
+	}
+	uri, err := ParseLoginURL(u.URI)
+	if err != nil {
+		return false
+	}
+	switch u.Match {
+	case MatchExact:
+		return uri.String() == target.String()
+	case MatchHost:
+		return strings.EqualFold(uri.Host, target.Host)
+	}
+	return baseDomain(uri.Hostname()) == baseDomain(target.Hostname())
+}
+
+// baseDomain returns registrable domain of the host, IP addresses and hosts like localhost are returned as is
+func baseDomain(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// MatchesURL reports whether any of the login's URIs matches the URL
+func (l *InfoLoginPass) MatchesURL(target *url.URL) bool {
+	for _, uri := range l.URIs {
+		if uri.Matches(target) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkLoginPass(info Info) FieldErrors {
+	login, ok := info.(*InfoLoginPass)
+	if !ok {
+		return nil
+	}
+	var fieldErrs FieldErrors
+	for i, uri := range login.URIs {
+		if err := uri.Validate(); err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: fmt.Sprintf("uris.%d", i), Message: err.Error()})
+		}
+	}
+	return fieldErrs
+}
+
+func renderLoginPass(info Info) string {
+	login, ok := info.(*InfoLoginPass)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Login: %s\nPassword: %s\n", login.Login, login.Password)
+	for _, uri := range login.URIs {
+		match := uri.Match
+		if match == "" {
+			match = MatchBaseDomain
+		}
+		fmt.Fprintf(&b, "URI: %s (%s)\n", uri.URI, match)
+	}
+	return b.String()
+}
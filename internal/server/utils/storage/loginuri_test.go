@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginURIMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		uri   LoginURI
+		url   string
+		match bool
+	}{
+		{name: "base domain by default", uri: LoginURI{URI: "example.co.uk"}, url: "https://login.example.co.uk/auth", match: true},
+		{name: "other base domain", uri: LoginURI{URI: "example.co.uk"}, url: "https://other.co.uk", match: false},
+		{name: "ip address", uri: LoginURI{URI: "http://192.168.0.1"}, url: "http://192.168.0.1:8080/admin", match: true},
+		{name: "host", uri: LoginURI{URI: "https://mail.example.com", Match: MatchHost}, url: "https://MAIL.example.com/inbox", match: true},
+		{name: "other host", uri: LoginURI{URI: "https://mail.example.com", Match: MatchHost}, url: "https://www.example.com", match: false},
+		{name: "host with port", uri: LoginURI{URI: "localhost:8080", Match: MatchHost}, url: "http://localhost:9090", match: false},
+		{name: "exact", uri: LoginURI{URI: "https://example.com/login", Match: MatchExact}, url: "https://example.com/login", match: true},
+		{name: "not exact", uri: LoginURI{URI: "https://example.com/login", Match: MatchExact}, url: "https://example.com/login?next=/", match: false},
+		{name: "regex", uri: LoginURI{URI: `^https://[a-z]+\.example\.com/`, Match: MatchRegex}, url: "https://eu.example.com/", match: true},
+		{name: "not regex", uri: LoginURI{URI: `^https://[a-z]+\.example\.com/`, Match: MatchRegex}, url: "http://eu.example.com/", match: false},
+		{name: "regex in query", uri: LoginURI{URI: `example\.com`, Match: MatchRegex}, url: "https://evil.test/?example.com", match: false},
+		{name: "regex in path", uri: LoginURI{URI: `https://example\.com/.*`, Match: MatchRegex}, url: "https://evil.test/https://example.com/", match: false},
+		{name: "regex with path", uri: LoginURI{URI: `https://example\.com/.*`, Match: MatchRegex}, url: "https://example.com/login?next=home", match: true},
+		{name: "regex alternation", uri: LoginURI{URI: `https://a\.test/|https://b\.test/`, Match: MatchRegex}, url: "https://b.test/?https://a.test/", match: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.uri.Validate())
+			target, err := ParseLoginURL(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, tt.uri.Matches(target))
+		})
+	}
+}
+
+func TestLoginPassSpec(t *testing.T) {
+	spec, ok := Lookup(LoginPassword)
+	require.True(t, ok)
+
+	info := &InfoLoginPass{Login: "user", Password: "secret", URIs: []LoginURI{{URI: "github.com"}, {URI: "(", Match: MatchRegex}, {URI: "example.com", Match: "prefix"}}}
+	err := spec.Validate(info)
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, FieldErrors{
+		{Field: "uris.1", Message: "is not a regular expression"},
+		{Field: "uris.2", Message: "has unknown match rule"},
+	}, fieldErrs)
+
+	info.URIs = info.URIs[:1]
+	text, err := spec.Format(info)
+	require.NoError(t, err)
+	assert.Equal(t, "Login: user\nPassword: secret\nURI: github.com (base-domain)\n", text)
+}
@@ -0,0 +1,81 @@
+package mockstorage
+
+import (
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+func (ms *MockStorage) findEmergencyContact(owner string, contact string) int {
+	for i, c := range ms.Emergency {
+		if c.Owner == owner && c.Contact == contact {
+			return i
+		}
+	}
+	return -1
+}
+
+func (ms *MockStorage) AddEmergencyContact(owner string, contact string, waitHours int) error {
+	for _, login := range []string{owner, contact} {
+		if user, _ := ms.GetUserData(login); user.ID == 0 {
+			return storage.ErrUserNotFound
+		}
+	}
+	if i := ms.findEmergencyContact(owner, contact); i >= 0 {
+		ms.Emergency[i].WaitHours = waitHours
+		return nil
+	}
+	ms.Emergency = append(ms.Emergency, emergency.Contact{
+		Owner:     owner,
+		Contact:   contact,
+		WaitHours: waitHours,
+		Status:    emergency.StatusIdle,
+	})
+	return nil
+}
+
+func (ms *MockStorage) RemoveEmergencyContact(owner string, contact string) error {
+	i := ms.findEmergencyContact(owner, contact)
+	if i < 0 {
+		return storage.ErrDataNotFound
+	}
+	ms.Emergency = append(ms.Emergency[:i], ms.Emergency[i+1:]...)
+	return nil
+}
+
+func (ms *MockStorage) GetEmergencyContact(owner string, contact string) (emergency.Contact, error) {
+	i := ms.findEmergencyContact(owner, contact)
+	if i < 0 {
+		return emergency.Contact{}, storage.ErrDataNotFound
+	}
+	return ms.Emergency[i], nil
+}
+
+func (ms *MockStorage) GetEmergencyContacts(owner string) ([]emergency.Contact, error) {
+	var contacts []emergency.Contact
+	for _, c := range ms.Emergency {
+		if c.Owner == owner {
+			contacts = append(contacts, c)
+		}
+	}
+	return contacts, nil
+}
+
+func (ms *MockStorage) GetEmergencyGrantors(contact string) ([]emergency.Contact, error) {
+	var contacts []emergency.Contact
+	for _, c := range ms.Emergency {
+		if c.Contact == contact {
+			contacts = append(contacts, c)
+		}
+	}
+	return contacts, nil
+}
+
+func (ms *MockStorage) SetEmergencyStatus(contact emergency.Contact) error {
+	i := ms.findEmergencyContact(contact.Owner, contact.Contact)
+	if i < 0 {
+		return storage.ErrDataNotFound
+	}
+	ms.Emergency[i].Status = contact.Status
+	ms.Emergency[i].RequestedAt = contact.RequestedAt
+	return nil
+}
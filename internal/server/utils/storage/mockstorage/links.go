@@ -0,0 +1,34 @@
+package mockstorage
+
+import (
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+func (ms *MockStorage) CreateLink(tokenHash []byte, link storage.Link) error {
+	user, _ := ms.GetUserData(link.Owner)
+	if user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	ms.Links[string(tokenHash)] = link
+	return nil
+}
+
+func (ms *MockStorage) ConsumeLink(tokenHash []byte) (storage.Link, error) {
+	link, ok := ms.Links[string(tokenHash)]
+	if !ok {
+		return storage.Link{}, storage.ErrDataNotFound
+	}
+	if !link.ExpiresAt.After(time.Now()) {
+		delete(ms.Links, string(tokenHash))
+		return storage.Link{}, storage.ErrDataNotFound
+	}
+	link.ViewsLeft--
+	if link.ViewsLeft <= 0 {
+		delete(ms.Links, string(tokenHash))
+	} else {
+		ms.Links[string(tokenHash)] = link
+	}
+	return link, nil
+}
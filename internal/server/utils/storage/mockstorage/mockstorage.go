@@ -1,50 +1,292 @@
 package mockstorage
 
 import (
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/audit"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/types"
 )
 
 type MockData struct {
-	Data  []byte
-	Type  storage.InfoType
-	Name  string
-	Login string
+	Data       []byte
+	Type       storage.InfoType
+	Name       string
+	Login      string
+	Org        string
+	Collection string
 }
 
 type MockStorage struct {
-	Storage []MockData
-	Users   []types.User
+	Storage     []MockData
+	Users       []types.User
+	BackupCodes map[int][]string
+	Sessions    map[string]MockSession
+	Challenges  map[string]MockChallenge
+	Attempts    map[string]MockAttempt
+	Audit       []audit.Entry
+	Shares      []MockShare
+	Sealed      []storage.SealedShare
+	Orgs        map[string]*MockOrg
+	// Links are kept by hashes of their tokens
+	Links     map[string]storage.Link
+	Emergency []emergency.Contact
+	// Templates are kept by logins of their owners
+	Templates map[string][]storage.Template
+}
+
+type MockShare struct {
+	Owner     string
+	Type      storage.InfoType
+	Name      string
+	Recipient string
+	Access    storage.Access
+}
+
+type MockAttempt struct {
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil time.Time
+}
+
+type MockChallenge struct {
+	UserID    int
+	Attempts  int
+	ExpiresAt time.Time
+}
+
+type MockSession struct {
+	UserID    int
+	ExpiresAt time.Time
+	Revoked   bool
 }
 
 func NewMockStorage() *MockStorage {
 	return &MockStorage{
-		Storage: make([]MockData, 100),
+		Storage:     make([]MockData, 100),
+		BackupCodes: make(map[int][]string),
+		Sessions:    make(map[string]MockSession),
+		Challenges:  make(map[string]MockChallenge),
+		Attempts:    make(map[string]MockAttempt),
+		Orgs:        make(map[string]*MockOrg),
+		Links:       make(map[string]storage.Link),
+		Templates:   make(map[string][]storage.Template),
 	}
 }
 
 func (ms *MockStorage) SaveData(encryptedData []byte, metadata storage.InfoMeta) error {
-	ms.Storage = append(ms.Storage, MockData{
-		Data: encryptedData,
-		Type: metadata.Type,
-		Name: metadata.Name,
-	})
+	data := MockData{
+		Data:  encryptedData,
+		Type:  metadata.Type,
+		Name:  metadata.Name,
+		Login: metadata.Login,
+	}
+	if metadata.InCollection() {
+		if !ms.collectionExists(metadata.Org, metadata.Collection) {
+			return storage.ErrDataNotFound
+		}
+		data.Login = ""
+		data.Org, data.Collection = metadata.Org, metadata.Collection
+	}
+	ms.Storage = append(ms.Storage, data)
 	return nil
 }
 
-func (ms *MockStorage) GetData(metadata storage.InfoMeta) ([]byte, error) {
-	var data []byte
-	exists := false
-	for _, md := range ms.Storage {
-		if md.Type == metadata.Type && md.Name == metadata.Name {
-			data = md.Data
-			exists = true
+// find returns index of the secret, collection's secrets are found by organization and collection instead of owner
+func (ms *MockStorage) find(metadata storage.InfoMeta) int {
+	for i, md := range ms.Storage {
+		if md.Type != metadata.Type || md.Name != metadata.Name {
+			continue
+		}
+		if metadata.InCollection() && md.Org == metadata.Org && md.Collection == metadata.Collection {
+			return i
+		}
+		if !metadata.InCollection() && md.Collection == "" && md.Login == metadata.OwnerLogin() {
+			return i
 		}
 	}
-	if !exists {
+	return -1
+}
+
+func (ms *MockStorage) GetData(metadata storage.InfoMeta) ([]byte, error) {
+	if _, ok := ms.access(metadata); !ok {
 		return nil, storage.ErrDataNotFound
 	}
-	return data, nil
+	if i := ms.find(metadata); i >= 0 {
+		return ms.Storage[i].Data, nil
+	}
+	return nil, storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) UpdateData(encryptedData []byte, metadata storage.InfoMeta) error {
+	access, ok := ms.access(metadata)
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	if access != storage.AccessReadWrite {
+		return storage.ErrAccessDenied
+	}
+	if i := ms.find(metadata); i >= 0 {
+		ms.Storage[i].Data = encryptedData
+		return nil
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) DeleteData(metadata storage.InfoMeta) error {
+	if i := ms.find(metadata); i >= 0 {
+		md := ms.Storage[i]
+		ms.Storage = append(ms.Storage[:i], ms.Storage[i+1:]...)
+		if md.Collection == "" {
+			ms.removeShares(func(share MockShare) bool {
+				return share.Owner == md.Login && share.Type == md.Type && share.Name == md.Name
+			})
+			ms.removeSealed(func(share storage.SealedShare) bool {
+				return share.Owner == md.Login && share.Type == md.Type && share.Name == md.Name
+			})
+		}
+		return nil
+	}
+	return storage.ErrDataNotFound
+}
+
+// access returns level of the user's access to the secret, owners have read-write access
+func (ms *MockStorage) access(metadata storage.InfoMeta) (storage.Access, bool) {
+	if !metadata.Shared() || metadata.InCollection() {
+		return storage.AccessReadWrite, true
+	}
+	for _, share := range ms.Shares {
+		if share.Owner == metadata.Owner && share.Type == metadata.Type && share.Name == metadata.Name && share.Recipient == metadata.Login {
+			return share.Access, true
+		}
+	}
+	return "", false
+}
+
+func (ms *MockStorage) removeShares(match func(share MockShare) bool) {
+	kept := ms.Shares[:0]
+	for _, share := range ms.Shares {
+		if !match(share) {
+			kept = append(kept, share)
+		}
+	}
+	ms.Shares = kept
+}
+
+func (ms *MockStorage) removeSealed(match func(share storage.SealedShare) bool) int {
+	kept := ms.Sealed[:0]
+	for _, share := range ms.Sealed {
+		if !match(share) {
+			kept = append(kept, share)
+		}
+	}
+	removed := len(ms.Sealed) - len(kept)
+	ms.Sealed = kept
+	return removed
+}
+
+func (ms *MockStorage) ShareData(metadata storage.InfoMeta, recipient string, access storage.Access) error {
+	if user, _ := ms.GetUserData(recipient); user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	if _, err := ms.GetData(storage.InfoMeta{Login: metadata.Login, Type: metadata.Type, Name: metadata.Name}); err != nil {
+		return err
+	}
+	for i, share := range ms.Shares {
+		if share.Owner == metadata.Login && share.Type == metadata.Type && share.Name == metadata.Name && share.Recipient == recipient {
+			ms.Shares[i].Access = access
+			return nil
+		}
+	}
+	ms.Shares = append(ms.Shares, MockShare{
+		Owner:     metadata.Login,
+		Type:      metadata.Type,
+		Name:      metadata.Name,
+		Recipient: recipient,
+		Access:    access,
+	})
+	return nil
+}
+
+func (ms *MockStorage) RevokeShare(metadata storage.InfoMeta, recipient string) error {
+	removed := ms.removeSealed(func(share storage.SealedShare) bool {
+		return share.Owner == metadata.Login && share.Type == metadata.Type && share.Name == metadata.Name && share.Recipient == recipient
+	})
+	for i, share := range ms.Shares {
+		if share.Owner == metadata.Login && share.Type == metadata.Type && share.Name == metadata.Name && share.Recipient == recipient {
+			ms.Shares = append(ms.Shares[:i], ms.Shares[i+1:]...)
+			return nil
+		}
+	}
+	if removed > 0 {
+		return nil
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) GetShares(metadata storage.InfoMeta) ([]storage.Share, error) {
+	var shares []storage.Share
+	for _, share := range ms.Shares {
+		if share.Owner == metadata.Login && share.Type == metadata.Type && share.Name == metadata.Name {
+			shares = append(shares, storage.Share{Login: share.Recipient, Access: share.Access})
+		}
+	}
+	for _, share := range ms.Sealed {
+		if share.Owner == metadata.Login && share.Type == metadata.Type && share.Name == metadata.Name {
+			shares = append(shares, storage.Share{Login: share.Recipient, Access: storage.AccessSealed})
+		}
+	}
+	return shares, nil
+}
+
+func (ms *MockStorage) GetSharedWithMe(login string) ([]storage.SharedInfo, error) {
+	var infos []storage.SharedInfo
+	for _, share := range ms.Shares {
+		if share.Recipient == login {
+			infos = append(infos, storage.SharedInfo{
+				Name:   share.Name,
+				Type:   share.Type,
+				Owner:  share.Owner,
+				Access: share.Access,
+			})
+		}
+	}
+	for _, share := range ms.Sealed {
+		if share.Recipient == login {
+			infos = append(infos, storage.SharedInfo{
+				Name:   share.Name,
+				Type:   share.Type,
+				Owner:  share.Owner,
+				Access: storage.AccessSealed,
+			})
+		}
+	}
+	return infos, nil
+}
+
+func (ms *MockStorage) SaveSealedShare(share storage.SealedShare) error {
+	if user, _ := ms.GetUserData(share.Recipient); user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	if _, err := ms.GetData(storage.InfoMeta{Login: share.Owner, Type: share.Type, Name: share.Name}); err != nil {
+		return err
+	}
+	ms.removeSealed(func(s storage.SealedShare) bool {
+		return s.Owner == share.Owner && s.Type == share.Type && s.Name == share.Name && s.Recipient == share.Recipient
+	})
+	ms.Sealed = append(ms.Sealed, share)
+	return nil
+}
+
+func (ms *MockStorage) GetSealedShare(metadata storage.InfoMeta) (storage.SealedShare, error) {
+	for _, share := range ms.Sealed {
+		if share.Owner == metadata.OwnerLogin() && share.Recipient == metadata.Login && share.Type == metadata.Type && share.Name == metadata.Name {
+			return share, nil
+		}
+	}
+	return storage.SealedShare{}, storage.ErrDataNotFound
 }
 
 func (ms *MockStorage) Close() {}
@@ -58,26 +300,308 @@ func (ms *MockStorage) FindUser(login string) (*types.User, error) {
 	return nil, storage.ErrDataNotFound
 }
 
-func (ms *MockStorage) RegisterNewUser(login string, password string) (types.User, error) {
-	user := types.User{
-		ID:           len(ms.Users) + 1,
-		Login:        login,
-		HashPassword: password,
-	}
-	for _, user := range ms.Users {
-		if user.Login == login {
+func (ms *MockStorage) RegisterNewUser(user types.User) (types.User, error) {
+	user.ID = 1
+	for _, u := range ms.Users {
+		if u.Login == user.Login {
 			return types.User{}, storage.ErrUserExists
 		}
+		if u.ID >= user.ID {
+			user.ID = u.ID + 1
+		}
 	}
 	ms.Users = append(ms.Users, user)
 	return user, nil
 }
 
+func (ms *MockStorage) ListData(login string) ([]storage.InfoMeta, error) {
+	var metas []storage.InfoMeta
+	for _, md := range ms.Storage {
+		if md.Login == login && md.Name != "" {
+			metas = append(metas, storage.InfoMeta{Name: md.Name, Type: md.Type, Login: login})
+		}
+	}
+	return metas, nil
+}
+
+func (ms *MockStorage) GetAllData(login string, infoType storage.InfoType) ([]storage.OwnData, error) {
+	var all []storage.OwnData
+	for _, md := range ms.Storage {
+		if md.Login == login && md.Name != "" && (infoType == "" || md.Type == infoType) {
+			all = append(all, storage.OwnData{Meta: storage.InfoMeta{Name: md.Name, Type: md.Type, Login: login}, Data: md.Data})
+		}
+	}
+	return all, nil
+}
+
 func (ms *MockStorage) GetUserData(login string) (types.User, error) {
 	for _, user := range ms.Users {
 		if user.Login == login {
 			return user, nil
 		}
 	}
-	return types.User{}, storage.ErrDataNotFound
+	// same as database: unknown login is not an error, user has zero ID
+	return types.User{}, nil
+}
+
+func (ms *MockStorage) SetTOTPSecret(userID int, secret string, backupCodeHashes []string) error {
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			if user.TOTPEnabled {
+				return types.ErrTOTPEnabled
+			}
+			ms.Users[i].TOTPSecret = secret
+			ms.BackupCodes[userID] = backupCodeHashes
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) EnableTOTP(userID int) error {
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			ms.Users[i].TOTPEnabled = true
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) UseBackupCode(userID int, codeHash string) (bool, error) {
+	codes := ms.BackupCodes[userID]
+	for i, code := range codes {
+		if code == codeHash {
+			ms.BackupCodes[userID] = append(codes[:i], codes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ms *MockStorage) SetTOTPCounter(userID int, counter int64) (bool, error) {
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			if counter <= user.TOTPCounter {
+				return false, nil
+			}
+			ms.Users[i].TOTPCounter = counter
+			return true, nil
+		}
+	}
+	return false, storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) CreateChallenge(userID int, challengeID string, expiresAt time.Time) error {
+	ms.Challenges[challengeID] = MockChallenge{UserID: userID, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (ms *MockStorage) UseChallenge(challengeID string, maxAttempts int) (bool, error) {
+	challenge, ok := ms.Challenges[challengeID]
+	if !ok || challenge.Attempts >= maxAttempts || !challenge.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+	challenge.Attempts++
+	ms.Challenges[challengeID] = challenge
+	return true, nil
+}
+
+func (ms *MockStorage) DeleteChallenge(challengeID string) error {
+	delete(ms.Challenges, challengeID)
+	return nil
+}
+
+func (ms *MockStorage) CreateSession(userID int, sessionID string, expiresAt time.Time) error {
+	ms.Sessions[sessionID] = MockSession{UserID: userID, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (ms *MockStorage) SessionActive(sessionID string) (bool, error) {
+	session, ok := ms.Sessions[sessionID]
+	return ok && !session.Revoked && session.ExpiresAt.After(time.Now()), nil
+}
+
+func (ms *MockStorage) ChangePassword(userID int, hash string, vaultKey []byte, keepSessionID string) error {
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			ms.Users[i].HashPassword = hash
+			if vaultKey != nil {
+				ms.Users[i].VaultKey = vaultKey
+			}
+			for id, session := range ms.Sessions {
+				if session.UserID == userID && id != keepSessionID {
+					session.Revoked = true
+					ms.Sessions[id] = session
+				}
+			}
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) SetVaultKey(userID int, vaultKey []byte) error {
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			if user.VaultKey != nil {
+				return types.ErrVaultKeySet
+			}
+			ms.Users[i].VaultKey = vaultKey
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) SetRecoveryKey(userID int, vaultKey []byte, verifierHash string) error {
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			ms.Users[i].RecoveryVaultKey = vaultKey
+			ms.Users[i].RecoveryHash = verifierHash
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) SetKeyPair(userID int, publicKey []byte, privateKey []byte) error {
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			if user.PublicKey != nil {
+				return types.ErrKeyPairSet
+			}
+			ms.Users[i].PublicKey = publicKey
+			ms.Users[i].PrivateKey = privateKey
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) DeleteUser(userID int, login string) error {
+	var sole []string
+	for name, o := range ms.Orgs {
+		if _, ok := o.Members[login]; ok && len(o.Members) == 1 {
+			sole = append(sole, name)
+			continue
+		}
+		if o.Members[login] == orgs.RoleOwner && !o.hasOtherOwner(login) {
+			return orgs.ErrLastOwner
+		}
+	}
+	for _, name := range sole {
+		ms.DeleteOrg(name)
+	}
+	kept := ms.Storage[:0]
+	for _, md := range ms.Storage {
+		if md.Login != login {
+			kept = append(kept, md)
+		}
+	}
+	ms.Storage = kept
+	ms.removeShares(func(share MockShare) bool {
+		return share.Owner == login || share.Recipient == login
+	})
+	ms.removeSealed(func(share storage.SealedShare) bool {
+		return share.Owner == login || share.Recipient == login
+	})
+	for _, o := range ms.Orgs {
+		delete(o.Members, login)
+		delete(o.Invitations, login)
+	}
+	contacts := ms.Emergency[:0]
+	for _, c := range ms.Emergency {
+		if c.Owner != login && c.Contact != login {
+			contacts = append(contacts, c)
+		}
+	}
+	ms.Emergency = contacts
+	for hash, link := range ms.Links {
+		if link.Owner == login {
+			delete(ms.Links, hash)
+		}
+	}
+	delete(ms.Templates, login)
+	for id, session := range ms.Sessions {
+		if session.UserID == userID {
+			delete(ms.Sessions, id)
+		}
+	}
+	delete(ms.BackupCodes, userID)
+	for id, challenge := range ms.Challenges {
+		if challenge.UserID == userID {
+			delete(ms.Challenges, id)
+		}
+	}
+	entries := ms.Audit[:0]
+	for _, e := range ms.Audit {
+		if e.Login != login {
+			entries = append(entries, e)
+		}
+	}
+	ms.Audit = entries
+	// bruteforce tests use this package, so the key is built here instead of bruteforce.LoginKey
+	delete(ms.Attempts, "login:"+login)
+	for i, user := range ms.Users {
+		if user.ID == userID {
+			ms.Users = append(ms.Users[:i], ms.Users[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (ms *MockStorage) IncrementFailures(key string, now time.Time, since time.Time) (int, error) {
+	attempt := ms.Attempts[key]
+	if attempt.LastFailure.Before(since) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailure = now
+	ms.Attempts[key] = attempt
+	return attempt.Failures, nil
+}
+
+func (ms *MockStorage) SetBlockedUntil(key string, until time.Time) error {
+	attempt := ms.Attempts[key]
+	attempt.BlockedUntil = until
+	ms.Attempts[key] = attempt
+	return nil
+}
+
+func (ms *MockStorage) GetBlockedUntil(key string) (time.Time, error) {
+	return ms.Attempts[key].BlockedUntil, nil
+}
+
+func (ms *MockStorage) ResetFailures(key string) error {
+	delete(ms.Attempts, key)
+	return nil
+}
+
+func (ms *MockStorage) AppendAudit(e audit.Entry) (audit.Entry, error) {
+	for i := len(ms.Audit) - 1; i >= 0; i-- {
+		if ms.Audit[i].Login == e.Login {
+			e.PrevHash = ms.Audit[i].Hash
+			break
+		}
+	}
+	e.ID = int64(len(ms.Audit) + 1)
+	if len(ms.Audit) > 0 {
+		e.ID = ms.Audit[len(ms.Audit)-1].ID + 1
+	}
+	e.Hash = e.ComputeHash()
+	ms.Audit = append(ms.Audit, e)
+	return e, nil
+}
+
+func (ms *MockStorage) GetAudit(login string, beforeID int64, limit int) ([]audit.Entry, error) {
+	var entries []audit.Entry
+	for i := len(ms.Audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		e := ms.Audit[i]
+		if e.Login == login && (beforeID == 0 || e.ID < beforeID) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
 }
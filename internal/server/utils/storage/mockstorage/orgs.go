@@ -0,0 +1,222 @@
+package mockstorage
+
+import (
+	"sort"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+type MockOrg struct {
+	Members     map[string]orgs.Role
+	Invitations map[string]orgs.Invitation
+	Collections []string
+}
+
+func (o *MockOrg) hasOtherOwner(login string) bool {
+	for member, role := range o.Members {
+		if member != login && role == orgs.RoleOwner {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms *MockStorage) collectionExists(org string, collection string) bool {
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return false
+	}
+	for _, name := range o.Collections {
+		if name == collection {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms *MockStorage) CreateOrg(name string, ownerLogin string) error {
+	if _, ok := ms.Orgs[name]; ok {
+		return orgs.ErrOrgExists
+	}
+	if user, _ := ms.GetUserData(ownerLogin); user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	ms.Orgs[name] = &MockOrg{
+		Members:     map[string]orgs.Role{ownerLogin: orgs.RoleOwner},
+		Invitations: make(map[string]orgs.Invitation),
+	}
+	return nil
+}
+
+func (ms *MockStorage) DeleteOrg(name string) error {
+	if _, ok := ms.Orgs[name]; !ok {
+		return storage.ErrDataNotFound
+	}
+	delete(ms.Orgs, name)
+	kept := ms.Storage[:0]
+	for _, md := range ms.Storage {
+		if md.Org != name {
+			kept = append(kept, md)
+		}
+	}
+	ms.Storage = kept
+	return nil
+}
+
+func (ms *MockStorage) GetUserOrgs(login string) ([]orgs.Membership, error) {
+	var memberships []orgs.Membership
+	for name, o := range ms.Orgs {
+		if role, ok := o.Members[login]; ok {
+			memberships = append(memberships, orgs.Membership{Org: name, Role: role})
+		}
+	}
+	sort.Slice(memberships, func(i, j int) bool { return memberships[i].Org < memberships[j].Org })
+	return memberships, nil
+}
+
+func (ms *MockStorage) GetMemberRole(org string, login string) (orgs.Role, error) {
+	if o, ok := ms.Orgs[org]; ok {
+		return o.Members[login], nil
+	}
+	return "", nil
+}
+
+func (ms *MockStorage) GetMembers(org string) ([]orgs.Member, error) {
+	var members []orgs.Member
+	if o, ok := ms.Orgs[org]; ok {
+		for login, role := range o.Members {
+			members = append(members, orgs.Member{Login: login, Role: role})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Login < members[j].Login })
+	return members, nil
+}
+
+func (ms *MockStorage) InviteMember(org string, login string, role orgs.Role, invitedBy string) error {
+	if user, _ := ms.GetUserData(login); user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	if _, ok = o.Members[login]; ok {
+		return orgs.ErrAlreadyMember
+	}
+	o.Invitations[login] = orgs.Invitation{Org: org, Role: role, InvitedBy: invitedBy}
+	return nil
+}
+
+func (ms *MockStorage) GetInvitations(login string) ([]orgs.Invitation, error) {
+	var invitations []orgs.Invitation
+	for _, o := range ms.Orgs {
+		if invitation, ok := o.Invitations[login]; ok {
+			invitations = append(invitations, invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].Org < invitations[j].Org })
+	return invitations, nil
+}
+
+func (ms *MockStorage) AcceptInvitation(org string, login string) error {
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	invitation, ok := o.Invitations[login]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	delete(o.Invitations, login)
+	o.Members[login] = invitation.Role
+	return nil
+}
+
+func (ms *MockStorage) DeclineInvitation(org string, login string) error {
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	if _, ok = o.Invitations[login]; !ok {
+		return storage.ErrDataNotFound
+	}
+	delete(o.Invitations, login)
+	return nil
+}
+
+func (ms *MockStorage) SetMemberRole(org string, login string, role orgs.Role) error {
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	if _, ok = o.Members[login]; !ok {
+		return storage.ErrDataNotFound
+	}
+	o.Members[login] = role
+	return nil
+}
+
+func (ms *MockStorage) RemoveMember(org string, login string) error {
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	if _, ok = o.Members[login]; !ok {
+		return storage.ErrDataNotFound
+	}
+	delete(o.Members, login)
+	return nil
+}
+
+func (ms *MockStorage) CreateCollection(org string, name string) error {
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	if ms.collectionExists(org, name) {
+		return storage.ErrInvalidData
+	}
+	o.Collections = append(o.Collections, name)
+	return nil
+}
+
+func (ms *MockStorage) DeleteCollection(org string, name string) error {
+	o, ok := ms.Orgs[org]
+	if !ok {
+		return storage.ErrDataNotFound
+	}
+	for i, collection := range o.Collections {
+		if collection == name {
+			o.Collections = append(o.Collections[:i], o.Collections[i+1:]...)
+			kept := ms.Storage[:0]
+			for _, md := range ms.Storage {
+				if md.Org != org || md.Collection != name {
+					kept = append(kept, md)
+				}
+			}
+			ms.Storage = kept
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
+
+func (ms *MockStorage) GetCollections(org string) ([]string, error) {
+	if o, ok := ms.Orgs[org]; ok {
+		collections := append([]string{}, o.Collections...)
+		sort.Strings(collections)
+		return collections, nil
+	}
+	return nil, nil
+}
+
+func (ms *MockStorage) GetCollectionData(org string, collection string) ([]storage.InfoMeta, error) {
+	var metas []storage.InfoMeta
+	for _, md := range ms.Storage {
+		if md.Org == org && md.Collection == collection && md.Collection != "" {
+			metas = append(metas, storage.InfoMeta{Name: md.Name, Type: md.Type, Org: org, Collection: collection})
+		}
+	}
+	return metas, nil
+}
@@ -0,0 +1,39 @@
+package mockstorage
+
+import (
+	"sort"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+func (ms *MockStorage) SaveTemplate(owner string, template storage.Template) error {
+	if user, _ := ms.GetUserData(owner); user.ID == 0 {
+		return storage.ErrUserNotFound
+	}
+	templates := ms.Templates[owner]
+	for i, t := range templates {
+		if t.Name == template.Name {
+			templates[i] = template
+			return nil
+		}
+	}
+	templates = append(templates, template)
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	ms.Templates[owner] = templates
+	return nil
+}
+
+func (ms *MockStorage) GetTemplates(owner string) ([]storage.Template, error) {
+	return ms.Templates[owner], nil
+}
+
+func (ms *MockStorage) DeleteTemplate(owner string, name string) error {
+	templates := ms.Templates[owner]
+	for i, t := range templates {
+		if t.Name == name {
+			ms.Templates[owner] = append(templates[:i], templates[i+1:]...)
+			return nil
+		}
+	}
+	return storage.ErrDataNotFound
+}
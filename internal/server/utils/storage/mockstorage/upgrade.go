@@ -0,0 +1,28 @@
+package mockstorage
+
+import (
+	"bytes"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+// GetDataBatch uses positions in Storage as IDs, empty entries are skipped
+func (ms *MockStorage) GetDataBatch(afterID int64, limit int) ([]storage.StoredData, error) {
+	var batch []storage.StoredData
+	for i := int(afterID); i < len(ms.Storage) && len(batch) < limit; i++ {
+		if ms.Storage[i].Data == nil {
+			continue
+		}
+		batch = append(batch, storage.StoredData{ID: int64(i + 1), Type: ms.Storage[i].Type, Data: ms.Storage[i].Data})
+	}
+	return batch, nil
+}
+
+func (ms *MockStorage) ReplaceData(id int64, oldData []byte, newData []byte) (bool, error) {
+	i := int(id) - 1
+	if i < 0 || i >= len(ms.Storage) || !bytes.Equal(ms.Storage[i].Data, oldData) {
+		return false, nil
+	}
+	ms.Storage[i].Data = newData
+	return true, nil
+}
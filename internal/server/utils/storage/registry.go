@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type typeRegistry struct {
+	sync.RWMutex
+	specs map[InfoType]TypeSpec
+	order []InfoType
+}
+
+// registry is initialized with builtin types before init functions register the others
+var registry = newTypeRegistry(builtinTypes)
+
+func newTypeRegistry(specs []TypeSpec) *typeRegistry {
+	r := &typeRegistry{specs: make(map[InfoType]TypeSpec)}
+	for _, spec := range specs {
+		r.register(spec)
+	}
+	return r
+}
+
+const (
+	KindText FieldKind = ""
+	// KindNumber fields are integers in JSON
+	KindNumber FieldKind = "number"
+	// KindHidden fields are text which is masked when it is typed and shown
+	KindHidden FieldKind = "hidden"
+	// KindBoolean fields are "true" or "false"
+	KindBoolean FieldKind = "boolean"
+	// KindURL fields are absolute URLs
+	KindURL FieldKind = "url"
+	// KindDate fields are dates in DateLayout
+	KindDate FieldKind = "date"
+)
+
+// FieldKind tells how value of the field is kept in JSON of the secret
+type FieldKind string
+
+// Field describes a field of a secret type, Name is its key in JSON
+type Field struct {
+	Name     string
+	Label    string
+	Kind     FieldKind
+	Masked   bool
+	Required bool
+	// Generate fields can be filled by the client's password generator
+	Generate bool
+	// Validate checks non-empty value of the field
+	Validate func(value string) error
+}
+
+// Check reports why the value can't be stored in the field
+func (f Field) Check(value string) error {
+	if strings.TrimSpace(value) == "" {
+		if f.Required {
+			return errors.New("is required")
+		}
+		return nil
+	}
+	switch f.Kind {
+	case KindNumber:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return errors.New("is not a number")
+		}
+	case KindBoolean:
+		if value != "true" && value != "false" {
+			return errors.New("is not true or false")
+		}
+	case KindURL:
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("is not an absolute URL")
+		}
+	case KindDate:
+		if err := validateDate(value); err != nil {
+			return err
+		}
+	}
+	if f.Validate != nil {
+		return f.Validate(value)
+	}
+	return nil
+}
+
+// TypeSpec describes a secret type, the server and the client handle secrets generically by their specs
+type TypeSpec struct {
+	Type InfoType
+	// Name is shown to the user instead of the type
+	Name   string
+	New    func() Info
+	Fields []Field
+	// Render formats the secret for the CLI, the fields are printed with their labels if it is nil
+	Render func(info Info) string
+	// Version is version of the type's schema, it is 1 if not set and is raised when fields change incompatibly
+	Version int
+	// Migrate converts JSON of the secret encoded with older schema version to the current one
+	Migrate func(version int, payload []byte) ([]byte, error)
+	// Check validates the secret as a whole after its fields are checked one by one
+	Check func(info Info) FieldErrors
+}
+
+func (s TypeSpec) version() int {
+	if s.Version == 0 {
+		return 1
+	}
+	return s.Version
+}
+
+// FieldError is a field of the secret with invalid value
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors are returned by Validate when some fields of the secret are invalid
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+" "+fieldErr.Message)
+	}
+	return "error invalid fields: " + strings.Join(messages, ", ")
+}
+
+// Register adds secret type to the registry, it panics if the type is registered twice
+func Register(spec TypeSpec) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.register(spec)
+}
+
+func (r *typeRegistry) register(spec TypeSpec) {
+	if spec.Type == "" || spec.New == nil {
+		panic("storage: Register of incomplete type spec")
+	}
+	if _, ok := r.specs[spec.Type]; ok {
+		panic("storage: Register called twice for type " + string(spec.Type))
+	}
+	r.specs[spec.Type] = spec
+	r.order = append(r.order, spec.Type)
+}
+
+// Lookup returns spec of the registered type
+func Lookup(infoType InfoType) (TypeSpec, bool) {
+	return registry.lookup(infoType)
+}
+
+func (r *typeRegistry) lookup(infoType InfoType) (TypeSpec, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	spec, ok := r.specs[infoType]
+	return spec, ok
+}
+
+// Types returns specs of all registered types in order of registration
+func Types() []TypeSpec {
+	registry.RLock()
+	defer registry.RUnlock()
+	specs := make([]TypeSpec, 0, len(registry.order))
+	for _, infoType := range registry.order {
+		specs = append(specs, registry.specs[infoType])
+	}
+	return specs
+}
+
+// NewInfo returns empty secret of the type or nil if the type is not registered
+func NewInfo(infoType InfoType) Info {
+	spec, ok := Lookup(infoType)
+	if !ok {
+		return nil
+	}
+	return spec.New()
+}
+
+// Values returns the secret's fields as strings by their names
+func (s TypeSpec) Values(info Info) (map[string]string, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling secret: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error while unmarshalling secret: %w", err)
+	}
+	values := make(map[string]string, len(s.Fields))
+	for _, field := range s.Fields {
+		value, ok := raw[field.Name]
+		if !ok {
+			continue
+		}
+		var str string
+		if json.Unmarshal(value, &str) == nil {
+			values[field.Name] = str
+			continue
+		}
+		values[field.Name] = string(value)
+	}
+	return values, nil
+}
+
+// FromValues builds secret of the type from its fields' values
+func (s TypeSpec) FromValues(values map[string]string) (Info, error) {
+	raw := make(map[string]json.RawMessage, len(values))
+	for _, field := range s.Fields {
+		value, ok := values[field.Name]
+		if !ok || (field.Kind == KindNumber && value == "") {
+			continue
+		}
+		if field.Kind == KindNumber {
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, FieldErrors{{Field: field.Name, Message: "is not a number"}}
+			}
+			raw[field.Name] = json.RawMessage(strconv.FormatInt(number, 10))
+			continue
+		}
+		str, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("error while marshalling values: %w", err)
+		}
+		raw[field.Name] = str
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling values: %w", err)
+	}
+	info := s.New()
+	if err = json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("error while unmarshalling secret: %w", err)
+	}
+	return info, nil
+}
+
+// Validate checks all fields of the secret, invalid ones are returned as FieldErrors
+func (s TypeSpec) Validate(info Info) error {
+	values, err := s.Values(info)
+	if err != nil {
+		return err
+	}
+	var fieldErrs FieldErrors
+	for _, field := range s.Fields {
+		if err := field.Check(values[field.Name]); err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: field.Name, Message: err.Error()})
+		}
+	}
+	if s.Check != nil && len(fieldErrs) == 0 {
+		fieldErrs = s.Check(info)
+	}
+	if custom, ok := info.(Customizable); ok {
+		fieldErrs = append(fieldErrs, custom.CustomFieldSet().Check()...)
+	}
+	if rotatable, ok := info.(Rotatable); ok {
+		fieldErrs = append(fieldErrs, rotatable.RotationPolicy().Check()...)
+	}
+	if len(fieldErrs) != 0 {
+		return fieldErrs
+	}
+	return nil
+}
+
+// Format returns the secret as text for the CLI, custom fields and the policy follow the type's fields
+func (s TypeSpec) Format(info Info) (string, error) {
+	var b strings.Builder
+	if s.Render != nil {
+		b.WriteString(s.Render(info))
+	} else {
+		values, err := s.Values(info)
+		if err != nil {
+			return "", err
+		}
+		for _, field := range s.Fields {
+			fmt.Fprintf(&b, "%s: %s\n", field.Label, values[field.Name])
+		}
+	}
+	if custom, ok := info.(Customizable); ok {
+		b.WriteString(custom.CustomFieldSet().Format())
+	}
+	if rotatable, ok := info.(Rotatable); ok {
+		b.WriteString(rotatable.RotationPolicy().Format())
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	spec, ok := Lookup(LoginPassword)
+	require.True(t, ok)
+	assert.Equal(t, "Login/Password", LoginPassword.String())
+	assert.IsType(t, &InfoLoginPass{}, NewInfo(LoginPassword))
+	assert.Nil(t, NewInfo("wrong-type"))
+	assert.Equal(t, "", InfoType("wrong-type").String())
+
+	var types []InfoType
+	for _, spec := range Types() {
+		types = append(types, spec.Type)
+	}
+	assert.Equal(t, []InfoType{LoginPassword, Card, Text}, types[:3])
+
+	assert.Panics(t, func() { Register(spec) })
+	assert.Panics(t, func() { Register(TypeSpec{Type: "incomplete"}) })
+}
+
+func TestTypeSpecValues(t *testing.T) {
+	spec, ok := Lookup(LoginPassword)
+	require.True(t, ok)
+
+	info, err := spec.FromValues(map[string]string{"login": "user", "password": "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, &InfoLoginPass{Login: "user", Password: "secret"}, info)
+
+	values, err := spec.Values(info)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"login": "user", "password": "secret"}, values)
+
+	text, err := spec.Format(info)
+	require.NoError(t, err)
+	assert.Equal(t, "Login: user\nPassword: secret\n", text)
+}
+
+func TestTypeSpecValidate(t *testing.T) {
+	spec := TypeSpec{
+		Type: "test",
+		New:  func() Info { return &InfoLoginPass{} },
+		Fields: []Field{
+			{Name: "login", Label: "Login", Required: true},
+			{Name: "password", Label: "Password", Validate: func(value string) error {
+				if len(value) < 4 {
+					return errors.New("is too short")
+				}
+				return nil
+			}},
+		},
+	}
+	assert.NoError(t, spec.Validate(&InfoLoginPass{Login: "user"}))
+	assert.NoError(t, spec.Validate(&InfoLoginPass{Login: "user", Password: "secret"}))
+
+	err := spec.Validate(&InfoLoginPass{Password: "abc"})
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, FieldErrors{
+		{Field: "login", Message: "is required"},
+		{Field: "password", Message: "is too short"},
+	}, fieldErrs)
+}
+
+func TestTOTPSpec(t *testing.T) {
+	spec, ok := Lookup(TOTP)
+	require.True(t, ok)
+
+	info, err := spec.FromValues(map[string]string{"secret": "JBSWY3DPEHPK3PXP", "digits": "08", "period": ""})
+	require.NoError(t, err)
+	assert.Equal(t, &InfoTOTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 8}, info)
+	assert.NoError(t, spec.Validate(info))
+	assert.Equal(t, 30, info.(*InfoTOTP).Params().Period)
+
+	_, err = spec.FromValues(map[string]string{"secret": "JBSWY3DPEHPK3PXP", "digits": "six"})
+	assert.Error(t, err)
+
+	err = spec.Validate(&InfoTOTP{Secret: "not base32!", Algorithm: "md5", Digits: 4})
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Len(t, fieldErrs, 3)
+}
+
+func TestDatabaseSpec(t *testing.T) {
+	spec, ok := Lookup(Database)
+	require.True(t, ok)
+
+	info, err := spec.FromValues(map[string]string{"driver": "postgres", "host": "localhost", "port": "5432", "database": "keeper"})
+	require.NoError(t, err)
+	assert.Equal(t, &InfoDatabase{Driver: "postgres", Host: "localhost", Port: 5432, Database: "keeper"}, info)
+	assert.NoError(t, spec.Validate(info))
+
+	err = spec.Validate(&InfoDatabase{Driver: "oracle", Host: "localhost", Port: 70000, Params: "%zz"})
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Len(t, fieldErrs, 3)
+}
+
+func TestIdentitySpec(t *testing.T) {
+	spec, ok := Lookup(Identity)
+	require.True(t, ok)
+
+	identity := &InfoIdentity{
+		Document:  "passport",
+		FirstName: "Ivan",
+		LastName:  "Ivanov",
+		Number:    "1234 567890",
+		Country:   "DE",
+		IssuedAt:  "2020-01-02",
+		ExpiresAt: "2030-01-02",
+	}
+	assert.NoError(t, spec.Validate(identity))
+	expires, ok := identity.ExpiresOn()
+	require.True(t, ok)
+	assert.Equal(t, "2030-01-02", expires.Format(DateLayout))
+
+	err := spec.Validate(&InfoIdentity{Document: "visa", FirstName: "Ivan", LastName: "Ivanov", Number: "1", Country: "de", IssuedAt: "02.01.2020"})
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Len(t, fieldErrs, 3)
+
+	identity.ExpiresAt = "2019-01-02"
+	identity.Attachments = make([]Attachment, MaxAttachments+1)
+	err = spec.Validate(identity)
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, FieldErrors{
+		{Field: "expires_at", Message: "is not after issue date"},
+		{Field: "attachments", Message: "are more than 5"},
+		{Field: "attachments", Message: "must have names and be at most 5 MiB"},
+	}, fieldErrs)
+
+	identity.ExpiresAt = ""
+	_, ok = identity.ExpiresOn()
+	assert.False(t, ok)
+}
+
+func TestCustomFields(t *testing.T) {
+	spec, ok := Lookup(LoginPassword)
+	require.True(t, ok)
+
+	info := &InfoLoginPass{Login: "admin", Password: "admin"}
+	info.Custom = []CustomField{
+		{Name: "Address", Kind: KindURL, Value: "http://192.168.0.1"},
+		{Name: "Wi-Fi password", Kind: KindHidden, Value: "secret"},
+		{Name: "Guest network", Kind: KindBoolean, Value: "true"},
+		{Name: "Bought", Kind: KindDate, Value: "2023-05-01"},
+	}
+	assert.NoError(t, spec.Validate(info))
+	text, err := spec.Format(info)
+	require.NoError(t, err)
+	assert.Equal(t, "Login: admin\nPassword: admin\nAddress: http://192.168.0.1\nWi-Fi password: ********\nGuest network: true\nBought: 2023-05-01\n", text)
+
+	info.Custom = []CustomField{
+		{Name: "Address", Kind: KindURL, Value: "192.168.0.1"},
+		{Name: "Address", Value: "again"},
+		{Name: "Guest network", Kind: KindBoolean, Value: "yes"},
+		{Name: "Color", Kind: "color"},
+		{Kind: KindText},
+	}
+	err = spec.Validate(info)
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, FieldErrors{
+		{Field: "custom_fields.Address", Message: "is not an absolute URL"},
+		{Field: "custom_fields.Address", Message: "is duplicated"},
+		{Field: "custom_fields.Guest network", Message: "is not true or false"},
+		{Field: "custom_fields.Color", Message: "has unknown kind"},
+		{Field: "custom_fields", Message: "must have names"},
+	}, fieldErrs)
+
+	spec, ok = Lookup(Custom)
+	require.True(t, ok)
+	assert.Error(t, spec.Validate(&InfoCustom{}))
+}
+
+func TestTemplateValidate(t *testing.T) {
+	template := Template{
+		Name:   "Router admin",
+		Type:   LoginPassword,
+		Fields: []TemplateField{{Name: "Address", Kind: KindURL, Required: true}},
+	}
+	assert.NoError(t, template.Validate())
+	assert.Equal(t, Field{Name: "Address", Label: "Address", Kind: KindURL, Required: true}, template.Fields[0].Field())
+
+	err := Template{Type: "wrong-type"}.Validate()
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, []string{"name", "type", "fields"}, []string{fieldErrs[0].Field, fieldErrs[1].Field, fieldErrs[2].Field})
+}
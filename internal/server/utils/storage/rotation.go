@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DueExpires is reason to renew the secret which expires, e.g. a card or a policy's expiry date
+	DueExpires DueReason = "expires"
+	// DueRotate is reason to change the secret which is not changed for longer than its policy allows
+	DueRotate DueReason = "rotate"
+)
+
+// MaxRotateDays limits rotation period of policies
+const MaxRotateDays = 3650
+
+// DueReason tells why the secret must be renewed
+type DueReason string
+
+// Due is the date the secret must be renewed by
+type Due struct {
+	Date   time.Time
+	Reason DueReason
+}
+
+// DueInfo is the user's secret which must be renewed soon or is already overdue
+type DueInfo struct {
+	Name   string    `json:"name"`
+	Type   InfoType  `json:"type"`
+	Reason DueReason `json:"reason"`
+	// Date is in DateLayout
+	Date    string `json:"date"`
+	Overdue bool   `json:"overdue"`
+}
+
+// Policy tells the user when to renew the secret, dates are in DateLayout
+type Policy struct {
+	ExpiresAt string `json:"expires_at,omitempty"`
+	// RotateDays is how many days the secret may be kept unchanged
+	RotateDays int `json:"rotate_days,omitempty"`
+	// ChangedAt is set by the server every time the secret's value is changed
+	ChangedAt string `json:"changed_at,omitempty"`
+}
+
+// Rotation is embedded into secrets of all types to keep the user's policy of renewing them
+type Rotation struct {
+	Policy *Policy `json:"policy,omitempty"`
+}
+
+// RotationPolicy returns the rotation, it lets generic code read and change the policy
+func (r *Rotation) RotationPolicy() *Rotation {
+	return r
+}
+
+// Rotatable is implemented by secrets which embed Rotation
+type Rotatable interface {
+	RotationPolicy() *Rotation
+}
+
+// Check reports invalid fields of the policy
+func (r *Rotation) Check() FieldErrors {
+	if r.Policy == nil {
+		return nil
+	}
+	var fieldErrs FieldErrors
+	if r.Policy.ExpiresAt != "" {
+		if err := validateDate(r.Policy.ExpiresAt); err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: "policy.expires_at", Message: err.Error()})
+		}
+	}
+	if r.Policy.RotateDays < 0 || r.Policy.RotateDays > MaxRotateDays {
+		fieldErrs = append(fieldErrs, FieldError{Field: "policy.rotate_days", Message: "is out of range"})
+	}
+	if r.Policy.ChangedAt != "" {
+		if err := validateDate(r.Policy.ChangedAt); err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: "policy.changed_at", Message: err.Error()})
+		}
+	}
+	return fieldErrs
+}
+
+// Format returns the policy as text
+func (r *Rotation) Format() string {
+	if r.Policy == nil {
+		return ""
+	}
+	var b strings.Builder
+	if r.Policy.ExpiresAt != "" {
+		fmt.Fprintf(&b, "Expires on: %s\n", r.Policy.ExpiresAt)
+	}
+	if r.Policy.RotateDays != 0 {
+		fmt.Fprintf(&b, "Rotate every: %d days\n", r.Policy.RotateDays)
+	}
+	if r.Policy.ChangedAt != "" {
+		fmt.Fprintf(&b, "Changed on: %s\n", r.Policy.ChangedAt)
+	}
+	return b.String()
+}
+
+// Touch records the secret is changed now, its rotation period and age are counted from that day
+func (r *Rotation) Touch(now time.Time) {
+	if r.Policy == nil {
+		r.Policy = &Policy{}
+	}
+	r.Policy.ChangedAt = now.Format(DateLayout)
+}
+
+// TouchChanged records the secret is changed now if its value differs from the stored secret's,
+// otherwise the stored date of the change is kept. stored is nil for new secrets
+func TouchChanged(spec TypeSpec, info, stored Info, now time.Time) error {
+	rotatable, ok := info.(Rotatable)
+	if !ok {
+		return nil
+	}
+	rotation := rotatable.RotationPolicy()
+	previous, ok := stored.(Rotatable)
+	if !ok {
+		rotation.Touch(now)
+		return nil
+	}
+	value, err := spec.secretValues(info)
+	if err != nil {
+		return err
+	}
+	storedValue, err := spec.secretValues(stored)
+	if err != nil {
+		return err
+	}
+	if !equalValues(value, storedValue) {
+		rotation.Touch(now)
+		return nil
+	}
+	var changedAt string
+	if policy := previous.RotationPolicy().Policy; policy != nil {
+		changedAt = policy.ChangedAt
+	}
+	if rotation.Policy == nil && changedAt == "" {
+		return nil
+	}
+	if rotation.Policy == nil {
+		rotation.Policy = &Policy{}
+	}
+	rotation.Policy.ChangedAt = changedAt
+	return nil
+}
+
+// secretValues returns values which make the secret itself: masked fields of the type, or all of its fields
+// if none is masked, and hidden custom fields, or all custom fields if the type has no fields of its own
+func (s TypeSpec) secretValues(info Info) (map[string]string, error) {
+	values, err := s.Values(info)
+	if err != nil {
+		return nil, err
+	}
+	masked := false
+	for _, field := range s.Fields {
+		masked = masked || field.Masked
+	}
+	secret := make(map[string]string)
+	for _, field := range s.Fields {
+		if field.Masked || !masked {
+			secret[field.Name] = values[field.Name]
+		}
+	}
+	if custom, ok := info.(Customizable); ok {
+		for _, field := range custom.CustomFieldSet().Custom {
+			if field.Kind == KindHidden || len(s.Fields) == 0 {
+				secret["custom_fields."+field.Name] = field.Value
+			}
+		}
+	}
+	return secret, nil
+}
+
+func equalValues(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ChangedOn returns the date the secret was last saved, it is false for secrets saved before dates were recorded
+func (r *Rotation) ChangedOn() (time.Time, bool) {
+	if r.Policy == nil {
+		return time.Time{}, false
+	}
+	return parseDate(r.Policy.ChangedAt)
+}
+
+// NextDue returns the earliest date the secret must be renewed by, it is false if the secret never has to be
+func NextDue(info Info) (Due, bool) {
+	var dues []Due
+	if expiring, ok := info.(Expiring); ok {
+		if date, ok := expiring.ExpiresOn(); ok {
+			dues = append(dues, Due{Date: date, Reason: DueExpires})
+		}
+	}
+	if rotatable, ok := info.(Rotatable); ok {
+		dues = append(dues, rotatable.RotationPolicy().dues()...)
+	}
+	if len(dues) == 0 {
+		return Due{}, false
+	}
+	next := dues[0]
+	for _, due := range dues[1:] {
+		if due.Date.Before(next.Date) {
+			next = due
+		}
+	}
+	return next, true
+}
+
+func (r *Rotation) dues() []Due {
+	if r.Policy == nil {
+		return nil
+	}
+	var dues []Due
+	if date, ok := parseDate(r.Policy.ExpiresAt); ok {
+		dues = append(dues, Due{Date: date, Reason: DueExpires})
+	}
+	if changed, ok := parseDate(r.Policy.ChangedAt); ok && r.Policy.RotateDays != 0 {
+		dues = append(dues, Due{Date: changed.AddDate(0, 0, r.Policy.RotateDays), Reason: DueRotate})
+	}
+	return dues
+}
+
+// ParseRotateDays parses rotation period typed by the user
+func ParseRotateDays(value string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, errors.New("is not a number")
+	}
+	if days <= 0 || days > MaxRotateDays {
+		return 0, errors.New("is out of range")
+	}
+	return days, nil
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDue(t *testing.T) {
+	_, ok := NextDue(&InfoText{Text: "text"})
+	assert.False(t, ok)
+
+	card := &InfoCard{CardNumber: "4242424242424242", Date: "02/28"}
+	due, ok := NextDue(card)
+	require.True(t, ok)
+	assert.Equal(t, Due{Date: time.Date(2028, 3, 1, 0, 0, 0, 0, time.UTC), Reason: DueExpires}, due)
+	// the card is still valid on the last day of the month, so it is not overdue yet
+	assert.True(t, due.Date.After(time.Date(2028, 2, 29, 23, 59, 0, 0, time.UTC)))
+
+	card.Policy = &Policy{RotateDays: 30}
+	card.Touch(time.Date(2027, 12, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2027-12-01", card.Policy.ChangedAt)
+	due, ok = NextDue(card)
+	require.True(t, ok)
+	assert.Equal(t, Due{Date: time.Date(2027, 12, 31, 0, 0, 0, 0, time.UTC), Reason: DueRotate}, due)
+
+	card.Policy.ExpiresAt = "2027-12-15"
+	due, _ = NextDue(card)
+	assert.Equal(t, DueExpires, due.Reason)
+
+	formatted, err := (TypeSpec{New: func() Info { return card }}).Format(card)
+	require.NoError(t, err)
+	assert.Contains(t, formatted, "Expires on: 2027-12-15\nRotate every: 30 days\nChanged on: 2027-12-01\n")
+
+	text := &InfoText{Text: "text"}
+	text.Touch(time.Date(2027, 12, 1, 12, 0, 0, 0, time.UTC))
+	changed, ok := text.ChangedOn()
+	require.True(t, ok)
+	assert.Equal(t, "2027-12-01", changed.Format(DateLayout))
+	_, ok = NextDue(text)
+	assert.False(t, ok)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	spec, ok := Lookup(Text)
+	require.True(t, ok)
+
+	info := &InfoText{Text: "text"}
+	info.Policy = &Policy{ExpiresAt: "tomorrow", RotateDays: MaxRotateDays + 1}
+	err := spec.Validate(info)
+	var fieldErrs FieldErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, FieldErrors{
+		{Field: "policy.expires_at", Message: "is not a date in YYYY-MM-DD format"},
+		{Field: "policy.rotate_days", Message: "is out of range"},
+	}, fieldErrs)
+
+	days, err := ParseRotateDays("90")
+	require.NoError(t, err)
+	assert.Equal(t, 90, days)
+	_, err = ParseRotateDays("0")
+	assert.Error(t, err)
+}
+
+func TestTouchChanged(t *testing.T) {
+	now := time.Date(2027, 12, 1, 12, 0, 0, 0, time.UTC)
+	stored := &InfoLoginPass{Login: "user", Password: "pass"}
+	stored.Policy = &Policy{RotateDays: 30, ChangedAt: "2027-01-01"}
+
+	tests := []struct {
+		name      string
+		infoType  InfoType
+		info      Info
+		stored    Info
+		changedAt string
+	}{
+		{
+			name:      "new secret",
+			infoType:  LoginPassword,
+			info:      &InfoLoginPass{Login: "user", Password: "pass"},
+			changedAt: "2027-12-01",
+		},
+		{
+			name:      "password changed",
+			infoType:  LoginPassword,
+			info:      &InfoLoginPass{Login: "user", Password: "new_pass"},
+			stored:    stored,
+			changedAt: "2027-12-01",
+		},
+		{
+			name:      "only login and URIs changed",
+			infoType:  LoginPassword,
+			info:      &InfoLoginPass{Login: "new_user", Password: "pass", URIs: []LoginURI{{URI: "example.com"}}},
+			stored:    stored,
+			changedAt: "2027-01-01",
+		},
+		{
+			name:     "client's date is ignored",
+			infoType: LoginPassword,
+			info: &InfoLoginPass{Login: "user", Password: "pass", Rotation: Rotation{
+				Policy: &Policy{RotateDays: 30, ChangedAt: "2027-11-30"},
+			}},
+			stored:    stored,
+			changedAt: "2027-01-01",
+		},
+		{
+			name:      "text changed",
+			infoType:  Text,
+			info:      &InfoText{Text: "new text"},
+			stored:    &InfoText{Text: "text", Rotation: Rotation{Policy: &Policy{ChangedAt: "2027-01-01"}}},
+			changedAt: "2027-12-01",
+		},
+		{
+			name:     "hidden custom field changed",
+			infoType: Text,
+			info: &InfoText{Text: "text", CustomFields: CustomFields{
+				Custom: []CustomField{{Name: "pin", Kind: KindHidden, Value: "4321"}},
+			}},
+			stored: &InfoText{Text: "text", Rotation: Rotation{Policy: &Policy{ChangedAt: "2027-01-01"}}, CustomFields: CustomFields{
+				Custom: []CustomField{{Name: "pin", Kind: KindHidden, Value: "1234"}},
+			}},
+			changedAt: "2027-12-01",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, ok := Lookup(tt.infoType)
+			require.True(t, ok)
+			require.NoError(t, TouchChanged(spec, tt.info, tt.stored, now))
+			policy := tt.info.(Rotatable).RotationPolicy().Policy
+			require.NotNil(t, policy)
+			assert.Equal(t, tt.changedAt, policy.ChangedAt)
+		})
+	}
+}
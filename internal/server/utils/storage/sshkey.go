@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const SSHKey InfoType = "ssh-key"
+
+// InfoSSHKey is SSH private key as it is stored in OpenSSH file, it is encrypted with Passphrase if the latter is set
+type InfoSSHKey struct {
+	CustomFields
+	Rotation
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+	Passphrase string `json:"passphrase"`
+	Comment    string `json:"comment"`
+}
+
+func init() {
+	Register(TypeSpec{
+		Type: SSHKey,
+		Name: "SSH Key",
+		New:  func() Info { return &InfoSSHKey{} },
+		Fields: []Field{
+			{Name: "private_key", Label: "Private key", Masked: true, Required: true, Validate: validatePrivateKey},
+			{Name: "public_key", Label: "Public key", Validate: validatePublicKey},
+			{Name: "passphrase", Label: "Passphrase", Masked: true},
+			{Name: "comment", Label: "Comment"},
+		},
+		Render: renderSSHKey,
+	})
+}
+
+func validatePrivateKey(value string) error {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return errors.New("is not a PEM encoded private key")
+	}
+	return nil
+}
+
+func validatePublicKey(value string) error {
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value)); err != nil {
+		return errors.New("is not in authorized_keys format")
+	}
+	return nil
+}
+
+// renderSSHKey shows the public key with its fingerprint, the private key is printed last so it can be copied as is
+func renderSSHKey(info Info) string {
+	key, ok := info.(*InfoSSHKey)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Public key: %s\n", strings.TrimSpace(key.PublicKey))
+	if publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.PublicKey)); err == nil {
+		fmt.Fprintf(&b, "Fingerprint: %s\n", ssh.FingerprintSHA256(publicKey))
+	}
+	fmt.Fprintf(&b, "Comment: %s\n", key.Comment)
+	if key.Passphrase != "" {
+		fmt.Fprintf(&b, "Passphrase: %s\n", key.Passphrase)
+	}
+	fmt.Fprintf(&b, "Private key:\n%s\n", strings.TrimSpace(key.PrivateKey))
+	return b.String()
+}
@@ -2,7 +2,10 @@ package storage
 
 import (
 	"errors"
+	"time"
 
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/emergency"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/orgs"
 	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/types"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -13,15 +16,154 @@ var (
 	ErrUserExists   = errors.New("such user already exist in DB")
 	ErrInvalidData  = errors.New("error data is invalid")
 	ErrDataNotFound = errors.New("error data not found")
+	ErrUserNotFound = errors.New("error user not found")
+	ErrAccessDenied = errors.New("error access denied")
 )
 
+const (
+	AccessRead      Access = "read"
+	AccessReadWrite Access = "read-write"
+	// AccessSealed is access to a copy of the secret encrypted for the recipient's public key
+	AccessSealed Access = "sealed"
+)
+
+// Access is level of access to a secret granted by its owner to another user
+type Access string
+
+// Valid reports whether the access can be granted to the secret stored on the server, sealed copies are shared separately
+func (a Access) Valid() bool {
+	return a == AccessRead || a == AccessReadWrite
+}
+
+// Share is a user the secret is shared with
+type Share struct {
+	Login  string `json:"login"`
+	Access Access `json:"access"`
+}
+
+// SealedShare is a copy of the owner's secret encrypted on the client for the recipient, the server can't decrypt it
+type SealedShare struct {
+	Name      string   `json:"name"`
+	Type      InfoType `json:"type"`
+	Owner     string   `json:"owner,omitempty"`
+	Recipient string   `json:"recipient"`
+	// SealedKey is the item key encrypted with the recipient's public key
+	SealedKey []byte `json:"sealed_key"`
+	// Data is the secret encrypted with the item key
+	Data []byte `json:"data"`
+}
+
+// SharedInfo is a secret of another user shared with the user
+type SharedInfo struct {
+	Name   string   `json:"name"`
+	Type   InfoType `json:"type"`
+	Owner  string   `json:"owner"`
+	Access Access   `json:"access"`
+}
+
+// Link is a one-time link to a secret encrypted on the client with key the server never sees
+type Link struct {
+	Owner string `json:"-"`
+	// Name and Type are the linked secret's, they are kept for the owner's audit log
+	Name      string    `json:"-"`
+	Type      InfoType  `json:"-"`
+	Data      []byte    `json:"data"`
+	ViewsLeft int       `json:"views_left"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type Storage interface {
 	SaveData(encryptedData []byte, metadata InfoMeta) error
 	GetData(metadata InfoMeta) ([]byte, error)
+	UpdateData(encryptedData []byte, metadata InfoMeta) error
+	DeleteData(metadata InfoMeta) error
+	// ListData returns names and types of the user's own secrets
+	ListData(login string) ([]InfoMeta, error)
+	// GetAllData returns the user's own secrets of the type with their data, secrets of all types if it is empty
+	GetAllData(login string, infoType InfoType) ([]OwnData, error)
+	ShareData(metadata InfoMeta, recipient string, access Access) error
+	RevokeShare(metadata InfoMeta, recipient string) error
+	GetShares(metadata InfoMeta) ([]Share, error)
+	GetSharedWithMe(login string) ([]SharedInfo, error)
+	SaveSealedShare(share SealedShare) error
+	GetSealedShare(metadata InfoMeta) (SealedShare, error)
+	OrgStorage
+	LinkStorage
+	EmergencyStorage
+	UpgradeStorage
+	TemplateStorage
+}
+
+// TemplateStorage keeps templates of secrets by their owners
+type TemplateStorage interface {
+	// SaveTemplate adds the template or replaces the owner's template with the same name
+	SaveTemplate(owner string, template Template) error
+	GetTemplates(owner string) ([]Template, error)
+	DeleteTemplate(owner string, name string) error
+}
+
+// OwnData is the user's own secret with its encrypted data
+type OwnData struct {
+	Meta InfoMeta
+	Data []byte
+}
+
+// StoredData is a secret addressed by its row regardless of the owner, it is used by background jobs
+type StoredData struct {
+	ID   int64
+	Type InfoType
+	Data []byte
+}
+
+// UpgradeStorage lets background jobs rewrite stored secrets in place
+type UpgradeStorage interface {
+	// GetDataBatch returns up to limit secrets with ID greater than afterID ordered by ID
+	GetDataBatch(afterID int64, limit int) ([]StoredData, error)
+	// ReplaceData replaces the secret only if it was not changed since it was read
+	ReplaceData(id int64, oldData []byte, newData []byte) (bool, error)
+}
+
+// EmergencyStorage keeps trusted contacts, statuses are checked by the caller
+type EmergencyStorage interface {
+	AddEmergencyContact(owner string, contact string, waitHours int) error
+	RemoveEmergencyContact(owner string, contact string) error
+	GetEmergencyContact(owner string, contact string) (emergency.Contact, error)
+	// GetEmergencyContacts returns the owner's trusted contacts
+	GetEmergencyContacts(owner string) ([]emergency.Contact, error)
+	// GetEmergencyGrantors returns users who trust the contact
+	GetEmergencyGrantors(contact string) ([]emergency.Contact, error)
+	SetEmergencyStatus(contact emergency.Contact) error
+}
+
+// LinkStorage keeps one-time links by hashes of their tokens
+type LinkStorage interface {
+	CreateLink(tokenHash []byte, link Link) error
+	// ConsumeLink returns the link with one view used, the link is deleted after the last view, expired links are not found
+	ConsumeLink(tokenHash []byte) (Link, error)
+}
+
+// OrgStorage keeps organizations, their members and collections, permissions are checked by the caller
+type OrgStorage interface {
+	CreateOrg(name string, ownerLogin string) error
+	DeleteOrg(name string) error
+	GetUserOrgs(login string) ([]orgs.Membership, error)
+	// GetMemberRole returns empty role if the user is not a member of the organization
+	GetMemberRole(org string, login string) (orgs.Role, error)
+	GetMembers(org string) ([]orgs.Member, error)
+	InviteMember(org string, login string, role orgs.Role, invitedBy string) error
+	GetInvitations(login string) ([]orgs.Invitation, error)
+	AcceptInvitation(org string, login string) error
+	DeclineInvitation(org string, login string) error
+	SetMemberRole(org string, login string, role orgs.Role) error
+	RemoveMember(org string, login string) error
+	CreateCollection(org string, name string) error
+	DeleteCollection(org string, name string) error
+	GetCollections(org string) ([]string, error)
+	GetCollectionData(org string, collection string) ([]InfoMeta, error)
 }
 
 type UserStorage interface {
 	FindUser(login string) (*types.User, error)
-	RegisterNewUser(login string, password string) (types.User, error)
+	RegisterNewUser(user types.User) (types.User, error)
 	GetUserData(login string) (types.User, error)
 }
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template is the user's reusable layout of secrets, e.g. "Router admin", its fields are added to new secrets as custom fields
+type Template struct {
+	Name string `json:"name"`
+	// Type is type of secrets created from the template, it is Custom for secrets made only of the template's fields
+	Type   InfoType        `json:"type"`
+	Fields []TemplateField `json:"fields"`
+}
+
+// TemplateField is a custom field every secret created from the template has
+type TemplateField struct {
+	Name     string    `json:"name"`
+	Kind     FieldKind `json:"kind,omitempty"`
+	Required bool      `json:"required,omitempty"`
+}
+
+// Field describes the template's field as a field of a secret type
+func (f TemplateField) Field() Field {
+	return Field{Name: f.Name, Label: f.Name, Kind: f.Kind, Masked: f.Kind == KindHidden, Required: f.Required}
+}
+
+// Validate reports invalid parts of the template as FieldErrors
+func (t Template) Validate() error {
+	var fieldErrs FieldErrors
+	if strings.TrimSpace(t.Name) == "" {
+		fieldErrs = append(fieldErrs, FieldError{Field: "name", Message: "is required"})
+	}
+	if _, ok := Lookup(t.Type); !ok {
+		fieldErrs = append(fieldErrs, FieldError{Field: "type", Message: "is unknown"})
+	}
+	if len(t.Fields) == 0 || len(t.Fields) > MaxCustomFields {
+		fieldErrs = append(fieldErrs, FieldError{Field: "fields", Message: fmt.Sprintf("must be from 1 to %d", MaxCustomFields)})
+	}
+	names := make(map[string]bool, len(t.Fields))
+	for _, field := range t.Fields {
+		switch {
+		case strings.TrimSpace(field.Name) == "":
+			fieldErrs = append(fieldErrs, FieldError{Field: "fields", Message: "must have names"})
+		case names[field.Name]:
+			fieldErrs = append(fieldErrs, FieldError{Field: "fields." + field.Name, Message: "is duplicated"})
+		case !validKind(field.Kind):
+			fieldErrs = append(fieldErrs, FieldError{Field: "fields." + field.Name, Message: "has unknown kind"})
+		}
+		names[field.Name] = true
+	}
+	if len(fieldErrs) != 0 {
+		return fieldErrs
+	}
+	return nil
+}
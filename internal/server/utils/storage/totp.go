@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/otp"
+)
+
+const TOTP InfoType = "totp"
+
+// InfoTOTP is a seed of two-factor authentication codes, codes are computed by the client only
+type InfoTOTP struct {
+	CustomFields
+	Rotation
+	Secret    string `json:"secret"`
+	Algorithm string `json:"algorithm"`
+	Digits    int    `json:"digits,omitempty"`
+	Period    int    `json:"period,omitempty"`
+	Issuer    string `json:"issuer"`
+	Account   string `json:"account"`
+}
+
+// Params returns parameters of the codes, missing ones are the defaults of authenticator apps
+func (t *InfoTOTP) Params() otp.Params {
+	p := otp.DefaultParams
+	if t.Algorithm != "" {
+		p.Algorithm = otp.Algorithm(strings.ToUpper(t.Algorithm))
+	}
+	if t.Digits != 0 {
+		p.Digits = t.Digits
+	}
+	if t.Period != 0 {
+		p.Period = t.Period
+	}
+	return p
+}
+
+func init() {
+	Register(TypeSpec{
+		Type: TOTP,
+		Name: "TOTP Seed",
+		New:  func() Info { return &InfoTOTP{} },
+		Fields: []Field{
+			{Name: "secret", Label: "Secret", Masked: true, Required: true, Validate: otp.ValidateSecret},
+			{Name: "algorithm", Label: "Algorithm", Validate: validateTOTPAlgorithm},
+			{Name: "digits", Label: "Digits", Kind: KindNumber, Validate: validateTOTPDigits},
+			{Name: "period", Label: "Period", Kind: KindNumber, Validate: validateTOTPPeriod},
+			{Name: "issuer", Label: "Issuer"},
+			{Name: "account", Label: "Account"},
+		},
+	})
+}
+
+func validateTOTPAlgorithm(value string) error {
+	p := otp.DefaultParams
+	p.Algorithm = otp.Algorithm(strings.ToUpper(value))
+	return p.Validate()
+}
+
+func validateTOTPDigits(value string) error {
+	p := otp.DefaultParams
+	p.Digits, _ = strconv.Atoi(value)
+	if p.Validate() != nil {
+		return errors.New("must be from 6 to 8")
+	}
+	return nil
+}
+
+func validateTOTPPeriod(value string) error {
+	if period, _ := strconv.Atoi(value); period <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
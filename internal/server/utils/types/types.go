@@ -3,6 +3,7 @@ package types
 import (
 	"errors"
 	"net/http"
+	"time"
 )
 
 type Authorization interface {
@@ -12,21 +13,125 @@ type Authorization interface {
 	GetUserID(r *http.Request) int
 	GetUserLogin(r *http.Request) string
 	CheckData(u UserData) error
+	CheckBreached(password string) error
+	GenerateChallengeToken(user User) (string, error)
+	LoginTOTP(data TOTPLoginData) (User, error)
+	GetChallengeLogin(challenge string) string
+	EnrollTOTP(login string) (TOTPEnrollment, error)
+	ConfirmTOTP(login string, code string) error
+	GetSessionID(r *http.Request) string
+	CheckSession(r *http.Request) bool
+	ChangePassword(login string, sessionID string, data ChangePasswordData) error
+	GetVaultKey(login string) ([]byte, error)
+	SetVaultKey(login string, vaultKey []byte) error
+	DeleteAccount(login string, password string) error
+	GetKeyPair(login string) (KeyPairData, error)
+	SetKeyPair(login string, keyPair KeyPairData) error
+	GetPublicKey(login string) ([]byte, error)
+	SetRecoveryKey(login string, recovery RecoveryKeyData) error
+	GetRecoveryVaultKey(data RecoveryData) ([]byte, error)
+	ResetPassword(data RecoveryData) error
 }
 
 type UserDB interface {
-	RegisterNewUser(login string, password string) (User, error)
+	RegisterNewUser(user User) (User, error)
 	GetUserData(login string) (User, error)
+	SetTOTPSecret(userID int, secret string, backupCodeHashes []string) error
+	EnableTOTP(userID int) error
+	UseBackupCode(userID int, codeHash string) (bool, error)
+	// SetTOTPCounter stores time step of accepted TOTP code, it reports false if the step is not after the last accepted one
+	SetTOTPCounter(userID int, counter int64) (bool, error)
+	// CreateChallenge stores challenge of the second login step until it expires
+	CreateChallenge(userID int, challengeID string, expiresAt time.Time) error
+	// UseChallenge counts attempt to pass the challenge and reports whether it is alive and has attempts left
+	UseChallenge(challengeID string, maxAttempts int) (bool, error)
+	DeleteChallenge(challengeID string) error
+	CreateSession(userID int, sessionID string, expiresAt time.Time) error
+	SessionActive(sessionID string) (bool, error)
+	ChangePassword(userID int, hash string, vaultKey []byte, keepSessionID string) error
+	SetVaultKey(userID int, vaultKey []byte) error
+	DeleteUser(userID int, login string) error
+	SetKeyPair(userID int, publicKey []byte, privateKey []byte) error
+	SetRecoveryKey(userID int, vaultKey []byte, verifierHash string) error
 }
 
 type User struct {
 	Login        string
 	HashPassword string
 	ID           int
+	TOTPSecret   string
+	TOTPEnabled  bool
+	// TOTPCounter is time step of the last accepted TOTP code, codes up to it can't be used again
+	TOTPCounter int64
+	VaultKey    []byte
+	PublicKey   []byte
+	PrivateKey  []byte
+	// RecoveryVaultKey is vault key wrapped with the recovery key, RecoveryHash is hash of the recovery key's verifier
+	RecoveryVaultKey []byte
+	RecoveryHash     string
 }
 type UserData struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	// VaultKey is user's vault key wrapped on the client with key derived from the password
+	VaultKey []byte `json:"vault_key,omitempty"`
+	// Recovery is set if the user created recovery key at registration
+	Recovery *RecoveryKeyData `json:"recovery,omitempty"`
+}
+
+// RecoveryKeyData is user's vault key wrapped on the client with the recovery key and
+// verifier derived from the recovery key, the server keeps only hash of the verifier
+type RecoveryKeyData struct {
+	VaultKey []byte `json:"vault_key"`
+	Verifier string `json:"verifier"`
+}
+
+// RecoveryData proves knowledge of the recovery key to get the wrapped vault key or to reset forgotten password
+type RecoveryData struct {
+	Login    string `json:"login"`
+	Verifier string `json:"verifier"`
+	// NewPassword and VaultKey re-wrapped with it are set when the password is reset
+	NewPassword string `json:"new_password,omitempty"`
+	VaultKey    []byte `json:"vault_key,omitempty"`
+}
+
+type ChangePasswordData struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+	VaultKey    []byte `json:"vault_key,omitempty"`
+}
+
+type DeleteAccountData struct {
+	Password string `json:"password"`
+}
+
+type VaultKeyData struct {
+	VaultKey []byte `json:"vault_key"`
+}
+
+// KeyPairData is user's key pair for sharing, private key is encrypted on the client with the user's vault key
+type KeyPairData struct {
+	PublicKey  []byte `json:"public_key"`
+	PrivateKey []byte `json:"private_key,omitempty"`
+}
+
+type TOTPLoginData struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TOTPEnrollment struct {
+	URI         string   `json:"uri"`
+	Secret      string   `json:"secret"`
+	BackupCodes []string `json:"backup_codes"`
+}
+
+type TOTPConfirmData struct {
+	Code string `json:"code"`
+}
+
+type ChallengeResponse struct {
+	ChallengeToken string `json:"challenge_token"`
 }
 
 var (
@@ -37,4 +142,11 @@ var (
 	ErrKeyNotFound  = errors.New("error user ID not found")
 	ErrAlarm        = errors.New("error tx.BeginTx alarm")
 	ErrAlarm2       = errors.New("error tx.PrepareContext alarm")
+	ErrTOTPNotSet   = errors.New("error totp is not enrolled")
+	ErrTOTPEnabled  = errors.New("error totp is already enabled")
+	ErrVaultKeyReq  = errors.New("error vault key re-wrapped with new password is required")
+	ErrVaultKeySet  = errors.New("error vault key is already set")
+	ErrKeyPairSet   = errors.New("error key pair is already set")
+	ErrNoPublicKey  = errors.New("error user has no public key")
+	ErrBreached     = errors.New("error password was found in data breaches")
 )
@@ -0,0 +1,108 @@
+// Package upgrade re-encodes secrets stored in legacy gob encoding or with old schema versions of their types.
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/crypto"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+)
+
+const (
+	DefaultBatchSize = 100
+	// DefaultPause is time between batches so the job doesn't compete with users' requests
+	DefaultPause = 100 * time.Millisecond
+)
+
+// Result is what one run of the upgrade did
+type Result struct {
+	Checked  int
+	Upgraded int
+	// Skipped secrets were changed by users during the run or can't be decoded
+	Skipped int
+}
+
+type Upgrader struct {
+	storage   storage.UpgradeStorage
+	secretKey []byte
+	BatchSize int
+	Pause     time.Duration
+}
+
+func NewUpgrader(st storage.UpgradeStorage, secretKey []byte) *Upgrader {
+	return &Upgrader{
+		storage:   st,
+		secretKey: secretKey,
+		BatchSize: DefaultBatchSize,
+		Pause:     DefaultPause,
+	}
+}
+
+// Run goes through all stored secrets once and re-encodes outdated ones in place
+func (u *Upgrader) Run(ctx context.Context) (Result, error) {
+	var (
+		result  Result
+		afterID int64
+	)
+	for {
+		batch, err := u.storage.GetDataBatch(afterID, u.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+		for _, data := range batch {
+			afterID = data.ID
+			result.Checked++
+			upgraded, err := u.upgrade(data)
+			if err != nil {
+				log.Printf("error while upgrading secret %d: %v", data.ID, err)
+				result.Skipped++
+				continue
+			}
+			if upgraded {
+				result.Upgraded++
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(u.Pause):
+		}
+	}
+}
+
+// upgrade reports whether the secret was re-encoded
+func (u *Upgrader) upgrade(data storage.StoredData) (bool, error) {
+	binData, err := crypto.Decrypt(data.Data, u.secretKey)
+	if err != nil {
+		return false, fmt.Errorf("error while decrypting data: %w", err)
+	}
+	if !storage.NeedsUpgrade(data.Type, binData) {
+		return false, nil
+	}
+	info, err := storage.Decode(data.Type, binData)
+	if err != nil {
+		return false, err
+	}
+	binData, err = storage.Encode(data.Type, info)
+	if err != nil {
+		return false, err
+	}
+	encData, err := crypto.Encrypt(binData, u.secretKey)
+	if err != nil {
+		return false, fmt.Errorf("error while encrypting data: %w", err)
+	}
+	replaced, err := u.storage.ReplaceData(data.ID, data.Data, encData)
+	if err != nil {
+		return false, err
+	}
+	if !replaced {
+		return false, fmt.Errorf("secret was changed during upgrade")
+	}
+	return true, nil
+}
@@ -0,0 +1,55 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"testing"
+
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/crypto"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage"
+	"github.com/AbramovArseniy/GophKeeper/internal/server/utils/storage/mockstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var secretKey = []byte("secretKeyReallyy")
+
+func TestRun(t *testing.T) {
+	ms := mockstorage.NewMockStorage()
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&storage.InfoText{Text: "legacy"}))
+	legacy, err := crypto.Encrypt(buf.Bytes(), secretKey)
+	require.NoError(t, err)
+	require.NoError(t, ms.SaveData(legacy, storage.InfoMeta{Name: "legacy", Type: storage.Text, Login: "user"}))
+
+	bin, err := storage.Encode(storage.Text, &storage.InfoText{Text: "current"})
+	require.NoError(t, err)
+	current, err := crypto.Encrypt(bin, secretKey)
+	require.NoError(t, err)
+	require.NoError(t, ms.SaveData(current, storage.InfoMeta{Name: "current", Type: storage.Text, Login: "user"}))
+
+	upgrader := NewUpgrader(ms, secretKey)
+	upgrader.BatchSize = 1
+	upgrader.Pause = 0
+	result, err := upgrader.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Result{Checked: 2, Upgraded: 1}, result)
+
+	data, err := ms.GetData(storage.InfoMeta{Name: "legacy", Type: storage.Text, Login: "user"})
+	require.NoError(t, err)
+	bin, err = crypto.Decrypt(data, secretKey)
+	require.NoError(t, err)
+	assert.False(t, storage.IsLegacy(bin))
+	info, err := storage.Decode(storage.Text, bin)
+	require.NoError(t, err)
+	assert.Equal(t, &storage.InfoText{Text: "legacy"}, info)
+
+	data, err = ms.GetData(storage.InfoMeta{Name: "current", Type: storage.Text, Login: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, current, data)
+
+	result, err = upgrader.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Result{Checked: 2}, result)
+}